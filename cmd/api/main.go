@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 	_ "github.com/kanutocd/tada/docs"
 	"github.com/kanutocd/tada/internal/config"
 	"github.com/kanutocd/tada/internal/database"
+	"github.com/kanutocd/tada/internal/domain"
 	"github.com/kanutocd/tada/internal/handler"
 	"github.com/kanutocd/tada/internal/middleware"
 	"github.com/kanutocd/tada/internal/repository"
@@ -25,6 +27,8 @@ import (
 // @title           Tada API
 // @version         1.0
 // @description     A simple Todo API application
+// @description     Tadas, projects and their changes belong to a workspace.
+// @description     Send X-Workspace-ID to pick one; the caller's first workspace is used otherwise.
 // @termsOfService  http://swagger.io/terms/
 // @contact.name    API Support
 // @contact.url     http://www.swagger.io/support
@@ -33,6 +37,10 @@ import (
 // @license.url     https://opensource.org/licenses/MIT
 // @host            localhost:8080
 // @BasePath        /api/v1
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Access token from /auth/login, sent as "Bearer <token>"
 func main() {
 	// Load configuration
 	cfg, err := config.Load()
@@ -59,17 +67,89 @@ func main() {
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
 	tadaRepo := repository.NewTadaRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	dependencyRepo := repository.NewTadaDependencyRepository(db)
+	reminderRepo := repository.NewReminderRepository(db)
+	commentRepo := repository.NewCommentRepository(db)
+	attachmentRepo := repository.NewAttachmentRepository(db)
+	checklistRepo := repository.NewChecklistRepository(db)
+	projectRepo := repository.NewProjectRepository(db)
+	workspaceRepo := repository.NewWorkspaceRepository(db)
+	idempotencyRepo := repository.NewIdempotencyKeyRepository(db)
+	txManager := repository.NewTxManager(db)
+
+	blobStore, err := repository.NewBlobStore(cfg.Attachments.Storage)
+	if err != nil {
+		log.Fatal("Failed to configure attachment storage:", err)
+	}
+
+	notifier, err := service.NewNotifier(cfg.Notifications)
+	if err != nil {
+		log.Fatal("Failed to configure notifications:", err)
+	}
 
 	// Initialize services
-	userService := service.NewUserService(userRepo)
-	tadaService := service.NewTadaService(tadaRepo, userRepo)
+	userService := service.NewUserService(userRepo, workspaceRepo, sessionRepo, txManager)
+	workspaceService := service.NewWorkspaceService(workspaceRepo, userRepo, txManager, notifier, cfg.Workspaces)
+	webhookService := service.NewWebhookService(webhookRepo, txManager)
+	tagService := service.NewTagService(tagRepo, txManager)
+	projectService := service.NewProjectService(projectRepo, txManager)
+	commentService := service.NewCommentService(commentRepo, tadaRepo, userRepo, txManager)
+	attachmentService := service.NewAttachmentService(attachmentRepo, tadaRepo, blobStore, cfg.Attachments)
+	tadaStream := service.NewTadaStream(cfg.Stream.ReplayBufferSize, cfg.Stream.SubscriberBufferSize)
+	tadaPublishers := service.TadaPublishers{webhookService, tadaStream}
+	tadaPolicy := service.DefaultTadaPolicy{}
+	tadaService := service.NewTadaService(
+		tadaRepo, userRepo, tagRepo, dependencyRepo, commentRepo, checklistRepo, projectRepo, workspaceRepo,
+		repository.NewPostgresSearcher(db), txManager, tadaPolicy, tadaPublishers, cfg.Tadas,
+	)
+	checklistService := service.NewChecklistService(checklistRepo, tadaRepo, txManager, tadaPolicy)
+	authService := service.NewAuthService(userRepo, sessionRepo, txManager, cfg.Auth)
+	idempotencyService := service.NewIdempotencyService(idempotencyRepo, cfg.Idempotency)
+
+	// Start background workers
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+
+	webhookDispatcher := service.NewWebhookDispatcher(webhookRepo, service.NewWebhookClient(), cfg.Webhooks)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		webhookDispatcher.Run(workerCtx)
+	}()
+
+	if cfg.Reminders.Enabled {
+		reminderScheduler := service.NewReminderScheduler(reminderRepo, repository.NewAdvisoryLocker(db), notifier, cfg.Reminders)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			reminderScheduler.Run(workerCtx)
+		}()
+	}
 
 	// Initialize handlers
 	userHandler := handler.NewUserHandler(userService)
 	tadaHandler := handler.NewTadaHandler(tadaService)
+	authHandler := handler.NewAuthHandler(authService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	tagHandler := handler.NewTagHandler(tagService)
+	projectHandler := handler.NewProjectHandler(projectService)
+	workspaceHandler := handler.NewWorkspaceHandler(workspaceService)
+	commentHandler := handler.NewCommentHandler(commentService)
+	attachmentHandler := handler.NewAttachmentHandler(attachmentService, cfg.Attachments.MaxSize)
+	checklistHandler := handler.NewChecklistHandler(checklistService)
+	streamHandler := handler.NewStreamHandler(tadaStream, cfg.Stream.HeartbeatInterval)
+
+	// Clients may retry creating users and tadas with an Idempotency-Key
+	idempotent := middleware.Idempotency(idempotencyService, cfg.Idempotency.MaxBodySize)
 
 	// Setup router
-	router := setupRouter(userHandler, tadaHandler)
+	router := setupRouter(
+		authService, workspaceService, idempotent, authHandler, userHandler, workspaceHandler, tadaHandler, commentHandler,
+		attachmentHandler, checklistHandler, projectHandler, tagHandler, webhookHandler, streamHandler,
+	)
 
 	// Setup server
 	srv := &http.Server{
@@ -79,6 +159,10 @@ func main() {
 		WriteTimeout: cfg.Server.WriteTimeout,
 	}
 
+	// End open event streams as soon as shutdown begins; Shutdown waits
+	// for active connections, and streams never finish on their own.
+	srv.RegisterOnShutdown(tadaStream.Close)
+
 	// Start server
 	go func() {
 		log.Printf("Server starting on port %s", cfg.Server.Port)
@@ -102,10 +186,29 @@ func main() {
 		log.Fatal("Server forced to shutdown:", err)
 	}
 
+	// Stop background workers
+	stopWorkers()
+	workers.Wait()
+
 	log.Println("Server exited")
 }
 
-func setupRouter(userHandler *handler.UserHandler, tadaHandler *handler.TadaHandler) *gin.Engine {
+func setupRouter(
+	authService service.AuthService,
+	workspaceService service.WorkspaceService,
+	idempotent gin.HandlerFunc,
+	authHandler *handler.AuthHandler,
+	userHandler *handler.UserHandler,
+	workspaceHandler *handler.WorkspaceHandler,
+	tadaHandler *handler.TadaHandler,
+	commentHandler *handler.CommentHandler,
+	attachmentHandler *handler.AttachmentHandler,
+	checklistHandler *handler.ChecklistHandler,
+	projectHandler *handler.ProjectHandler,
+	tagHandler *handler.TagHandler,
+	webhookHandler *handler.WebhookHandler,
+	streamHandler *handler.StreamHandler,
+) *gin.Engine {
 	router := gin.Default()
 
 	// Middleware
@@ -124,24 +227,125 @@ func setupRouter(userHandler *handler.UserHandler, tadaHandler *handler.TadaHand
 	// API routes
 	v1 := router.Group("/api/v1")
 	{
-		// User routes
-		users := v1.Group("/users")
+		// Public routes
+		v1.POST("/users", idempotent, userHandler.CreateUser)
+		v1.POST("/auth/login", authHandler.Login)
+		v1.POST("/auth/refresh", authHandler.Refresh)
+
+		// Everything below requires a bearer access token
+		authenticated := v1.Group("", middleware.Auth(authService))
+
+		authenticated.POST("/auth/logout", authHandler.Logout)
+		authenticated.GET("/me", authHandler.Me)
+
+		// Tadas, projects, tags and webhooks live in the workspace named by
+		// the X-Workspace-ID header, and guests may only read and comment.
+		scoped := authenticated.Group("", middleware.Workspace(workspaceService))
+		member := middleware.RequireWorkspaceRole(domain.WorkspaceRoleMember)
+
+		// Browsers' EventSource cannot send headers, so the stream also
+		// accepts the access token and workspace as query parameters.
+		v1.GET(
+			"/stream",
+			middleware.TokenFromQuery("access_token"), middleware.WorkspaceFromQuery("workspace_id"),
+			middleware.Auth(authService), middleware.Workspace(workspaceService),
+			streamHandler.Stream,
+		)
+
+		// User routes. Users are looked up among the members of the
+		// current workspace, but may only change their own account.
+		users := authenticated.Group("/users")
 		{
-			users.GET("", userHandler.GetUsers)
-			users.POST("", userHandler.CreateUser)
-			users.GET("/:id", userHandler.GetUser)
 			users.PUT("/:id", userHandler.UpdateUser)
+			users.PATCH("/:id", userHandler.PatchUser)
 			users.DELETE("/:id", userHandler.DeleteUser)
 		}
+		scoped.GET("/users", userHandler.GetUsers)
+		scoped.GET("/users/:id", userHandler.GetUser)
+		scoped.GET("/users/:id/tadas", tadaHandler.GetUserTadas)
+
+		// Search routes
+		scoped.GET("/search", tadaHandler.SearchTadas)
+
+		// Workspace routes
+		workspaces := authenticated.Group("/workspaces")
+		{
+			workspaces.GET("", workspaceHandler.GetWorkspaces)
+			workspaces.POST("", workspaceHandler.CreateWorkspace)
+			workspaces.POST("/invitations/accept", workspaceHandler.AcceptInvitation)
+			workspaces.GET("/:id", workspaceHandler.GetWorkspace)
+			workspaces.PUT("/:id", workspaceHandler.UpdateWorkspace)
+			workspaces.GET("/:id/members", workspaceHandler.GetMembers)
+			workspaces.PUT("/:id/members/:userId", workspaceHandler.UpdateMember)
+			workspaces.DELETE("/:id/members/:userId", workspaceHandler.RemoveMember)
+			workspaces.GET("/:id/invitations", workspaceHandler.GetInvitations)
+			workspaces.POST("/:id/invitations", workspaceHandler.InviteMember)
+			workspaces.DELETE("/:id/invitations/:invitationId", workspaceHandler.RevokeInvitation)
+		}
 
 		// Tada routes
-		tadas := v1.Group("/tadas")
+		tadas := scoped.Group("/tadas")
 		{
 			tadas.GET("", tadaHandler.GetTadas)
-			tadas.POST("", tadaHandler.CreateTada)
+			tadas.POST("", member, idempotent, tadaHandler.CreateTada)
+			tadas.POST("/bulk", member, tadaHandler.BulkTadas)
 			tadas.GET("/:id", tadaHandler.GetTada)
-			tadas.PUT("/:id", tadaHandler.UpdateTada)
-			tadas.DELETE("/:id", tadaHandler.DeleteTada)
+			tadas.PUT("/:id", member, tadaHandler.UpdateTada)
+			tadas.PATCH("/:id", member, tadaHandler.PatchTada)
+			tadas.DELETE("/:id", member, tadaHandler.DeleteTada)
+			tadas.GET("/:id/history", tadaHandler.GetTadaHistory)
+			tadas.GET("/:id/children", tadaHandler.GetTadaChildren)
+			tadas.GET("/:id/tree", tadaHandler.GetTadaTree)
+			tadas.GET("/:id/occurrences", tadaHandler.GetTadaOccurrences)
+			tadas.POST("/:id/blocks", member, tadaHandler.AddDependency)
+			tadas.DELETE("/:id/blocks/:blocked_id", member, tadaHandler.RemoveDependency)
+			tadas.GET("/:id/comments", commentHandler.GetComments)
+			tadas.POST("/:id/comments", commentHandler.CreateComment)
+			tadas.PUT("/:id/comments/:commentId", commentHandler.UpdateComment)
+			tadas.DELETE("/:id/comments/:commentId", commentHandler.DeleteComment)
+			tadas.GET("/:id/attachments", attachmentHandler.GetAttachments)
+			tadas.POST("/:id/attachments", member, attachmentHandler.UploadAttachment)
+			tadas.GET("/:id/attachments/:attachmentId", attachmentHandler.DownloadAttachment)
+			tadas.DELETE("/:id/attachments/:attachmentId", member, attachmentHandler.DeleteAttachment)
+			tadas.GET("/:id/checklist", checklistHandler.GetChecklist)
+			tadas.POST("/:id/checklist", member, checklistHandler.AddChecklistItem)
+			tadas.PUT("/:id/checklist/order", member, checklistHandler.ReorderChecklist)
+			tadas.PUT("/:id/checklist/:itemId", member, checklistHandler.UpdateChecklistItem)
+			tadas.DELETE("/:id/checklist/:itemId", member, checklistHandler.DeleteChecklistItem)
+		}
+
+		// Project routes
+		projects := scoped.Group("/projects")
+		{
+			projects.GET("", projectHandler.GetProjects)
+			projects.POST("", member, projectHandler.CreateProject)
+			projects.GET("/:id", projectHandler.GetProject)
+			projects.PUT("/:id", member, projectHandler.UpdateProject)
+			projects.DELETE("/:id", member, projectHandler.DeleteProject)
+			projects.GET("/:id/tadas", tadaHandler.GetProjectTadas)
+			projects.GET("/:id/stats", projectHandler.GetProjectStats)
+		}
+
+		// Tag routes
+		tags := scoped.Group("/tags")
+		{
+			tags.GET("", tagHandler.GetTags)
+			tags.POST("", member, tagHandler.CreateTag)
+			tags.GET("/:id", tagHandler.GetTag)
+			tags.PUT("/:id", member, tagHandler.UpdateTag)
+			tags.DELETE("/:id", member, tagHandler.DeleteTag)
+		}
+
+		// Webhook routes. A webhook receives every change in the workspace,
+		// so viewers may not subscribe one.
+		webhooks := scoped.Group("/webhooks", member)
+		{
+			webhooks.GET("", webhookHandler.GetWebhooks)
+			webhooks.POST("", webhookHandler.CreateWebhook)
+			webhooks.GET("/:id", webhookHandler.GetWebhook)
+			webhooks.PUT("/:id", webhookHandler.UpdateWebhook)
+			webhooks.DELETE("/:id", webhookHandler.DeleteWebhook)
+			webhooks.GET("/:id/deliveries", webhookHandler.GetWebhookDeliveries)
 		}
 	}
 
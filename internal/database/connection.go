@@ -28,14 +28,194 @@ func Migrate(db *gorm.DB) error {
 		return fmt.Errorf("failed to create uuid extension: %w", err)
 	}
 
+	// Tadas that predate workflows need their status category filled in
+	// once the column exists
+	hadCategories := db.Migrator().HasColumn(&domain.Tada{}, "status_category")
+
 	// Auto-migrate models
 	if err := db.AutoMigrate(
 		&domain.User{},
+		&domain.Workspace{},
+		&domain.WorkspaceMember{},
+		&domain.WorkspaceInvitation{},
+		&domain.Tag{},
+		&domain.Project{},
 		&domain.Tada{},
+		&domain.TadaDependency{},
+		&domain.Session{},
+		&domain.RetiredRefreshToken{},
+		&domain.TadaEvent{},
+		&domain.Webhook{},
+		&domain.WebhookDelivery{},
+		&domain.Reminder{},
+		&domain.Comment{},
+		&domain.Attachment{},
+		&domain.ChecklistItem{},
+		&domain.IdempotencyKey{},
 	); err != nil {
 		return fmt.Errorf("failed to migrate database: %w", err)
 	}
 
+	if err := backfillWorkspaces(db); err != nil {
+		return fmt.Errorf("failed to backfill workspaces: %w", err)
+	}
+
+	if err := backfillTagWorkspaces(db); err != nil {
+		return fmt.Errorf("failed to backfill tag workspaces: %w", err)
+	}
+
+	if !hadCategories {
+		if err := backfillStatusCategories(db); err != nil {
+			return fmt.Errorf("failed to backfill status categories: %w", err)
+		}
+	}
+
+	if err := ensureSearchVectors(db); err != nil {
+		return fmt.Errorf("failed to add search vectors: %w", err)
+	}
+
 	log.Println("Database migrated successfully")
 	return nil
 }
+
+// backfillStatusCategories gives tadas created before workflows existed the
+// category their status has in the default workflow, and lifts the check
+// that held them to its states.
+func backfillStatusCategories(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("ALTER TABLE tadas DROP CONSTRAINT IF EXISTS chk_tadas_status").Error; err != nil {
+			return err
+		}
+		return tx.Exec(`UPDATE tadas SET status_category = CASE status
+			WHEN ? THEN ?
+			WHEN ? THEN ?
+			ELSE ?
+		END`,
+			domain.StatusCompleted, domain.CategoryCompleted,
+			domain.StatusCancelled, domain.CategoryCancelled,
+			domain.CategoryOpen).Error
+	})
+}
+
+// ensureSearchVectors adds the generated tsvector columns that full-text
+// search runs against, with their GIN indexes. The models leave them out, as
+// they are only ever written by Postgres.
+func ensureSearchVectors(db *gorm.DB) error {
+	statements := []string{
+		`ALTER TABLE tadas ADD COLUMN IF NOT EXISTS search_vector TSVECTOR
+			GENERATED ALWAYS AS (
+				setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+				setweight(to_tsvector('english', coalesce(description, '')), 'B')
+			) STORED`,
+		`ALTER TABLE comments ADD COLUMN IF NOT EXISTS search_vector TSVECTOR
+			GENERATED ALWAYS AS (to_tsvector('english', coalesce(body, ''))) STORED`,
+		"CREATE INDEX IF NOT EXISTS idx_tadas_search_vector ON tadas USING GIN (search_vector)",
+		"CREATE INDEX IF NOT EXISTS idx_comments_search_vector ON comments USING GIN (search_vector)",
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillWorkspaces moves tadas, projects and webhooks created before
+// workspaces existed into a "Default" workspace that every user joins. The
+// earliest user owns it.
+func backfillWorkspaces(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var orphans int64
+		err := tx.Raw(`SELECT
+			(SELECT COUNT(*) FROM tadas WHERE workspace_id IS NULL) +
+			(SELECT COUNT(*) FROM projects WHERE workspace_id IS NULL) +
+			(SELECT COUNT(*) FROM webhooks WHERE workspace_id IS NULL)`).Scan(&orphans).Error
+		if err != nil {
+			return err
+		}
+		if orphans == 0 {
+			return nil
+		}
+
+		workspace := &domain.Workspace{Name: "Default"}
+		if err := tx.Create(workspace).Error; err != nil {
+			return err
+		}
+
+		err = tx.Exec(`INSERT INTO workspace_members (id, workspace_id, user_id, role, created_at, updated_at)
+			SELECT uuid_generate_v4(), ?, id,
+				CASE WHEN ROW_NUMBER() OVER (ORDER BY created_at, id) = 1 THEN ? ELSE ? END,
+				NOW(), NOW()
+			FROM users WHERE deleted_at IS NULL`,
+			workspace.ID, domain.WorkspaceRoleOwner, domain.WorkspaceRoleMember).Error
+		if err != nil {
+			return err
+		}
+
+		for _, table := range []string{"tadas", "projects", "webhooks"} {
+			err := tx.Exec("UPDATE "+table+" SET workspace_id = ? WHERE workspace_id IS NULL", workspace.ID).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		log.Printf("Moved %d existing records into workspace %s", orphans, workspace.ID)
+		return nil
+	})
+}
+
+// backfillTagWorkspaces gives tags created before they belonged to a
+// workspace a copy in each workspace whose tadas carry them, and moves those
+// tadas over to the copies. Tags no tada carries go to the earliest
+// workspace. Names were unique overall until then, so that index goes too,
+// even when there are no tags yet to move.
+func backfillTagWorkspaces(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DROP INDEX IF EXISTS idx_tags_name").Error; err != nil {
+			return err
+		}
+
+		var orphans int64
+		if err := tx.Raw("SELECT COUNT(*) FROM tags WHERE workspace_id IS NULL").Scan(&orphans).Error; err != nil {
+			return err
+		}
+		if orphans == 0 {
+			return nil
+		}
+
+		statements := []string{
+			`INSERT INTO tags (id, name, workspace_id, created_at, updated_at)
+			SELECT uuid_generate_v4(), copies.name, copies.workspace_id, copies.created_at, copies.updated_at
+			FROM (
+				SELECT DISTINCT tags.name, tadas.workspace_id, tags.created_at, tags.updated_at
+				FROM tags
+				JOIN tada_tags ON tada_tags.tag_id = tags.id
+				JOIN tadas ON tadas.id = tada_tags.tada_id
+				WHERE tags.workspace_id IS NULL
+			) AS copies`,
+			`UPDATE tada_tags SET tag_id = copies.id
+			FROM tadas, tags AS originals, tags AS copies
+			WHERE tadas.id = tada_tags.tada_id
+				AND originals.id = tada_tags.tag_id
+				AND originals.workspace_id IS NULL
+				AND copies.workspace_id = tadas.workspace_id
+				AND copies.name = originals.name`,
+			`DELETE FROM tags
+			WHERE workspace_id IS NULL
+				AND (
+					NOT EXISTS (SELECT 1 FROM workspaces)
+					OR EXISTS (SELECT 1 FROM tags AS copies WHERE copies.name = tags.name AND copies.workspace_id IS NOT NULL)
+				)`,
+			`UPDATE tags SET workspace_id = (SELECT id FROM workspaces ORDER BY created_at, id LIMIT 1)
+			WHERE workspace_id IS NULL`,
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+
+		log.Printf("Moved %d existing tags into workspaces", orphans)
+		return nil
+	})
+}
@@ -6,11 +6,14 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 
 	"github.com/kanutocd/tada/internal/domain"
 )
 
+const seedPassword = "password123"
+
 func Seed(db *gorm.DB) error {
 	// Check if data already exists
 	var userCount int64
@@ -20,22 +23,31 @@ func Seed(db *gorm.DB) error {
 		return nil
 	}
 
+	// Every seeded user logs in with the same development password
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(seedPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash seed password: %w", err)
+	}
+
 	// Create users
 	users := []domain.User{
 		{
-			ID:    uuid.New(),
-			Name:  "John Doe",
-			Email: "john.doe@example.com",
+			ID:           uuid.New(),
+			Name:         "John Doe",
+			Email:        "john.doe@example.com",
+			PasswordHash: string(passwordHash),
 		},
 		{
-			ID:    uuid.New(),
-			Name:  "Jane Smith",
-			Email: "jane.smith@example.com",
+			ID:           uuid.New(),
+			Name:         "Jane Smith",
+			Email:        "jane.smith@example.com",
+			PasswordHash: string(passwordHash),
 		},
 		{
-			ID:    uuid.New(),
-			Name:  "Bob Wilson",
-			Email: "bob.wilson@example.com",
+			ID:           uuid.New(),
+			Name:         "Bob Wilson",
+			Email:        "bob.wilson@example.com",
+			PasswordHash: string(passwordHash),
 		},
 	}
 
@@ -45,6 +57,20 @@ func Seed(db *gorm.DB) error {
 		}
 	}
 
+	// Create a workspace the seeded users share
+	workspace := domain.Workspace{ID: uuid.New(), Name: "Tada Team"}
+	if err := db.Create(&workspace).Error; err != nil {
+		return fmt.Errorf("failed to seed workspace: %w", err)
+	}
+
+	roles := []domain.WorkspaceRole{domain.WorkspaceRoleOwner, domain.WorkspaceRoleAdmin, domain.WorkspaceRoleMember}
+	for i, user := range users {
+		member := domain.WorkspaceMember{WorkspaceID: workspace.ID, UserID: user.ID, Role: roles[i]}
+		if err := db.Omit("Workspace", "User").Create(&member).Error; err != nil {
+			return fmt.Errorf("failed to seed workspace member %s: %w", user.Name, err)
+		}
+	}
+
 	// Create tadas
 	dueDate := time.Now().Add(7 * 24 * time.Hour) // 1 week from now
 	tadas := []domain.Tada{
@@ -88,6 +114,7 @@ func Seed(db *gorm.DB) error {
 	tadas[2].CompletedAt = &completedTime
 
 	for _, tada := range tadas {
+		tada.WorkspaceID = workspace.ID
 		if err := db.Create(&tada).Error; err != nil {
 			return fmt.Errorf("failed to seed tada %s: %w", tada.Name, err)
 		}
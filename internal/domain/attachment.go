@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Attachment describes a file attached to a tada. The contents live in a
+// blob store under StorageKey; Checksum is their hex-encoded SHA-256.
+type Attachment struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TadaID      uuid.UUID `gorm:"type:uuid;not null;index" json:"tada_id"`
+	UploadedBy  uuid.UUID `gorm:"type:uuid;not null" json:"uploaded_by"`
+	Filename    string    `gorm:"size:255;not null" json:"filename"`
+	ContentType string    `gorm:"size:255;not null" json:"content_type"`
+	Size        int64     `gorm:"not null" json:"size"`
+	Checksum    string    `gorm:"size:64;not null" json:"checksum"`
+	StorageKey  string    `gorm:"size:512;not null" json:"-"`
+	CreatedAt   time.Time `gorm:"index" json:"created_at"`
+
+	// Relationships
+	Uploader User `gorm:"foreignKey:UploadedBy;references:ID" json:"uploader,omitempty"`
+}
+
+func (a *Attachment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+func (Attachment) TableName() string {
+	return "attachments"
+}
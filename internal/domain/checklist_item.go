@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ChecklistItem is a step in a tada's checklist. Items are listed by
+// Position, lowest first; CheckedBy and CheckedAt are set while Checked.
+type ChecklistItem struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TadaID    uuid.UUID  `gorm:"type:uuid;not null;index:idx_checklist_items_tada_position,priority:1" json:"tada_id"`
+	Text      string     `gorm:"size:500;not null" json:"text"`
+	Checked   bool       `gorm:"not null;default:false" json:"checked"`
+	Position  int        `gorm:"not null;index:idx_checklist_items_tada_position,priority:2" json:"position"`
+	CheckedBy *uuid.UUID `gorm:"type:uuid" json:"checked_by,omitempty"`
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func (i *ChecklistItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+func (ChecklistItem) TableName() string {
+	return "checklist_items"
+}
+
+// ChecklistSummary counts a tada's checklist items and how many of them
+// are checked.
+type ChecklistSummary struct {
+	Total int
+	Done  int
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UUIDList is a list of IDs stored as a jsonb array.
+type UUIDList []uuid.UUID
+
+func (l UUIDList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (l *UUIDList) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.New("unsupported type for UUIDList")
+	}
+	return json.Unmarshal(data, l)
+}
+
+// Comment is a message in a tada's discussion thread. Mentions holds the
+// IDs of the users @mentioned by email in Body.
+type Comment struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TadaID    uuid.UUID      `gorm:"type:uuid;not null;index" json:"tada_id"`
+	AuthorID  uuid.UUID      `gorm:"type:uuid;not null" json:"author_id"`
+	Body      string         `gorm:"type:text;not null" json:"body"`
+	Mentions  UUIDList       `gorm:"type:jsonb;not null;default:'[]'" json:"mentions"`
+	EditedAt  *time.Time     `json:"edited_at"`
+	CreatedAt time.Time      `gorm:"index" json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Author User `gorm:"foreignKey:AuthorID;references:ID" json:"author,omitempty"`
+}
+
+func (c *Comment) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+func (Comment) TableName() string {
+	return "comments"
+}
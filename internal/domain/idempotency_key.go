@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKey records a request sent with an Idempotency-Key header, so
+// that retries of it get the first response instead of repeating it.
+// Scope keeps the keys of different callers and endpoints apart, and
+// Fingerprint identifies the request the key was first used for. Status is
+// zero while that request is still running; it holds the key until
+// LockedUntil, which it keeps pushing back, and Token tells its claim apart
+// from that of a request that took the key over after its hold lapsed. The
+// record is kept until ExpiresAt.
+type IdempotencyKey struct {
+	Scope       string    `gorm:"size:255;primaryKey" json:"scope"`
+	Key         string    `gorm:"size:255;primaryKey" json:"key"`
+	Fingerprint string    `gorm:"size:64;not null" json:"fingerprint"`
+	Status      int       `gorm:"not null;default:0" json:"status"`
+	ContentType string    `gorm:"size:255;not null;default:''" json:"content_type"`
+	Body        []byte    `json:"-"`
+	Token       uuid.UUID `gorm:"type:uuid;not null;default:uuid_generate_v4()" json:"-"`
+	LockedUntil time.Time `gorm:"not null" json:"locked_until"`
+	ExpiresAt   time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Completed reports whether the response to the key's request is stored.
+func (k *IdempotencyKey) Completed() bool {
+	return k.Status != 0
+}
+
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
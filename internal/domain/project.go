@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Project groups tadas into a list. The tadas of an archived project are
+// kept but left out of default listings. Its tadas move through Workflow,
+// or through DefaultWorkflow if it has no states.
+type Project struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	WorkspaceID uuid.UUID      `gorm:"type:uuid;index" json:"workspace_id"`
+	Name        string         `gorm:"size:255;not null" json:"name"`
+	Description string         `gorm:"type:text" json:"description"`
+	OwnerID     uuid.UUID      `gorm:"type:uuid;not null;index" json:"owner_id"`
+	Archived    bool           `gorm:"not null;default:false" json:"archived"`
+	Workflow    Workflow       `gorm:"type:jsonb" json:"workflow"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Owner User `gorm:"foreignKey:OwnerID;references:ID" json:"owner,omitempty"`
+}
+
+func (p *Project) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+func (Project) TableName() string {
+	return "projects"
+}
+
+// ProjectStats counts a project's tadas by status category. Overdue tadas
+// are open and past their due time; they are also counted as open.
+type ProjectStats struct {
+	Open      int
+	Completed int
+	Cancelled int
+	Overdue   int
+}
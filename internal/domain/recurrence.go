@@ -0,0 +1,258 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRecurrence = errors.New("invalid recurrence rule")
+
+type RecurrenceFrequency string
+
+const (
+	FrequencyDaily   RecurrenceFrequency = "DAILY"
+	FrequencyWeekly  RecurrenceFrequency = "WEEKLY"
+	FrequencyMonthly RecurrenceFrequency = "MONTHLY"
+)
+
+// maxRecurrenceInterval bounds INTERVAL so that finding the next
+// occurrence stays cheap.
+const maxRecurrenceInterval = 366
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// RecurrenceRule is the subset of an RFC 5545 RRULE that tadas support:
+// FREQ (DAILY, WEEKLY or MONTHLY), INTERVAL, BYDAY (plain weekdays, without
+// ordinals), COUNT and UNTIL. Occurrences keep the time of day of the first
+// one, which plays the role of DTSTART.
+type RecurrenceRule struct {
+	Frequency RecurrenceFrequency
+	Interval  int
+	ByDay     []time.Weekday
+	// Count is the total number of occurrences, including the first; 0
+	// means unlimited.
+	Count int
+	Until *time.Time
+}
+
+// ParseRecurrenceRule parses a rule such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH".
+// A leading "RRULE:" is accepted.
+func ParseRecurrenceRule(s string) (*RecurrenceRule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return nil, fmt.Errorf("%w: empty rule", ErrInvalidRecurrence)
+	}
+
+	rule := &RecurrenceRule{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		key = strings.ToUpper(strings.TrimSpace(key))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !ok || value == "" {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalidRecurrence, part)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("%w: %s given twice", ErrInvalidRecurrence, key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "FREQ":
+			switch RecurrenceFrequency(value) {
+			case FrequencyDaily, FrequencyWeekly, FrequencyMonthly:
+				rule.Frequency = RecurrenceFrequency(value)
+			default:
+				return nil, fmt.Errorf("%w: unsupported FREQ %q", ErrInvalidRecurrence, value)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 || interval > maxRecurrenceInterval {
+				return nil, fmt.Errorf("%w: INTERVAL must be between 1 and %d", ErrInvalidRecurrence, maxRecurrenceInterval)
+			}
+			rule.Interval = interval
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				day, ok := weekdayCodes[strings.TrimSpace(code)]
+				if !ok {
+					return nil, fmt.Errorf("%w: unsupported BYDAY %q", ErrInvalidRecurrence, code)
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("%w: COUNT must be a positive number", ErrInvalidRecurrence)
+			}
+			rule.Count = count
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: UNTIL %q", ErrInvalidRecurrence, value)
+			}
+			rule.Until = &until
+		default:
+			return nil, fmt.Errorf("%w: unsupported part %s", ErrInvalidRecurrence, key)
+		}
+	}
+
+	if rule.Frequency == "" {
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalidRecurrence)
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, fmt.Errorf("%w: COUNT and UNTIL cannot both be set", ErrInvalidRecurrence)
+	}
+
+	return rule, nil
+}
+
+// parseUntil accepts the RFC 5545 date and UTC date-time forms.
+func parseUntil(value string) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("20060102", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// A bare date includes the whole day.
+	return t.Add(24*time.Hour - time.Second), nil
+}
+
+// String formats the rule in canonical form.
+func (r *RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + string(r.Frequency)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := append([]time.Weekday(nil), r.ByDay...)
+		sort.Slice(days, func(i, j int) bool { return mondayFirst(days[i]) < mondayFirst(days[j]) })
+
+		codes := make([]string, 0, len(days))
+		for i, day := range days {
+			if i > 0 && day == days[i-1] {
+				continue
+			}
+			codes = append(codes, strings.ToUpper(day.String()[:2]))
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next returns the occurrence that follows prev, the occurrence numbered
+// occurrence (the first is 1), or false if the series has ended. Intervals
+// are counted from prev, which must itself be an occurrence.
+func (r *RecurrenceRule) Next(prev time.Time, occurrence int) (time.Time, bool) {
+	if r.Count > 0 && occurrence >= r.Count {
+		return time.Time{}, false
+	}
+
+	// The longest gap is a monthly rule on the 31st or the 29th, which
+	// may skip several intervals before the day exists again.
+	maxDays := 8 * 31 * r.Interval
+	candidate := prev
+	for i := 0; i < maxDays; i++ {
+		candidate = candidate.AddDate(0, 0, 1)
+		// AddDate normalizes, so go back to prev's time of day in case a
+		// DST change moved it.
+		candidate = time.Date(candidate.Year(), candidate.Month(), candidate.Day(),
+			prev.Hour(), prev.Minute(), prev.Second(), prev.Nanosecond(), prev.Location())
+
+		if !r.matches(prev, candidate) {
+			continue
+		}
+		if r.Until != nil && candidate.After(*r.Until) {
+			return time.Time{}, false
+		}
+		return candidate, true
+	}
+
+	return time.Time{}, false
+}
+
+// Occurrences returns up to n occurrences following prev, as Next does.
+func (r *RecurrenceRule) Occurrences(prev time.Time, occurrence, n int) []time.Time {
+	occurrences := make([]time.Time, 0, n)
+	for len(occurrences) < n {
+		next, ok := r.Next(prev, occurrence)
+		if !ok {
+			break
+		}
+		occurrences = append(occurrences, next)
+		prev = next
+		occurrence++
+	}
+	return occurrences
+}
+
+// matches reports whether day is an occurrence of a series that has an
+// occurrence at anchor.
+func (r *RecurrenceRule) matches(anchor, day time.Time) bool {
+	switch r.Frequency {
+	case FrequencyDaily:
+		return daysBetween(anchor, day)%r.Interval == 0 && r.onDay(day, day.Weekday())
+	case FrequencyWeekly:
+		weeks := daysBetween(startOfWeek(anchor), startOfWeek(day)) / 7
+		return weeks%r.Interval == 0 && r.onDay(day, anchor.Weekday())
+	case FrequencyMonthly:
+		months := (day.Year()-anchor.Year())*12 + int(day.Month()-anchor.Month())
+		if months%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByDay) > 0 {
+			return r.onDay(day, -1)
+		}
+		return day.Day() == anchor.Day()
+	}
+	return false
+}
+
+// onDay reports whether day falls on one of ByDay, or on fallback when
+// ByDay is empty.
+func (r *RecurrenceRule) onDay(day time.Time, fallback time.Weekday) bool {
+	if len(r.ByDay) == 0 {
+		return day.Weekday() == fallback
+	}
+	for _, weekday := range r.ByDay {
+		if day.Weekday() == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// daysBetween counts calendar days from a to b, ignoring the time of day.
+func daysBetween(a, b time.Time) int {
+	ad := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	bd := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(bd.Sub(ad).Hours() / 24)
+}
+
+// startOfWeek returns the Monday of t's week, the RFC 5545 default WKST.
+func startOfWeek(t time.Time) time.Time {
+	return t.AddDate(0, 0, -mondayFirst(t.Weekday()))
+}
+
+func mondayFirst(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
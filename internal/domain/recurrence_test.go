@@ -0,0 +1,281 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrenceRule(t *testing.T) {
+	tests := []struct {
+		rule string
+		want string
+	}{
+		{"FREQ=DAILY", "FREQ=DAILY"},
+		{"RRULE:FREQ=DAILY;INTERVAL=1", "FREQ=DAILY"},
+		{"freq=weekly;interval=2;byday=th,mo", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH"},
+		{"FREQ=WEEKLY;BYDAY=SU,MO,MO", "FREQ=WEEKLY;BYDAY=MO,SU"},
+		{"FREQ=MONTHLY;INTERVAL=366;COUNT=3", "FREQ=MONTHLY;INTERVAL=366;COUNT=3"},
+		{"FREQ=DAILY;UNTIL=20260301T120000Z", "FREQ=DAILY;UNTIL=20260301T120000Z"},
+		{"FREQ=DAILY;UNTIL=20260301", "FREQ=DAILY;UNTIL=20260301T235959Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			rule, err := ParseRecurrenceRule(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseRecurrenceRule() error = %v", err)
+			}
+			if got := rule.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRecurrenceRuleRejects(t *testing.T) {
+	rules := []string{
+		"",
+		"RRULE:",
+		"INTERVAL=2",
+		"FREQ",
+		"FREQ=",
+		"FREQ=YEARLY",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;INTERVAL=367",
+		"FREQ=DAILY;INTERVAL=two",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=WEEKLY;BYDAY=MO,",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;UNTIL=tomorrow",
+		"FREQ=DAILY;COUNT=2;UNTIL=20260301",
+		"FREQ=MONTHLY;BYMONTHDAY=31",
+	}
+
+	for _, rule := range rules {
+		t.Run(rule, func(t *testing.T) {
+			if _, err := ParseRecurrenceRule(rule); !errors.Is(err, ErrInvalidRecurrence) {
+				t.Errorf("ParseRecurrenceRule() error = %v, want ErrInvalidRecurrence", err)
+			}
+		})
+	}
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 9, 30, 0, 0, time.UTC)
+}
+
+func TestRecurrenceRuleOccurrences(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+		// first is the occurrence the series continues from, occurrence
+		// number 1.
+		first time.Time
+		n     int
+		want  []time.Time
+	}{
+		{
+			name:  "daily",
+			rule:  "FREQ=DAILY",
+			first: date(2026, 3, 2),
+			n:     3,
+			want:  []time.Time{date(2026, 3, 3), date(2026, 3, 4), date(2026, 3, 5)},
+		},
+		{
+			name:  "every third day across a month end",
+			rule:  "FREQ=DAILY;INTERVAL=3",
+			first: date(2026, 2, 26),
+			n:     3,
+			want:  []time.Time{date(2026, 3, 1), date(2026, 3, 4), date(2026, 3, 7)},
+		},
+		{
+			name:  "daily on weekdays only",
+			rule:  "FREQ=DAILY;BYDAY=MO,WE,FR",
+			first: date(2026, 3, 6),
+			n:     3,
+			want:  []time.Time{date(2026, 3, 9), date(2026, 3, 11), date(2026, 3, 13)},
+		},
+		{
+			name:  "weekly on the first occurrence's weekday",
+			rule:  "FREQ=WEEKLY",
+			first: date(2026, 3, 3),
+			n:     2,
+			want:  []time.Time{date(2026, 3, 10), date(2026, 3, 17)},
+		},
+		{
+			name:  "fortnightly on two weekdays",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+			first: date(2026, 3, 2),
+			n:     4,
+			want:  []time.Time{date(2026, 3, 5), date(2026, 3, 16), date(2026, 3, 19), date(2026, 3, 30)},
+		},
+		{
+			name:  "weekly on a weekday before the first occurrence's",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO",
+			first: date(2026, 3, 4),
+			n:     2,
+			want:  []time.Time{date(2026, 3, 16), date(2026, 3, 30)},
+		},
+		{
+			name:  "monthly on the 15th",
+			rule:  "FREQ=MONTHLY",
+			first: date(2026, 11, 15),
+			n:     3,
+			want:  []time.Time{date(2026, 12, 15), date(2027, 1, 15), date(2027, 2, 15)},
+		},
+		{
+			name:  "monthly on the 31st skips shorter months",
+			rule:  "FREQ=MONTHLY",
+			first: date(2026, 1, 31),
+			n:     4,
+			want:  []time.Time{date(2026, 3, 31), date(2026, 5, 31), date(2026, 7, 31), date(2026, 8, 31)},
+		},
+		{
+			name:  "monthly on the 30th skips February",
+			rule:  "FREQ=MONTHLY",
+			first: date(2026, 1, 30),
+			n:     2,
+			want:  []time.Time{date(2026, 3, 30), date(2026, 4, 30)},
+		},
+		{
+			name:  "monthly on the 29th skips February outside leap years",
+			rule:  "FREQ=MONTHLY",
+			first: date(2026, 1, 29),
+			n:     1,
+			want:  []time.Time{date(2026, 3, 29)},
+		},
+		{
+			name:  "monthly on the 29th in a leap year",
+			rule:  "FREQ=MONTHLY",
+			first: date(2028, 1, 29),
+			n:     2,
+			want:  []time.Time{date(2028, 2, 29), date(2028, 3, 29)},
+		},
+		{
+			name:  "every other month on the 31st skips several intervals",
+			rule:  "FREQ=MONTHLY;INTERVAL=2",
+			first: date(2026, 5, 31),
+			n:     2,
+			want:  []time.Time{date(2026, 7, 31), date(2027, 1, 31)},
+		},
+		{
+			name:  "monthly on a weekday",
+			rule:  "FREQ=MONTHLY;BYDAY=FR",
+			first: date(2026, 3, 27),
+			n:     2,
+			want:  []time.Time{date(2026, 4, 3), date(2026, 4, 10)},
+		},
+		{
+			name:  "COUNT includes the first occurrence",
+			rule:  "FREQ=DAILY;COUNT=3",
+			first: date(2026, 3, 2),
+			n:     10,
+			want:  []time.Time{date(2026, 3, 3), date(2026, 3, 4)},
+		},
+		{
+			name:  "COUNT of one has nothing to follow",
+			rule:  "FREQ=WEEKLY;COUNT=1",
+			first: date(2026, 3, 2),
+			n:     10,
+			want:  []time.Time{},
+		},
+		{
+			name:  "UNTIL a date includes that whole day",
+			rule:  "FREQ=DAILY;UNTIL=20260305",
+			first: date(2026, 3, 2),
+			n:     10,
+			want:  []time.Time{date(2026, 3, 3), date(2026, 3, 4), date(2026, 3, 5)},
+		},
+		{
+			name:  "UNTIL a time before the day's occurrence",
+			rule:  "FREQ=DAILY;UNTIL=20260305T080000Z",
+			first: date(2026, 3, 2),
+			n:     10,
+			want:  []time.Time{date(2026, 3, 3), date(2026, 3, 4)},
+		},
+		{
+			name:  "UNTIL on an occurrence includes it",
+			rule:  "FREQ=WEEKLY;UNTIL=20260316T093000Z",
+			first: date(2026, 3, 2),
+			n:     10,
+			want:  []time.Time{date(2026, 3, 9), date(2026, 3, 16)},
+		},
+		{
+			name:  "UNTIL before the next occurrence",
+			rule:  "FREQ=MONTHLY;UNTIL=20260330",
+			first: date(2026, 1, 31),
+			n:     10,
+			want:  []time.Time{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRecurrenceRule(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseRecurrenceRule(%q) error = %v", tt.rule, err)
+			}
+
+			got := rule.Occurrences(tt.first, 1, tt.n)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Occurrences() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("Occurrences()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRecurrenceRuleNextCount(t *testing.T) {
+	rule, err := ParseRecurrenceRule("FREQ=DAILY;COUNT=3")
+	if err != nil {
+		t.Fatalf("ParseRecurrenceRule() error = %v", err)
+	}
+	prev := date(2026, 3, 2)
+
+	tests := []struct {
+		occurrence int
+		wantOK     bool
+	}{
+		{1, true},
+		{2, true},
+		{3, false},
+		{4, false},
+	}
+
+	for _, tt := range tests {
+		next, ok := rule.Next(prev, tt.occurrence)
+		if ok != tt.wantOK {
+			t.Errorf("Next(prev, %d) ok = %v, want %v", tt.occurrence, ok, tt.wantOK)
+		}
+		if ok && !next.Equal(date(2026, 3, 3)) {
+			t.Errorf("Next(prev, %d) = %v, want the following day", tt.occurrence, next)
+		}
+	}
+}
+
+func TestRecurrenceRuleNextKeepsTimeOfDay(t *testing.T) {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+	rule, err := ParseRecurrenceRule("FREQ=DAILY")
+	if err != nil {
+		t.Fatalf("ParseRecurrenceRule() error = %v", err)
+	}
+
+	// Clocks in New York go forward on 8 March 2026.
+	prev := time.Date(2026, 3, 7, 9, 30, 0, 0, location)
+	next, ok := rule.Next(prev, 1)
+	if !ok {
+		t.Fatalf("Next() ended the series")
+	}
+	if want := time.Date(2026, 3, 8, 9, 30, 0, 0, location); !next.Equal(want) {
+		t.Errorf("Next() = %v, want %v", next, want)
+	}
+}
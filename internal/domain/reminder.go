@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ReminderKind string
+
+const (
+	ReminderDueSoon ReminderKind = "due_soon"
+	ReminderOverdue ReminderKind = "overdue"
+)
+
+// Reminder records a notification sent about a tada's due time. At most
+// one of each kind is sent per due time, so moving DueAt re-arms both. A
+// reminder that could not be sent is kept unsent and tried again at
+// NextAttemptAt; one with neither SentAt nor NextAttemptAt was given up.
+type Reminder struct {
+	ID          uuid.UUID    `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TadaID      uuid.UUID    `gorm:"type:uuid;not null;uniqueIndex:idx_reminders_tada_kind_due" json:"tada_id"`
+	Kind        ReminderKind `gorm:"type:varchar(20);not null;uniqueIndex:idx_reminders_tada_kind_due" json:"kind"`
+	DueAt       time.Time    `gorm:"not null;uniqueIndex:idx_reminders_tada_kind_due" json:"due_at"`
+	RecipientID uuid.UUID    `gorm:"type:uuid;not null" json:"recipient_id"`
+	SentAt      *time.Time   `json:"sent_at"`
+
+	Attempts      int        `gorm:"not null;default:1" json:"attempts"`
+	LastError     string     `gorm:"type:text" json:"last_error"`
+	NextAttemptAt *time.Time `json:"next_attempt_at"`
+}
+
+func (r *Reminder) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+func (Reminder) TableName() string {
+	return "reminders"
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SearchHitKind says whether a search hit is a tada or one of its comments.
+type SearchHitKind string
+
+const (
+	SearchHitTada    SearchHitKind = "tada"
+	SearchHitComment SearchHitKind = "comment"
+)
+
+// HighlightStart and HighlightEnd surround the matched terms in a search
+// snippet. They are private-use characters, so they cannot be confused with
+// anything in the text itself.
+const (
+	HighlightStart = "\uE000"
+	HighlightEnd   = "\uE001"
+)
+
+// SearchHit is a tada or comment that matches a full-text search. ID is
+// the hit's own ID and TadaID the tada it belongs to, which are the same
+// for a tada. Snippet is an excerpt of the matching text with the matched
+// terms highlighted.
+type SearchHit struct {
+	Kind      SearchHitKind
+	ID        uuid.UUID
+	TadaID    uuid.UUID
+	Rank      float64
+	Snippet   string
+	CreatedAt time.Time
+}
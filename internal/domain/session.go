@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Session is a login issued to a user. It holds the hash of the current
+// refresh token, and its ID is embedded in every access token minted for
+// it, so revoking the session invalidates both.
+type Session struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	UserID           uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	RefreshTokenHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt        time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt        *time.Time `json:"revoked_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+func (s *Session) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// Active reports whether the session can still be used at the given time.
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+func (Session) TableName() string {
+	return "sessions"
+}
+
+// RetiredRefreshToken remembers the hash of a refresh token that a session
+// has rotated away from. Together with the current one, these make up the
+// session's token family: a retired token presented again means it was
+// stolen or replayed, and the session is revoked.
+type RetiredRefreshToken struct {
+	TokenHash string    `gorm:"size:64;primaryKey" json:"-"`
+	SessionID uuid.UUID `gorm:"type:uuid;not null;index" json:"session_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (RetiredRefreshToken) TableName() string {
+	return "retired_refresh_tokens"
+}
@@ -7,6 +7,8 @@ import (
     "gorm.io/gorm"
 )
 
+// TadaStatus names a state in a tada's workflow. The constants are the
+// states of DefaultWorkflow.
 type TadaStatus string
 
 const (
@@ -15,15 +17,51 @@ const (
     StatusCompleted  TadaStatus = "completed"
 )
 
+// TadaPriority is how urgent a tada is.
+type TadaPriority string
+
+const (
+    PriorityLow    TadaPriority = "low"
+    PriorityMedium TadaPriority = "medium"
+    PriorityHigh   TadaPriority = "high"
+    PriorityUrgent TadaPriority = "urgent"
+)
+
+// Priorities lists the priorities from least to most urgent.
+var Priorities = []TadaPriority{PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent}
+
+// Rank orders priorities by urgency, from 0 for low upwards.
+func (p TadaPriority) Rank() int {
+    for i, priority := range Priorities {
+        if priority == p {
+            return i
+        }
+    }
+    return -1
+}
+
+// Tada is a task. Estimate is the expected effort in minutes. Version
+// starts at 1 and goes up by one with every save, so that a save made
+// against an outdated copy can be detected.
 type Tada struct {
     ID          uuid.UUID      `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+    WorkspaceID uuid.UUID      `gorm:"type:uuid;index" json:"workspace_id"`
     Name        string         `gorm:"size:255;not null" json:"name"`
     Description string         `gorm:"type:text" json:"description"`
     CreatedBy   uuid.UUID      `gorm:"type:uuid;not null;index" json:"created_by"`
     AssignedTo  *uuid.UUID     `gorm:"type:uuid;index" json:"assigned_to"`
+    ParentID    *uuid.UUID     `gorm:"type:uuid;index" json:"parent_id"`
+    ProjectID   *uuid.UUID     `gorm:"type:uuid;index" json:"project_id"`
     Status      TadaStatus     `gorm:"type:varchar(20);not null;default:'in_progress'" json:"status"`
+    Category    StatusCategory `gorm:"column:status_category;type:varchar(10);not null;default:'open';index" json:"status_category"`
+    Priority    TadaPriority   `gorm:"type:varchar(10);not null;default:'medium'" json:"priority"`
+    Estimate    *int           `gorm:"column:estimate_minutes" json:"estimate_minutes"`
     DueAt       *time.Time     `json:"due_at"`
     CompletedAt *time.Time     `json:"completed_at"`
+    Recurrence  string         `gorm:"size:255" json:"recurrence"`
+    SeriesID    *uuid.UUID     `gorm:"type:uuid;index" json:"series_id"`
+    Occurrence  int            `gorm:"not null;default:1" json:"occurrence"`
+    Version     int            `gorm:"not null;default:1" json:"version"`
     CreatedAt   time.Time      `json:"created_at"`
     UpdatedAt   time.Time      `json:"updated_at"`
     DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
@@ -31,6 +69,7 @@ type Tada struct {
     // Relationships
     Creator  User  `gorm:"foreignKey:CreatedBy;references:ID" json:"creator,omitempty"`
     Assignee *User `gorm:"foreignKey:AssignedTo;references:ID" json:"assignee,omitempty"`
+    Tags     []Tag `gorm:"many2many:tada_tags" json:"tags,omitempty"`
 }
 
 func (t *Tada) BeforeCreate(tx *gorm.DB) error {
@@ -40,11 +79,27 @@ func (t *Tada) BeforeCreate(tx *gorm.DB) error {
     if t.Status == "" {
         t.Status = StatusInProgress
     }
+    if t.Category == "" {
+        if state, ok := DefaultWorkflow.State(t.Status); ok {
+            t.Category = state.Category
+        } else {
+            t.Category = CategoryOpen
+        }
+    }
+    if t.Priority == "" {
+        t.Priority = PriorityMedium
+    }
+    if t.Occurrence == 0 {
+        t.Occurrence = 1
+    }
+    if t.Version == 0 {
+        t.Version = 1
+    }
     return nil
 }
 
 func (t *Tada) BeforeUpdate(tx *gorm.DB) error {
-    if t.Status == StatusCompleted && t.CompletedAt == nil {
+    if t.Category == CategoryCompleted && t.CompletedAt == nil {
         now := time.Now()
         t.CompletedAt = &now
     }
@@ -54,3 +109,31 @@ func (t *Tada) BeforeUpdate(tx *gorm.DB) error {
 func (Tada) TableName() string {
     return "tadas"
 }
+
+// SetState moves the tada into a workflow state. CompletedAt is set on
+// entering a completed state and cleared on leaving one.
+func (t *Tada) SetState(state WorkflowState, now time.Time) {
+    t.Status = state.Name
+    t.Category = state.Category
+    if state.Category != CategoryCompleted {
+        t.CompletedAt = nil
+    } else if t.CompletedAt == nil {
+        t.CompletedAt = &now
+    }
+}
+
+// SeriesRootID returns the ID of the first tada of the recurring series
+// this tada belongs to, which is its own ID for the first.
+func (t *Tada) SeriesRootID() uuid.UUID {
+    if t.SeriesID != nil {
+        return *t.SeriesID
+    }
+    return t.ID
+}
+
+// TadaProgress counts a tada's direct subtasks and how many of them are
+// completed.
+type TadaProgress struct {
+    Completed int `json:"completed"`
+    Total     int `json:"total"`
+}
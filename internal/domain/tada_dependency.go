@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TadaDependency records that the blocker tada blocks the blocked one: the
+// blocked tada cannot be completed until the blocker is done.
+type TadaDependency struct {
+	BlockerID uuid.UUID  `gorm:"type:uuid;primaryKey" json:"blocker_id"`
+	BlockedID uuid.UUID  `gorm:"type:uuid;primaryKey;index" json:"blocked_id"`
+	CreatedBy *uuid.UUID `gorm:"type:uuid" json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (TadaDependency) TableName() string {
+	return "tada_dependencies"
+}
+
+// TadaBlocker is a tada blocking another, with the category of the
+// blocker's current status.
+type TadaBlocker struct {
+	BlockedID uuid.UUID
+	BlockerID uuid.UUID
+	Category  StatusCategory
+}
@@ -0,0 +1,174 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TadaEventType string
+
+const (
+	TadaEventCreated TadaEventType = "created"
+	TadaEventUpdated TadaEventType = "updated"
+	// TadaEventCompleted records the CompletedAt stamp set by Tada.BeforeUpdate,
+	// separately from the update that triggered it.
+	TadaEventCompleted TadaEventType = "completed"
+	TadaEventDeleted   TadaEventType = "deleted"
+)
+
+// FieldChange is the old and new value of a single field.
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// FieldChanges maps field names, as they appear in JSON, to their change.
+// It is stored as a jsonb column.
+type FieldChanges map[string]FieldChange
+
+func (c FieldChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (c *FieldChanges) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.New("unsupported type for FieldChanges")
+	}
+	return json.Unmarshal(data, c)
+}
+
+// TadaEvent is one entry in a tada's audit history.
+type TadaEvent struct {
+	ID        uuid.UUID     `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TadaID    uuid.UUID     `gorm:"type:uuid;not null;index" json:"tada_id"`
+	ActorID   *uuid.UUID    `gorm:"type:uuid" json:"actor_id"`
+	Type      TadaEventType `gorm:"type:varchar(20);not null" json:"type"`
+	Changes   FieldChanges  `gorm:"type:jsonb;not null;default:'{}'" json:"changes"`
+	CreatedAt time.Time     `gorm:"index" json:"created_at"`
+}
+
+func (e *TadaEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+func (TadaEvent) TableName() string {
+	return "tada_events"
+}
+
+// DiffTadas returns the fields whose values differ between before and after.
+func DiffTadas(before, after *Tada) FieldChanges {
+	changes := FieldChanges{}
+
+	if before.Name != after.Name {
+		changes["name"] = FieldChange{From: before.Name, To: after.Name}
+	}
+	if before.Description != after.Description {
+		changes["description"] = FieldChange{From: before.Description, To: after.Description}
+	}
+	if !equalUUIDPtr(before.AssignedTo, after.AssignedTo) {
+		changes["assigned_to"] = FieldChange{From: before.AssignedTo, To: after.AssignedTo}
+	}
+	if !equalUUIDPtr(before.ParentID, after.ParentID) {
+		changes["parent_id"] = FieldChange{From: before.ParentID, To: after.ParentID}
+	}
+	if !equalUUIDPtr(before.ProjectID, after.ProjectID) {
+		changes["project_id"] = FieldChange{From: before.ProjectID, To: after.ProjectID}
+	}
+	if before.Status != after.Status {
+		changes["status"] = FieldChange{From: before.Status, To: after.Status}
+	}
+	if before.Priority != after.Priority {
+		changes["priority"] = FieldChange{From: before.Priority, To: after.Priority}
+	}
+	if !equalIntPtr(before.Estimate, after.Estimate) {
+		changes["estimate_minutes"] = FieldChange{From: before.Estimate, To: after.Estimate}
+	}
+	if !equalTimePtr(before.DueAt, after.DueAt) {
+		changes["due_at"] = FieldChange{From: before.DueAt, To: after.DueAt}
+	}
+	if !equalTimePtr(before.CompletedAt, after.CompletedAt) {
+		changes["completed_at"] = FieldChange{From: before.CompletedAt, To: after.CompletedAt}
+	}
+	if before.Recurrence != after.Recurrence {
+		changes["recurrence"] = FieldChange{From: before.Recurrence, To: after.Recurrence}
+	}
+	if !equalStrings(TagNames(before.Tags), TagNames(after.Tags)) {
+		changes["tags"] = FieldChange{From: TagNames(before.Tags), To: TagNames(after.Tags)}
+	}
+
+	return changes
+}
+
+// SnapshotTada returns every audited field of a tada as a change from nil,
+// as recorded when a tada is created.
+func SnapshotTada(tada *Tada) FieldChanges {
+	changes := DiffTadas(&Tada{}, tada)
+	for field, change := range changes {
+		change.From = nil
+		changes[field] = change
+	}
+	return changes
+}
+
+func equalUUIDPtr(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalIntPtr(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// equalStrings compares two lists of names regardless of order.
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[string]int, len(a))
+	for _, s := range a {
+		seen[s]++
+	}
+	for _, s := range b {
+		if seen[s] == 0 {
+			return false
+		}
+		seen[s]--
+	}
+	return true
+}
+
+func equalTimePtr(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
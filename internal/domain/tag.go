@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Tag is a label that can be attached to any number of tadas in its
+// workspace. Names are stored lowercased and are unique within the
+// workspace.
+type Tag struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	WorkspaceID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_tags_workspace_name" json:"workspace_id"`
+	Name        string    `gorm:"size:64;not null;uniqueIndex:idx_tags_workspace_name" json:"name"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (t *Tag) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// TagNames returns the names of tags in order.
+func TagNames(tags []Tag) []string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return names
+}
@@ -7,13 +7,17 @@ import (
 	"gorm.io/gorm"
 )
 
+// User is an account. Version starts at 1 and goes up by one with every
+// save, so that a save made against an outdated copy can be detected.
 type User struct {
-	ID        uuid.UUID      `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
-	Name      string         `gorm:"size:255;not null" json:"name"`
-	Email     string         `gorm:"size:255;not null;uniqueIndex" json:"email"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID           uuid.UUID      `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	Name         string         `gorm:"size:255;not null" json:"name"`
+	Email        string         `gorm:"size:255;not null;uniqueIndex" json:"email"`
+	PasswordHash string         `gorm:"size:255" json:"-"`
+	Version      int            `gorm:"not null;default:1" json:"version"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Relationships
 	CreatedTadas  []Tada `gorm:"foreignKey:CreatedBy" json:"created_tadas,omitempty"`
@@ -24,6 +28,9 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 	if u.ID == uuid.Nil {
 		u.ID = uuid.New()
 	}
+	if u.Version == 0 {
+		u.Version = 1
+	}
 	return nil
 }
 
@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TadaChangeType names a tada lifecycle event as published to webhook
+// subscribers.
+type TadaChangeType string
+
+const (
+	TadaChangeCreated   TadaChangeType = "tada.created"
+	TadaChangeUpdated   TadaChangeType = "tada.updated"
+	TadaChangeCompleted TadaChangeType = "tada.completed"
+	TadaChangeAssigned  TadaChangeType = "tada.assigned"
+	TadaChangeDeleted   TadaChangeType = "tada.deleted"
+)
+
+// TadaChangeTypes lists every change type a webhook can subscribe to.
+var TadaChangeTypes = []TadaChangeType{
+	TadaChangeCreated,
+	TadaChangeUpdated,
+	TadaChangeCompleted,
+	TadaChangeAssigned,
+	TadaChangeDeleted,
+}
+
+// TadaChangeTypeList is a set of change types stored as a jsonb array.
+type TadaChangeTypeList []TadaChangeType
+
+func (l TadaChangeTypeList) Contains(changeType TadaChangeType) bool {
+	for _, t := range l {
+		if t == changeType {
+			return true
+		}
+	}
+	return false
+}
+
+func (l TadaChangeTypeList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (l *TadaChangeTypeList) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.New("unsupported type for TadaChangeTypeList")
+	}
+	return json.Unmarshal(data, l)
+}
+
+// Webhook is a subscription that receives a signed POST for each tada
+// change of the selected types in its workspace, for as long as its owner
+// is a member there.
+type Webhook struct {
+	ID          uuid.UUID          `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	OwnerID     uuid.UUID          `gorm:"type:uuid;not null;index" json:"owner_id"`
+	WorkspaceID uuid.UUID          `gorm:"type:uuid;index" json:"workspace_id"`
+	URL         string             `gorm:"size:2048;not null" json:"url"`
+	Secret      string             `gorm:"size:128;not null" json:"-"`
+	Events      TadaChangeTypeList `gorm:"type:jsonb;not null;default:'[]'" json:"events"`
+	Active      bool               `gorm:"not null;default:true" json:"active"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt     `gorm:"index" json:"-"`
+
+	// OwnerRemoved is set on the webhooks of claimed deliveries whose owner
+	// is no longer a member of the workspace.
+	OwnerRemoved bool `gorm:"-" json:"-"`
+}
+
+func (w *Webhook) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
+
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	DeliveryPending   WebhookDeliveryStatus = "pending"
+	DeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	DeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is one event queued for a webhook, together with the
+// outcome of its latest attempt. Pending deliveries are retried at
+// NextAttemptAt until they succeed or run out of attempts.
+type WebhookDelivery struct {
+	ID            uuid.UUID             `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	WebhookID     uuid.UUID             `gorm:"type:uuid;not null;index" json:"webhook_id"`
+	EventType     TadaChangeType        `gorm:"type:varchar(32);not null" json:"event_type"`
+	Payload       string                `gorm:"type:jsonb;not null" json:"payload"`
+	Status        WebhookDeliveryStatus `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	Attempts      int                   `gorm:"not null;default:0" json:"attempts"`
+	ResponseCode  *int                  `json:"response_code"`
+	LastError     string                `gorm:"type:text" json:"last_error"`
+	NextAttemptAt time.Time             `gorm:"not null;index" json:"next_attempt_at"`
+	DeliveredAt   *time.Time            `json:"delivered_at"`
+	CreatedAt     time.Time             `json:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at"`
+
+	// Relationships
+	Webhook Webhook `gorm:"foreignKey:WebhookID;references:ID" json:"-"`
+}
+
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -0,0 +1,183 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// StatusCategory is what a workflow state means to the rest of the app:
+// whether a tada in it is still open, done, or abandoned. Blocking,
+// subtask progress, reminders and stats all go by category, never by the
+// state's name.
+type StatusCategory string
+
+const (
+	CategoryOpen      StatusCategory = "open"
+	CategoryCompleted StatusCategory = "completed"
+	CategoryCancelled StatusCategory = "cancelled"
+)
+
+// ErrInvalidWorkflow is wrapped by the errors Workflow.Validate returns.
+var ErrInvalidWorkflow = errors.New("invalid workflow")
+
+var stateNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,19}$`)
+
+// WorkflowState is a status a tada can be in.
+type WorkflowState struct {
+	Name     TadaStatus     `json:"name"`
+	Category StatusCategory `json:"category"`
+}
+
+// WorkflowTransition allows tadas to move from one state to another.
+type WorkflowTransition struct {
+	From TadaStatus `json:"from"`
+	To   TadaStatus `json:"to"`
+}
+
+// Workflow is the state machine a project's tadas move through. New tadas
+// start in Initial. Transitions lists the allowed moves between states;
+// without any, every state can be reached from every other. A workflow
+// with no states stands for DefaultWorkflow. It is stored as a jsonb
+// column.
+type Workflow struct {
+	Initial     TadaStatus           `json:"initial"`
+	States      []WorkflowState      `json:"states"`
+	Transitions []WorkflowTransition `json:"transitions,omitempty"`
+}
+
+// DefaultWorkflow is used by tadas outside a project and by projects that
+// do not define their own.
+var DefaultWorkflow = Workflow{
+	Initial: StatusInProgress,
+	States: []WorkflowState{
+		{Name: StatusInProgress, Category: CategoryOpen},
+		{Name: StatusCompleted, Category: CategoryCompleted},
+		{Name: StatusCancelled, Category: CategoryCancelled},
+	},
+}
+
+// IsDefault reports whether w has no states of its own.
+func (w Workflow) IsDefault() bool {
+	return len(w.States) == 0
+}
+
+// Effective returns w, or DefaultWorkflow if w has no states.
+func (w Workflow) Effective() Workflow {
+	if w.IsDefault() {
+		return DefaultWorkflow
+	}
+	return w
+}
+
+// State returns the state with the given name.
+func (w Workflow) State(name TadaStatus) (WorkflowState, bool) {
+	for _, state := range w.States {
+		if state.Name == name {
+			return state, true
+		}
+	}
+	return WorkflowState{}, false
+}
+
+// CanTransition reports whether a tada may move from one state to
+// another. Staying in the same state is always allowed.
+func (w Workflow) CanTransition(from, to TadaStatus) bool {
+	if from == to || len(w.Transitions) == 0 {
+		return true
+	}
+	for _, transition := range w.Transitions {
+		if transition.From == from && transition.To == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CompletionFrom returns the first completed state a tada can move to from
+// the given state.
+func (w Workflow) CompletionFrom(from TadaStatus) (WorkflowState, bool) {
+	for _, state := range w.States {
+		if state.Category == CategoryCompleted && w.CanTransition(from, state.Name) {
+			return state, true
+		}
+	}
+	return WorkflowState{}, false
+}
+
+// Validate checks that w is a usable state machine: state names are
+// unique, lowercase identifiers of up to 20 characters; there is at least
+// one open and one completed state; Initial is an open state; and
+// transitions connect distinct, known states.
+func (w Workflow) Validate() error {
+	if w.IsDefault() {
+		return nil
+	}
+
+	seen := make(map[TadaStatus]bool, len(w.States))
+	categories := make(map[StatusCategory]bool)
+	for _, state := range w.States {
+		if !stateNamePattern.MatchString(string(state.Name)) {
+			return fmt.Errorf("%w: state name %q must be a lowercase identifier of up to 20 characters", ErrInvalidWorkflow, state.Name)
+		}
+		if seen[state.Name] {
+			return fmt.Errorf("%w: state %q is defined twice", ErrInvalidWorkflow, state.Name)
+		}
+		switch state.Category {
+		case CategoryOpen, CategoryCompleted, CategoryCancelled:
+		default:
+			return fmt.Errorf("%w: state %q has unknown category %q", ErrInvalidWorkflow, state.Name, state.Category)
+		}
+		seen[state.Name] = true
+		categories[state.Category] = true
+	}
+
+	if !categories[CategoryOpen] || !categories[CategoryCompleted] {
+		return fmt.Errorf("%w: it needs at least one open and one completed state", ErrInvalidWorkflow)
+	}
+
+	initial, ok := w.State(w.Initial)
+	if !ok || initial.Category != CategoryOpen {
+		return fmt.Errorf("%w: initial state %q must be one of its open states", ErrInvalidWorkflow, w.Initial)
+	}
+
+	for _, transition := range w.Transitions {
+		if !seen[transition.From] || !seen[transition.To] {
+			return fmt.Errorf("%w: transition %s -> %s names an unknown state", ErrInvalidWorkflow, transition.From, transition.To)
+		}
+		if transition.From == transition.To {
+			return fmt.Errorf("%w: transition %s -> %s goes nowhere", ErrInvalidWorkflow, transition.From, transition.To)
+		}
+	}
+
+	return nil
+}
+
+func (w Workflow) Value() (driver.Value, error) {
+	if w.IsDefault() {
+		return nil, nil
+	}
+	data, err := json.Marshal(w)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (w *Workflow) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*w = Workflow{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.New("unsupported type for Workflow")
+	}
+	return json.Unmarshal(data, w)
+}
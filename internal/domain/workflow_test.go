@@ -0,0 +1,284 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// reviewWorkflow is a workflow with a review step: work goes from todo to
+// doing, through review, and is either done or sent back.
+func reviewWorkflow() Workflow {
+	return Workflow{
+		Initial: "todo",
+		States: []WorkflowState{
+			{Name: "todo", Category: CategoryOpen},
+			{Name: "doing", Category: CategoryOpen},
+			{Name: "review", Category: CategoryOpen},
+			{Name: "done", Category: CategoryCompleted},
+			{Name: "wont_do", Category: CategoryCancelled},
+		},
+		Transitions: []WorkflowTransition{
+			{From: "todo", To: "doing"},
+			{From: "doing", To: "review"},
+			{From: "review", To: "doing"},
+			{From: "review", To: "done"},
+			{From: "todo", To: "wont_do"},
+		},
+	}
+}
+
+func TestWorkflowValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(w *Workflow)
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			edit: func(w *Workflow) {},
+		},
+		{
+			name: "no transitions",
+			edit: func(w *Workflow) { w.Transitions = nil },
+		},
+		{
+			name: "no cancelled state",
+			edit: func(w *Workflow) {
+				w.States = w.States[:4]
+				w.Transitions = w.Transitions[:4]
+			},
+		},
+		{
+			name: "no states stands for the default",
+			edit: func(w *Workflow) { *w = Workflow{} },
+		},
+		{
+			name:    "state name with capitals",
+			edit:    func(w *Workflow) { w.States[1].Name = "Doing" },
+			wantErr: true,
+		},
+		{
+			name:    "state name starting with a digit",
+			edit:    func(w *Workflow) { w.States[1].Name = "2nd_pass" },
+			wantErr: true,
+		},
+		{
+			name:    "state name over 20 characters",
+			edit:    func(w *Workflow) { w.States[1].Name = TadaStatus(strings.Repeat("a", 21)) },
+			wantErr: true,
+		},
+		{
+			name:    "state defined twice",
+			edit:    func(w *Workflow) { w.States[2].Name = "doing" },
+			wantErr: true,
+		},
+		{
+			name:    "unknown category",
+			edit:    func(w *Workflow) { w.States[4].Category = "archived" },
+			wantErr: true,
+		},
+		{
+			name:    "no completed state",
+			edit:    func(w *Workflow) { w.States[3].Category = CategoryCancelled },
+			wantErr: true,
+		},
+		{
+			name: "no open state",
+			edit: func(w *Workflow) {
+				w.Initial = "done"
+				w.States = w.States[3:]
+				w.Transitions = nil
+			},
+			wantErr: true,
+		},
+		{
+			name:    "initial state missing",
+			edit:    func(w *Workflow) { w.Initial = "" },
+			wantErr: true,
+		},
+		{
+			name:    "initial state unknown",
+			edit:    func(w *Workflow) { w.Initial = "backlog" },
+			wantErr: true,
+		},
+		{
+			name:    "initial state not open",
+			edit:    func(w *Workflow) { w.Initial = "done" },
+			wantErr: true,
+		},
+		{
+			name:    "transition from an unknown state",
+			edit:    func(w *Workflow) { w.Transitions[0].From = "backlog" },
+			wantErr: true,
+		},
+		{
+			name:    "transition to an unknown state",
+			edit:    func(w *Workflow) { w.Transitions[0].To = "backlog" },
+			wantErr: true,
+		},
+		{
+			name:    "transition to the same state",
+			edit:    func(w *Workflow) { w.Transitions[0].To = "todo" },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := reviewWorkflow()
+			tt.edit(&workflow)
+
+			err := workflow.Validate()
+			if tt.wantErr && !errors.Is(err, ErrInvalidWorkflow) {
+				t.Errorf("Validate() error = %v, want ErrInvalidWorkflow", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Validate() error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestDefaultWorkflow(t *testing.T) {
+	if err := DefaultWorkflow.Validate(); err != nil {
+		t.Fatalf("DefaultWorkflow.Validate() error = %v", err)
+	}
+
+	workflow := Workflow{}
+	if !workflow.IsDefault() {
+		t.Errorf("IsDefault() = false for a workflow with no states")
+	}
+	effective := workflow.Effective()
+	if effective.Initial != StatusInProgress {
+		t.Errorf("Effective().Initial = %q, want %q", effective.Initial, StatusInProgress)
+	}
+	if reviewWorkflow().IsDefault() {
+		t.Errorf("IsDefault() = true for a workflow with states")
+	}
+	if got := reviewWorkflow().Effective().Initial; got != "todo" {
+		t.Errorf("Effective().Initial = %q, want the workflow's own", got)
+	}
+
+	categories := map[TadaStatus]StatusCategory{
+		StatusInProgress: CategoryOpen,
+		StatusCompleted:  CategoryCompleted,
+		StatusCancelled:  CategoryCancelled,
+	}
+	for name, want := range categories {
+		state, ok := DefaultWorkflow.State(name)
+		if !ok || state.Category != want {
+			t.Errorf("State(%q) = %+v, %v, want category %q", name, state, ok, want)
+		}
+	}
+}
+
+func TestWorkflowState(t *testing.T) {
+	workflow := reviewWorkflow()
+
+	tests := []struct {
+		name         string
+		wantCategory StatusCategory
+		wantOK       bool
+	}{
+		{"todo", CategoryOpen, true},
+		{"review", CategoryOpen, true},
+		{"done", CategoryCompleted, true},
+		{"wont_do", CategoryCancelled, true},
+		{"in_progress", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		state, ok := workflow.State(TadaStatus(tt.name))
+		if ok != tt.wantOK || state.Category != tt.wantCategory {
+			t.Errorf("State(%q) = %+v, %v, want category %q, %v", tt.name, state, ok, tt.wantCategory, tt.wantOK)
+		}
+	}
+}
+
+func TestWorkflowCanTransition(t *testing.T) {
+	workflow := reviewWorkflow()
+
+	tests := []struct {
+		from, to TadaStatus
+		want     bool
+	}{
+		{"todo", "doing", true},
+		{"doing", "review", true},
+		{"review", "doing", true},
+		{"review", "done", true},
+		{"todo", "wont_do", true},
+		{"todo", "todo", true},
+		{"done", "done", true},
+		{"doing", "todo", false},
+		{"todo", "done", false},
+		{"todo", "review", false},
+		{"done", "todo", false},
+		{"wont_do", "todo", false},
+	}
+
+	for _, tt := range tests {
+		if got := workflow.CanTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransition(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+
+	// Without transitions, any state can be reached from any other.
+	workflow.Transitions = nil
+	for _, tt := range tests {
+		if !workflow.CanTransition(tt.from, tt.to) {
+			t.Errorf("CanTransition(%q, %q) = false without transitions, want true", tt.from, tt.to)
+		}
+	}
+}
+
+func TestWorkflowCompletionFrom(t *testing.T) {
+	workflow := reviewWorkflow()
+
+	tests := []struct {
+		from   TadaStatus
+		want   TadaStatus
+		wantOK bool
+	}{
+		{"review", "done", true},
+		{"done", "done", true},
+		{"todo", "", false},
+		{"doing", "", false},
+	}
+
+	for _, tt := range tests {
+		state, ok := workflow.CompletionFrom(tt.from)
+		if ok != tt.wantOK || state.Name != tt.want {
+			t.Errorf("CompletionFrom(%q) = %q, %v, want %q, %v", tt.from, state.Name, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	if state, ok := DefaultWorkflow.CompletionFrom(StatusInProgress); !ok || state.Name != StatusCompleted {
+		t.Errorf("DefaultWorkflow.CompletionFrom(%q) = %q, %v, want %q", StatusInProgress, state.Name, ok, StatusCompleted)
+	}
+}
+
+func TestWorkflowValueScan(t *testing.T) {
+	value, err := Workflow{}.Value()
+	if err != nil || value != nil {
+		t.Errorf("Value() of the default = %v, %v, want NULL", value, err)
+	}
+
+	value, err = reviewWorkflow().Value()
+	if err != nil {
+		t.Fatalf("Value() error = %v", err)
+	}
+
+	var scanned Workflow
+	if err := scanned.Scan(value); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if scanned.Initial != "todo" || len(scanned.States) != 5 || len(scanned.Transitions) != 5 {
+		t.Errorf("Scan() = %+v, want the workflow stored", scanned)
+	}
+
+	if err := scanned.Scan(nil); err != nil || !scanned.IsDefault() {
+		t.Errorf("Scan(nil) = %+v, %v, want the default", scanned, err)
+	}
+}
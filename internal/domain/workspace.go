@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WorkspaceRole is what a member may do in a workspace.
+type WorkspaceRole string
+
+const (
+	WorkspaceRoleGuest  WorkspaceRole = "guest"
+	WorkspaceRoleMember WorkspaceRole = "member"
+	WorkspaceRoleAdmin  WorkspaceRole = "admin"
+	WorkspaceRoleOwner  WorkspaceRole = "owner"
+)
+
+// WorkspaceRoles lists the roles from least to most privileged.
+var WorkspaceRoles = []WorkspaceRole{WorkspaceRoleGuest, WorkspaceRoleMember, WorkspaceRoleAdmin, WorkspaceRoleOwner}
+
+// Rank orders roles by privilege, from 0 for guest upwards.
+func (r WorkspaceRole) Rank() int {
+	for i, role := range WorkspaceRoles {
+		if role == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// AtLeast reports whether r grants everything min does.
+func (r WorkspaceRole) AtLeast(min WorkspaceRole) bool {
+	return r.Rank() >= min.Rank()
+}
+
+// Workspace is a team's space. Its tadas and projects are only visible to
+// its members.
+type Workspace struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	Name      string         `gorm:"size:255;not null" json:"name"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (w *Workspace) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
+
+func (Workspace) TableName() string {
+	return "workspaces"
+}
+
+// WorkspaceMember gives a user a role in a workspace. Each workspace has
+// exactly one owner.
+type WorkspaceMember struct {
+	ID          uuid.UUID     `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	WorkspaceID uuid.UUID     `gorm:"type:uuid;not null;uniqueIndex:idx_workspace_members_workspace_user" json:"workspace_id"`
+	UserID      uuid.UUID     `gorm:"type:uuid;not null;uniqueIndex:idx_workspace_members_workspace_user;index" json:"user_id"`
+	Role        WorkspaceRole `gorm:"type:varchar(10);not null" json:"role"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+
+	// Relationships
+	Workspace Workspace `gorm:"foreignKey:WorkspaceID;references:ID" json:"workspace,omitempty"`
+	User      User      `gorm:"foreignKey:UserID;references:ID" json:"user,omitempty"`
+}
+
+func (m *WorkspaceMember) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
+
+func (WorkspaceMember) TableName() string {
+	return "workspace_members"
+}
+
+// WorkspaceInvitation asks whoever owns Email to join a workspace with
+// Role. Only the hash of the emailed token is stored.
+type WorkspaceInvitation struct {
+	ID          uuid.UUID     `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	WorkspaceID uuid.UUID     `gorm:"type:uuid;not null;index" json:"workspace_id"`
+	Email       string        `gorm:"size:255;not null" json:"email"`
+	Role        WorkspaceRole `gorm:"type:varchar(10);not null" json:"role"`
+	TokenHash   string        `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	InvitedBy   uuid.UUID     `gorm:"type:uuid;not null" json:"invited_by"`
+	ExpiresAt   time.Time     `gorm:"not null" json:"expires_at"`
+	AcceptedAt  *time.Time    `json:"accepted_at"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+func (i *WorkspaceInvitation) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// Pending reports whether the invitation can still be accepted at the
+// given time.
+func (i *WorkspaceInvitation) Pending(now time.Time) bool {
+	return i.AcceptedAt == nil && now.Before(i.ExpiresAt)
+}
+
+func (WorkspaceInvitation) TableName() string {
+	return "workspace_invitations"
+}
@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kanutocd/tada/internal/domain"
+)
+
+// AttachmentResponse describes an attachment. Checksum is the hex-encoded
+// SHA-256 of its contents.
+type AttachmentResponse struct {
+	ID          uuid.UUID     `json:"id"`
+	TadaID      uuid.UUID     `json:"tada_id"`
+	Filename    string        `json:"filename"`
+	ContentType string        `json:"content_type"`
+	Size        int64         `json:"size"`
+	Checksum    string        `json:"checksum"`
+	UploadedBy  uuid.UUID     `json:"uploaded_by"`
+	Uploader    *UserResponse `json:"uploader,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+func ToAttachmentResponse(attachment *domain.Attachment) *AttachmentResponse {
+	response := &AttachmentResponse{
+		ID:          attachment.ID,
+		TadaID:      attachment.TadaID,
+		Filename:    attachment.Filename,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		Checksum:    attachment.Checksum,
+		UploadedBy:  attachment.UploadedBy,
+		CreatedAt:   attachment.CreatedAt,
+	}
+
+	if attachment.Uploader.ID != uuid.Nil {
+		response.Uploader = ToUserResponse(&attachment.Uploader)
+	}
+
+	return response
+}
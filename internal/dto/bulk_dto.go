@@ -0,0 +1,79 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+)
+
+// Bulk request modes. An all-or-nothing request is applied in full or not
+// at all; a per-item request keeps every operation that succeeds.
+const (
+	BulkAllOrNothing = "all_or_nothing"
+	BulkPerItem      = "per_item"
+)
+
+// Operations of a bulk request.
+const (
+	BulkCreate = "create"
+	BulkUpdate = "update"
+	BulkDelete = "delete"
+)
+
+// BulkTadaRequest changes many tadas in one transaction, either through a
+// list of Operations or by applying Patch to every tada matching Filter.
+// Mode defaults to all-or-nothing.
+type BulkTadaRequest struct {
+	Mode       string              `json:"mode" binding:"omitempty,oneof=all_or_nothing per_item"`
+	Operations []BulkTadaOperation `json:"operations,omitempty" binding:"omitempty,dive"`
+	Filter     *TadaFilter         `json:"filter,omitempty"`
+	Patch      *PatchTadaRequest   `json:"patch,omitempty" swaggertype:"object"`
+	Cascade    bool                `json:"cascade,omitempty"`
+}
+
+// BulkTadaOperation is one operation of a bulk request. A create carries
+// Tada; an update carries ID and a merge Patch; a delete carries ID.
+// Version, when set, works as If-Match does for a single tada. Cascade
+// completes open subtasks along with a tada an update completes.
+type BulkTadaOperation struct {
+	Op      string             `json:"op" binding:"required,oneof=create update delete"`
+	ID      *uuid.UUID         `json:"id,omitempty"`
+	Version *int               `json:"version,omitempty" binding:"omitempty,min=1"`
+	Tada    *CreateTadaRequest `json:"tada,omitempty"`
+	Patch   *PatchTadaRequest  `json:"patch,omitempty" swaggertype:"object"`
+	Cascade bool               `json:"cascade,omitempty"`
+}
+
+// IfMatch returns the precondition the operation's Version sets.
+func (o BulkTadaOperation) IfMatch() IfMatch {
+	if o.Version == nil {
+		return IfMatch{}
+	}
+	return IfMatch{Conditional: true, Versions: []int{*o.Version}}
+}
+
+// BulkTadaResponse reports the outcome of a bulk request. Committed is
+// false when an all-or-nothing request was rolled back; Results then
+// holds only the operation that failed.
+type BulkTadaResponse struct {
+	Mode      string           `json:"mode"`
+	Committed bool             `json:"committed"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkTadaResult `json:"results"`
+}
+
+// BulkTadaResult is the outcome of one operation, at Index in the request
+// or in the tadas the filter matched. Status is the HTTP status the
+// operation would have had on its own, and Tada the created or updated
+// tada.
+type BulkTadaResult struct {
+	Index  int           `json:"index"`
+	Op     string        `json:"op"`
+	ID     *uuid.UUID    `json:"id,omitempty"`
+	Status int           `json:"status"`
+	Tada   *TadaResponse `json:"tada,omitempty"`
+	Error  string        `json:"error,omitempty"`
+
+	// Err is why the operation failed, for the handler to turn into
+	// Status and Error.
+	Err error `json:"-" swaggerignore:"true"`
+}
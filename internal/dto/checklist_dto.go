@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kanutocd/tada/internal/domain"
+)
+
+// CreateChecklistItemRequest adds an item to the end of a checklist.
+type CreateChecklistItemRequest struct {
+	Text string `json:"text" binding:"required,min=1,max=500"`
+}
+
+// UpdateChecklistItemRequest changes only the fields that are present.
+type UpdateChecklistItemRequest struct {
+	Text    *string `json:"text,omitempty" binding:"omitempty,min=1,max=500"`
+	Checked *bool   `json:"checked,omitempty"`
+}
+
+// ReorderChecklistRequest lists every item of a checklist in its new order.
+// It is rejected if the items have changed since the client last read them.
+type ReorderChecklistRequest struct {
+	ItemIDs []uuid.UUID `json:"item_ids" binding:"required"`
+}
+
+type ChecklistItemResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	TadaID    uuid.UUID  `json:"tada_id"`
+	Text      string     `json:"text"`
+	Checked   bool       `json:"checked"`
+	Position  int        `json:"position"`
+	CheckedBy *uuid.UUID `json:"checked_by,omitempty"`
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func ToChecklistItemResponse(item *domain.ChecklistItem) *ChecklistItemResponse {
+	return &ChecklistItemResponse{
+		ID:        item.ID,
+		TadaID:    item.TadaID,
+		Text:      item.Text,
+		Checked:   item.Checked,
+		Position:  item.Position,
+		CheckedBy: item.CheckedBy,
+		CheckedAt: item.CheckedAt,
+		CreatedAt: item.CreatedAt,
+		UpdatedAt: item.UpdatedAt,
+	}
+}
+
+func ToChecklistItemResponses(items []domain.ChecklistItem) []ChecklistItemResponse {
+	responses := make([]ChecklistItemResponse, len(items))
+	for i, item := range items {
+		responses[i] = *ToChecklistItemResponse(&item)
+	}
+	return responses
+}
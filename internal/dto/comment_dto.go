@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kanutocd/tada/internal/domain"
+)
+
+// CreateCommentRequest posts a comment. Members of the workspace are
+// mentioned by writing "@" followed by their email, as in
+// "@ana@example.com".
+type CreateCommentRequest struct {
+	Body string `json:"body" binding:"required,min=1,max=10000"`
+}
+
+type UpdateCommentRequest struct {
+	Body string `json:"body" binding:"required,min=1,max=10000"`
+}
+
+type CommentResponse struct {
+	ID        uuid.UUID     `json:"id"`
+	TadaID    uuid.UUID     `json:"tada_id"`
+	AuthorID  uuid.UUID     `json:"author_id"`
+	Body      string        `json:"body"`
+	Mentions  []uuid.UUID   `json:"mentions"`
+	Author    *UserResponse `json:"author,omitempty"`
+	EditedAt  *time.Time    `json:"edited_at,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+func ToCommentResponse(comment *domain.Comment) *CommentResponse {
+	response := &CommentResponse{
+		ID:        comment.ID,
+		TadaID:    comment.TadaID,
+		AuthorID:  comment.AuthorID,
+		Body:      comment.Body,
+		Mentions:  comment.Mentions,
+		EditedAt:  comment.EditedAt,
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+	}
+
+	if response.Mentions == nil {
+		response.Mentions = []uuid.UUID{}
+	}
+
+	if comment.Author.ID != uuid.Nil {
+		response.Author = ToUserResponse(&comment.Author)
+	}
+
+	return response
+}
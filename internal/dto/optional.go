@@ -0,0 +1,91 @@
+package dto
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Optional is a field of a partial update, which can be absent, null or
+// set to a value. Absent leaves the field alone and null clears it, where
+// the field can be cleared. It decodes from JSON, where a missing key is
+// absent and a literal null is null.
+type Optional[T any] struct {
+	Present bool
+	Null    bool
+	Value   T
+}
+
+// Some returns an Optional set to value.
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{Present: true, Value: value}
+}
+
+// Null returns an Optional that clears its field.
+func Null[T any]() Optional[T] {
+	return Optional[T]{Present: true, Null: true}
+}
+
+// OptionalFromPtr returns an Optional set to *value, or an absent one if
+// value is nil.
+func OptionalFromPtr[T any](value *T) Optional[T] {
+	if value == nil {
+		return Optional[T]{}
+	}
+	return Some(*value)
+}
+
+// IsSet reports whether the Optional holds a value.
+func (o Optional[T]) IsSet() bool {
+	return o.Present && !o.Null
+}
+
+// Ptr returns a pointer to the value, or nil if there is none.
+func (o Optional[T]) Ptr() *T {
+	if !o.IsSet() {
+		return nil
+	}
+	value := o.Value
+	return &value
+}
+
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	o.Present = true
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		o.Null = true
+		var zero T
+		o.Value = zero
+		return nil
+	}
+	o.Null = false
+	return json.Unmarshal(data, &o.Value)
+}
+
+// nullIfZero turns an Optional set to the zero value into a null one.
+func nullIfZero[T comparable](o Optional[T]) Optional[T] {
+	var zero T
+	if o.IsSet() && o.Value == zero {
+		return Null[T]()
+	}
+	return o
+}
+
+func invalidField(field, reason string) error {
+	return fmt.Errorf("%w: %s %s", ErrInvalidPatch, field, reason)
+}
+
+// requireLength checks that an Optional string, if present, is not null
+// and is between min and max characters long.
+func requireLength[T ~string](field string, o Optional[T], min, max int) error {
+	if !o.Present {
+		return nil
+	}
+	if o.Null {
+		return invalidField(field, "cannot be cleared")
+	}
+	if n := utf8.RuneCountInString(string(o.Value)); n < min || n > max {
+		return invalidField(field, fmt.Sprintf("must be %d to %d characters long", min, max))
+	}
+	return nil
+}
@@ -3,9 +3,15 @@ package dto
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"time"
 )
 
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidSort   = errors.New("invalid sort")
+)
+
 type PaginationQuery struct {
 	Cursor string `form:"cursor" json:"cursor,omitempty"`
 	Limit  int    `form:"limit" json:"limit,omitempty" binding:"min=1,max=100"`
@@ -25,6 +31,12 @@ type PaginationMeta struct {
 type Cursor struct {
 	ID        string    `json:"id"`
 	CreatedAt time.Time `json:"created_at"`
+	// Sort and Value record the ordering the cursor was issued for and the
+	// last row's value of the sort column. They are empty for the default
+	// created_at ordering; a nil Value under a nullable sort column means the
+	// last row had no value for it.
+	Sort  string  `json:"sort,omitempty"`
+	Value *string `json:"value,omitempty"`
 }
 
 func EncodeCursor(cursor Cursor) string {
@@ -0,0 +1,348 @@
+package dto
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Media types a PATCH request body can have. A body sent as plain
+// application/json is taken to be a merge patch.
+const (
+	MergePatchMediaType = "application/merge-patch+json"
+	JSONPatchMediaType  = "application/json-patch+json"
+)
+
+var (
+	ErrUnsupportedPatch = errors.New("unsupported patch media type")
+	ErrInvalidPatch     = errors.New("invalid patch")
+	ErrPatchConflict    = errors.New("patch does not apply to the current document")
+)
+
+// Patch is the body of a PATCH request, in the format its media type names.
+// A JSON merge patch (RFC 7396) is an object of the fields to change, where
+// null clears a field. A JSON Patch (RFC 6902) is a list of operations
+// applied in order to the resource's patch document, the object of its
+// fields that can be changed; a failed test operation rejects the whole
+// patch.
+type Patch struct {
+	MediaType string
+	Body      []byte
+}
+
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Decode turns the patch into a partial update of a resource whose patch
+// document is current, and stores it in req, a pointer to a request made
+// of Optional fields named like the document's. Fields the request does
+// not have are rejected.
+func (p Patch) Decode(current map[string]interface{}, req interface{}) error {
+	var merge []byte
+	switch p.MediaType {
+	case MergePatchMediaType, "application/json":
+		merge = p.Body
+	case JSONPatchMediaType:
+		var err error
+		merge, err = jsonPatchToMergePatch(current, p.Body)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedPatch, p.MediaType)
+	}
+
+	trimmed := bytes.TrimSpace(merge)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return fmt.Errorf("%w: a merge patch must be a JSON object", ErrInvalidPatch)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(req); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	return nil
+}
+
+// jsonPatchToMergePatch applies a JSON Patch to a copy of current and
+// returns the merge patch that takes current to the result.
+func jsonPatchToMergePatch(current map[string]interface{}, body []byte) ([]byte, error) {
+	var operations []jsonPatchOperation
+	if err := json.Unmarshal(body, &operations); err != nil {
+		return nil, fmt.Errorf("%w: a JSON Patch must be an array of operations: %v", ErrInvalidPatch, err)
+	}
+
+	// Round-trip the document so that it holds plain JSON values, which
+	// compare equal to those decoded from the patch.
+	data, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+	var before, after map[string]interface{}
+	if err := json.Unmarshal(data, &before); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &after); err != nil {
+		return nil, err
+	}
+
+	var doc interface{} = after
+	for i, operation := range operations {
+		doc, err = applyJSONPatchOperation(doc, operation)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+
+	after, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: the patched document must be an object", ErrInvalidPatch)
+	}
+
+	merge := make(map[string]interface{})
+	for key, value := range after {
+		if previous, ok := before[key]; !ok || !reflect.DeepEqual(previous, value) {
+			merge[key] = value
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			merge[key] = nil
+		}
+	}
+	return json.Marshal(merge)
+}
+
+func applyJSONPatchOperation(doc interface{}, operation jsonPatchOperation) (interface{}, error) {
+	if operation.Path == nil {
+		return nil, fmt.Errorf("%w: %q needs a path", ErrInvalidPatch, operation.Op)
+	}
+	path, err := parseJSONPointer(*operation.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var from []string
+	switch operation.Op {
+	case "move", "copy":
+		if operation.From == nil {
+			return nil, fmt.Errorf("%w: %q needs from", ErrInvalidPatch, operation.Op)
+		}
+		if from, err = parseJSONPointer(*operation.From); err != nil {
+			return nil, err
+		}
+	}
+
+	var value interface{}
+	switch operation.Op {
+	case "add", "replace", "test":
+		if operation.Value == nil {
+			return nil, fmt.Errorf("%w: %q needs a value", ErrInvalidPatch, operation.Op)
+		}
+		if err := json.Unmarshal(operation.Value, &value); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+	}
+
+	switch operation.Op {
+	case "add":
+		return jsonPointerAdd(doc, path, value)
+	case "remove":
+		doc, _, err = jsonPointerRemove(doc, path)
+		return doc, err
+	case "replace":
+		if len(path) == 0 {
+			return value, nil
+		}
+		if doc, _, err = jsonPointerRemove(doc, path); err != nil {
+			return nil, err
+		}
+		return jsonPointerAdd(doc, path, value)
+	case "move":
+		if len(path) > len(from) && reflect.DeepEqual(path[:len(from)], from) {
+			return nil, fmt.Errorf("%w: cannot move %s into itself", ErrInvalidPatch, *operation.From)
+		}
+		doc, moved, err := jsonPointerRemove(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return jsonPointerAdd(doc, path, moved)
+	case "copy":
+		copied, err := jsonPointerGet(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(copied)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &copied); err != nil {
+			return nil, err
+		}
+		return jsonPointerAdd(doc, path, copied)
+	case "test":
+		actual, err := jsonPointerGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(actual, value) {
+			return nil, fmt.Errorf("%w: test of %s failed", ErrPatchConflict, *operation.Path)
+		}
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown op %q", ErrInvalidPatch, operation.Op)
+	}
+}
+
+// parseJSONPointer splits a JSON Pointer (RFC 6901) into its unescaped
+// reference tokens. The empty pointer, which names the whole document, has
+// none.
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: pointer %q must start with /", ErrInvalidPatch, pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// jsonArrayIndex parses a reference token into an index of array. With
+// end set, "-" and len(array) name the position after the last element.
+func jsonArrayIndex(array []interface{}, token string, end bool) (int, error) {
+	if end && token == "-" {
+		return len(array), nil
+	}
+
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("%w: %q is not an array index", ErrInvalidPatch, token)
+	}
+	if index > len(array) || (index == len(array) && !end) {
+		return 0, fmt.Errorf("%w: index %d is out of range", ErrPatchConflict, index)
+	}
+	return index, nil
+}
+
+func jsonPointerGet(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: %q does not exist", ErrPatchConflict, token)
+			}
+			doc = value
+		case []interface{}:
+			index, err := jsonArrayIndex(node, token, false)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[index]
+		default:
+			return nil, fmt.Errorf("%w: %q does not exist", ErrPatchConflict, token)
+		}
+	}
+	return doc, nil
+}
+
+// jsonPointerAdd adds value at path and returns the updated document.
+func jsonPointerAdd(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	token, rest := path[0], path[1:]
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		if len(rest) == 0 {
+			node[token] = value
+			return node, nil
+		}
+		child, ok := node[token]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q does not exist", ErrPatchConflict, token)
+		}
+		child, err := jsonPointerAdd(child, rest, value)
+		if err != nil {
+			return nil, err
+		}
+		node[token] = child
+		return node, nil
+	case []interface{}:
+		index, err := jsonArrayIndex(node, token, len(rest) == 0)
+		if err != nil {
+			return nil, err
+		}
+		if len(rest) == 0 {
+			node = append(node, nil)
+			copy(node[index+1:], node[index:])
+			node[index] = value
+			return node, nil
+		}
+		if node[index], err = jsonPointerAdd(node[index], rest, value); err != nil {
+			return nil, err
+		}
+		return node, nil
+	default:
+		return nil, fmt.Errorf("%w: %q does not exist", ErrPatchConflict, token)
+	}
+}
+
+// jsonPointerRemove removes the value at path and returns the updated
+// document along with the value removed.
+func jsonPointerRemove(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("%w: cannot remove the whole document", ErrInvalidPatch)
+	}
+
+	token, rest := path[0], path[1:]
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: %q does not exist", ErrPatchConflict, token)
+		}
+		if len(rest) == 0 {
+			delete(node, token)
+			return node, child, nil
+		}
+		child, removed, err := jsonPointerRemove(child, rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		node[token] = child
+		return node, removed, nil
+	case []interface{}:
+		index, err := jsonArrayIndex(node, token, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(rest) == 0 {
+			removed := node[index]
+			return append(node[:index], node[index+1:]...), removed, nil
+		}
+		child, removed, err := jsonPointerRemove(node[index], rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		node[index] = child
+		return node, removed, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %q does not exist", ErrPatchConflict, token)
+	}
+}
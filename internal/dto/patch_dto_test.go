@@ -0,0 +1,272 @@
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// patchDocument is the patch document JSON Patch tests start from. Its
+// keys include the characters JSON Pointer escapes.
+func patchDocument() map[string]interface{} {
+	return map[string]interface{}{
+		"name":             "Write report",
+		"description":      "Numbers for the board",
+		"estimate_minutes": 30,
+		"tags":             []string{"a", "b"},
+		"a/b":              1,
+		"m~n":              2,
+	}
+}
+
+func TestJSONPatchToMergePatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   string
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "~1 unescapes to /",
+			patch: `[{"op": "replace", "path": "/a~1b", "value": 5}]`,
+			want:  `{"a/b": 5}`,
+		},
+		{
+			name:  "~0 unescapes to ~",
+			patch: `[{"op": "replace", "path": "/m~0n", "value": 3}]`,
+			want:  `{"m~n": 3}`,
+		},
+		{
+			name:  "~01 unescapes to ~1, not /",
+			patch: `[{"op": "add", "path": "/~01", "value": true}]`,
+			want:  `{"~1": true}`,
+		},
+		{
+			name:    "pointer without a leading /",
+			patch:   `[{"op": "remove", "path": "name"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:  "- appends to an array",
+			patch: `[{"op": "add", "path": "/tags/-", "value": "c"}]`,
+			want:  `{"tags": ["a", "b", "c"]}`,
+		},
+		{
+			name:  "index inserts into an array",
+			patch: `[{"op": "add", "path": "/tags/0", "value": "z"}]`,
+			want:  `{"tags": ["z", "a", "b"]}`,
+		},
+		{
+			name:  "add at the array length appends",
+			patch: `[{"op": "add", "path": "/tags/2", "value": "c"}]`,
+			want:  `{"tags": ["a", "b", "c"]}`,
+		},
+		{
+			name:    "add past the array length",
+			patch:   `[{"op": "add", "path": "/tags/3", "value": "c"}]`,
+			wantErr: ErrPatchConflict,
+		},
+		{
+			name:    "- names no element to remove",
+			patch:   `[{"op": "remove", "path": "/tags/-"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "index with a leading zero",
+			patch:   `[{"op": "remove", "path": "/tags/01"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:  "remove clears a field",
+			patch: `[{"op": "remove", "path": "/description"}]`,
+			want:  `{"description": null}`,
+		},
+		{
+			name:    "replace a missing field",
+			patch:   `[{"op": "replace", "path": "/due_at", "value": "2026-01-01T00:00:00Z"}]`,
+			wantErr: ErrPatchConflict,
+		},
+		{
+			name:  "move to another field",
+			patch: `[{"op": "move", "from": "/description", "path": "/name"}]`,
+			want:  `{"name": "Numbers for the board", "description": null}`,
+		},
+		{
+			name:  "move onto itself",
+			patch: `[{"op": "move", "from": "/name", "path": "/name"}]`,
+			want:  `{}`,
+		},
+		{
+			name:    "move into its own child",
+			patch:   `[{"op": "move", "from": "/tags", "path": "/tags/0"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:  "copy leaves the source alone",
+			patch: `[{"op": "copy", "from": "/tags", "path": "/labels"}, {"op": "add", "path": "/labels/-", "value": "c"}]`,
+			want:  `{"labels": ["a", "b", "c"]}`,
+		},
+		{
+			name:    "copy from a missing field",
+			patch:   `[{"op": "copy", "from": "/labels", "path": "/tags"}]`,
+			wantErr: ErrPatchConflict,
+		},
+		{
+			name:  "passing test lets the patch apply",
+			patch: `[{"op": "test", "path": "/name", "value": "Write report"}, {"op": "replace", "path": "/name", "value": "Edit report"}]`,
+			want:  `{"name": "Edit report"}`,
+		},
+		{
+			name:  "test compares numbers by value",
+			patch: `[{"op": "test", "path": "/estimate_minutes", "value": 30.0}]`,
+			want:  `{}`,
+		},
+		{
+			name:  "test sees earlier operations",
+			patch: `[{"op": "add", "path": "/tags/-", "value": "c"}, {"op": "test", "path": "/tags", "value": ["a", "b", "c"]}]`,
+			want:  `{"tags": ["a", "b", "c"]}`,
+		},
+		{
+			name:    "failed test rejects the whole patch",
+			patch:   `[{"op": "replace", "path": "/name", "value": "Edit report"}, {"op": "test", "path": "/name", "value": "Write report"}]`,
+			wantErr: ErrPatchConflict,
+		},
+		{
+			name:    "test of a missing field",
+			patch:   `[{"op": "test", "path": "/due_at", "value": null}]`,
+			wantErr: ErrPatchConflict,
+		},
+		{
+			name:    "operation without a value",
+			patch:   `[{"op": "add", "path": "/name"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "unknown operation",
+			patch:   `[{"op": "increment", "path": "/estimate_minutes", "value": 1}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "replacing the document with a non-object",
+			patch:   `[{"op": "replace", "path": "", "value": []}]`,
+			wantErr: ErrInvalidPatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merge, err := jsonPatchToMergePatch(patchDocument(), []byte(tt.patch))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("jsonPatchToMergePatch() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("jsonPatchToMergePatch() error = %v", err)
+			}
+
+			var got, want map[string]interface{}
+			if err := json.Unmarshal(merge, &got); err != nil {
+				t.Fatalf("merge patch %s is not an object: %v", merge, err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatalf("bad want %s: %v", tt.want, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("jsonPatchToMergePatch() = %s, want %s", merge, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatchDecode(t *testing.T) {
+	tests := []struct {
+		name            string
+		patch           Patch
+		wantName        Optional[string]
+		wantDescription Optional[string]
+		wantErr         error
+	}{
+		{
+			name:            "merge patch null clears a field",
+			patch:           Patch{MediaType: MergePatchMediaType, Body: []byte(`{"description": null}`)},
+			wantDescription: Null[string](),
+		},
+		{
+			name:            "merge patch sets a field",
+			patch:           Patch{MediaType: MergePatchMediaType, Body: []byte(`{"description": "Slides too"}`)},
+			wantDescription: Some("Slides too"),
+		},
+		{
+			name:  "missing key leaves a field alone",
+			patch: Patch{MediaType: MergePatchMediaType, Body: []byte(`{}`)},
+		},
+		{
+			name:     "plain JSON is a merge patch",
+			patch:    Patch{MediaType: "application/json", Body: []byte(`{"name": "Edit report"}`)},
+			wantName: Some("Edit report"),
+		},
+		{
+			name:            "JSON Patch remove clears a field",
+			patch:           Patch{MediaType: JSONPatchMediaType, Body: []byte(`[{"op": "remove", "path": "/description"}]`)},
+			wantDescription: Null[string](),
+		},
+		{
+			name: "JSON Patch replace with the current value changes nothing",
+			patch: Patch{
+				MediaType: JSONPatchMediaType,
+				Body:      []byte(`[{"op": "replace", "path": "/description", "value": "Numbers for the board"}]`),
+			},
+		},
+		{
+			name:    "merge patch that is not an object",
+			patch:   Patch{MediaType: MergePatchMediaType, Body: []byte(`["name"]`)},
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "field the request does not have",
+			patch:   Patch{MediaType: MergePatchMediaType, Body: []byte(`{"version": 3}`)},
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "JSON Patch that is not a list",
+			patch:   Patch{MediaType: JSONPatchMediaType, Body: []byte(`{"op": "remove", "path": "/description"}`)},
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "unsupported media type",
+			patch:   Patch{MediaType: "text/plain", Body: []byte(`{}`)},
+			wantErr: ErrUnsupportedPatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := map[string]interface{}{"name": "Write report", "description": "Numbers for the board"}
+
+			var req PatchTadaRequest
+			err := tt.patch.Decode(current, &req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Decode() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+
+			if req.Name != tt.wantName {
+				t.Errorf("Name = %+v, want %+v", req.Name, tt.wantName)
+			}
+			if req.Description != tt.wantDescription {
+				t.Errorf("Description = %+v, want %+v", req.Description, tt.wantDescription)
+			}
+			if req.DueAt.Present || req.Tags.Present {
+				t.Errorf("fields the patch does not name are present: %+v", req)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package dto
+
+// IfMatch holds the versions named by an If-Match header. A request that
+// names none, because it has no If-Match header or sends "*", leaves
+// Conditional unset and matches any version. Otherwise only the listed
+// versions match, and a conditional request whose ETags were all weak or
+// unrecognisable matches none.
+type IfMatch struct {
+	Conditional bool
+	Versions    []int
+}
+
+// Matches reports whether a resource at version satisfies the header.
+func (m IfMatch) Matches(version int) bool {
+	if !m.Conditional {
+		return true
+	}
+	for _, v := range m.Versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kanutocd/tada/internal/domain"
+)
+
+// CreateProjectRequest creates a project. Without a Workflow, its tadas
+// use the default in_progress, completed and cancelled states.
+type CreateProjectRequest struct {
+	Name        string           `json:"name" binding:"required,min=1,max=255"`
+	Description string           `json:"description"`
+	Workflow    *domain.Workflow `json:"workflow,omitempty"`
+}
+
+// UpdateProjectRequest changes only the fields that are present. A
+// Workflow replaces the project's workflow, and one with no states goes
+// back to the default. Every status the project's tadas are in must still
+// be a state of the new workflow.
+type UpdateProjectRequest struct {
+	Name        *string          `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	Description *string          `json:"description,omitempty"`
+	Archived    *bool            `json:"archived,omitempty"`
+	Workflow    *domain.Workflow `json:"workflow,omitempty"`
+}
+
+// ProjectFilter selects archived projects, or by default those in use.
+type ProjectFilter struct {
+	Archived bool `form:"archived"`
+}
+
+type ProjectResponse struct {
+	ID          uuid.UUID       `json:"id"`
+	WorkspaceID uuid.UUID       `json:"workspace_id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	OwnerID     uuid.UUID       `json:"owner_id"`
+	Archived    bool            `json:"archived"`
+	Workflow    domain.Workflow `json:"workflow"`
+	Owner       *UserResponse   `json:"owner,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// ProjectStatsResponse counts a project's tadas by status category.
+// Overdue tadas are open and past due, and are included in Open.
+type ProjectStatsResponse struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	Total     int       `json:"total"`
+	Open      int       `json:"open"`
+	Completed int       `json:"completed"`
+	Cancelled int       `json:"cancelled"`
+	Overdue   int       `json:"overdue"`
+}
+
+func ToProjectResponse(project *domain.Project) *ProjectResponse {
+	response := &ProjectResponse{
+		ID:          project.ID,
+		WorkspaceID: project.WorkspaceID,
+		Name:        project.Name,
+		Description: project.Description,
+		OwnerID:     project.OwnerID,
+		Archived:    project.Archived,
+		Workflow:    project.Workflow.Effective(),
+		CreatedAt:   project.CreatedAt,
+		UpdatedAt:   project.UpdatedAt,
+	}
+
+	if project.Owner.ID != uuid.Nil {
+		response.Owner = ToUserResponse(&project.Owner)
+	}
+
+	return response
+}
+
+func ToProjectStatsResponse(projectID uuid.UUID, stats *domain.ProjectStats) *ProjectStatsResponse {
+	return &ProjectStatsResponse{
+		ProjectID: projectID,
+		Total:     stats.Open + stats.Completed + stats.Cancelled,
+		Open:      stats.Open,
+		Completed: stats.Completed,
+		Cancelled: stats.Cancelled,
+		Overdue:   stats.Overdue,
+	}
+}
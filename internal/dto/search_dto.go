@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"html"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kanutocd/tada/internal/domain"
+)
+
+// SearchQuery is the text to search for. It accepts web search syntax:
+// quoted phrases, "or" between alternatives, and "-" before words to
+// leave out.
+type SearchQuery struct {
+	Q string `form:"q" binding:"required,min=1,max=256"`
+}
+
+// SearchResultResponse is a tada that matches a search, either itself or
+// through one of its comments, as Kind says. ID is the matching tada or
+// comment. Snippet is HTML-escaped matching text with the matched words
+// wrapped in <mark> elements.
+type SearchResultResponse struct {
+	Kind    domain.SearchHitKind `json:"kind"`
+	ID      uuid.UUID            `json:"id"`
+	Rank    float64              `json:"rank"`
+	Snippet string               `json:"snippet"`
+	Tada    TadaResponse         `json:"tada"`
+}
+
+var snippetMarks = strings.NewReplacer(domain.HighlightStart, "<mark>", domain.HighlightEnd, "</mark>")
+
+func ToSearchResultResponse(hit *domain.SearchHit, tada *TadaResponse) *SearchResultResponse {
+	return &SearchResultResponse{
+		Kind:    hit.Kind,
+		ID:      hit.ID,
+		Rank:    hit.Rank,
+		Snippet: snippetMarks.Replace(html.EscapeString(hit.Snippet)),
+		Tada:    *tada,
+	}
+}
@@ -1,60 +1,299 @@
 package dto
 
 import (
+	"slices"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/kanutocd/tada/internal/domain"
 )
 
+// CreateTadaRequest creates a tada. Recurrence is an RRULE such as
+// "FREQ=WEEKLY;BYDAY=MO" and requires DueAt, the first occurrence. Priority
+// defaults to medium; EstimateMinutes is the expected effort. ProjectID
+// must name a project that is not archived. Status must be a state of the
+// project's workflow, and defaults to its initial state.
 type CreateTadaRequest struct {
-	Name        string             `json:"name" binding:"required,min=1,max=255"`
-	Description string             `json:"description"`
-	CreatedBy   uuid.UUID          `json:"created_by" binding:"required"`
-	AssignedTo  *uuid.UUID         `json:"assigned_to,omitempty"`
-	Status      *domain.TadaStatus `json:"status,omitempty" binding:"omitempty,oneof=in_progress cancelled completed"`
-	DueAt       *time.Time         `json:"due_at,omitempty"`
+	Name            string               `json:"name" binding:"required,min=1,max=255"`
+	Description     string               `json:"description"`
+	AssignedTo      *uuid.UUID           `json:"assigned_to,omitempty"`
+	ParentID        *uuid.UUID           `json:"parent_id,omitempty"`
+	ProjectID       *uuid.UUID           `json:"project_id,omitempty"`
+	Status          *domain.TadaStatus   `json:"status,omitempty" binding:"omitempty,min=1,max=20"`
+	Priority        *domain.TadaPriority `json:"priority,omitempty" binding:"omitempty,oneof=low medium high urgent"`
+	EstimateMinutes *int                 `json:"estimate_minutes,omitempty" binding:"omitempty,min=1,max=525600"`
+	DueAt           *time.Time           `json:"due_at,omitempty"`
+	Recurrence      *string              `json:"recurrence,omitempty"`
+	Tags            []string             `json:"tags,omitempty" binding:"omitempty,dive,min=1,max=64"`
 }
 
+// UpdateTadaRequest changes only the fields that are present. Tags, when
+// present, replaces the tada's tags; an empty array removes them all. A
+// ParentID of all zeros moves the tada to the top level, a ProjectID of all
+// zeros takes it out of its project, and an empty Recurrence stops the tada
+// from recurring. Completing a tada whose subtasks are still open is
+// rejected unless Cascade is set, in which case those subtasks are
+// completed too. An EstimateMinutes of 0 removes the estimate. Status must
+// be reachable from the current status in the project's workflow. A tada
+// moved to another project keeps its status, which must then be a state of
+// the new workflow unless Status names one.
 type UpdateTadaRequest struct {
-	Name        *string            `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
-	Description *string            `json:"description,omitempty"`
-	AssignedTo  *uuid.UUID         `json:"assigned_to,omitempty"`
-	Status      *domain.TadaStatus `json:"status,omitempty" binding:"omitempty,oneof=in_progress cancelled completed"`
-	DueAt       *time.Time         `json:"due_at,omitempty"`
+	Name            *string              `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	Description     *string              `json:"description,omitempty"`
+	AssignedTo      *uuid.UUID           `json:"assigned_to,omitempty"`
+	ParentID        *uuid.UUID           `json:"parent_id,omitempty"`
+	ProjectID       *uuid.UUID           `json:"project_id,omitempty"`
+	Status          *domain.TadaStatus   `json:"status,omitempty" binding:"omitempty,min=1,max=20"`
+	Priority        *domain.TadaPriority `json:"priority,omitempty" binding:"omitempty,oneof=low medium high urgent"`
+	EstimateMinutes *int                 `json:"estimate_minutes,omitempty" binding:"omitempty,min=0,max=525600"`
+	DueAt           *time.Time           `json:"due_at,omitempty"`
+	Recurrence      *string              `json:"recurrence,omitempty"`
+	Tags            []string             `json:"tags,omitempty" binding:"omitempty,dive,min=1,max=64"`
+	Cascade         bool                 `json:"cascade,omitempty"`
 }
 
+// Patch returns the partial update the request stands for, with the values
+// it uses to clear fields turned into nulls.
+func (r UpdateTadaRequest) Patch() PatchTadaRequest {
+	patch := PatchTadaRequest{
+		Name:            OptionalFromPtr(r.Name),
+		Description:     OptionalFromPtr(r.Description),
+		AssignedTo:      OptionalFromPtr(r.AssignedTo),
+		ParentID:        nullIfZero(OptionalFromPtr(r.ParentID)),
+		ProjectID:       nullIfZero(OptionalFromPtr(r.ProjectID)),
+		Status:          OptionalFromPtr(r.Status),
+		Priority:        OptionalFromPtr(r.Priority),
+		EstimateMinutes: nullIfZero(OptionalFromPtr(r.EstimateMinutes)),
+		DueAt:           OptionalFromPtr(r.DueAt),
+		Recurrence:      nullIfZero(OptionalFromPtr(r.Recurrence)),
+	}
+	if r.Tags != nil {
+		patch.Tags = Some(r.Tags)
+	}
+	return patch
+}
+
+// PatchTadaRequest is a partial update of a tada, decoded from a PATCH
+// body. Null clears description, assigned_to, parent_id, project_id,
+// estimate_minutes, due_at, recurrence and tags; name, status and priority
+// cannot be cleared. Values follow the rules of UpdateTadaRequest, except
+// that an estimate must be positive. Clearing due_at is rejected while
+// the tada recurs.
+type PatchTadaRequest struct {
+	Name            Optional[string]              `json:"name"`
+	Description     Optional[string]              `json:"description"`
+	AssignedTo      Optional[uuid.UUID]           `json:"assigned_to"`
+	ParentID        Optional[uuid.UUID]           `json:"parent_id"`
+	ProjectID       Optional[uuid.UUID]           `json:"project_id"`
+	Status          Optional[domain.TadaStatus]   `json:"status"`
+	Priority        Optional[domain.TadaPriority] `json:"priority"`
+	EstimateMinutes Optional[int]                 `json:"estimate_minutes"`
+	DueAt           Optional[time.Time]           `json:"due_at"`
+	Recurrence      Optional[string]              `json:"recurrence"`
+	Tags            Optional[[]string]            `json:"tags"`
+}
+
+// Validate checks what binding tags check for UpdateTadaRequest.
+func (r PatchTadaRequest) Validate() error {
+	if err := requireLength("name", r.Name, 1, 255); err != nil {
+		return err
+	}
+	if err := requireLength("status", r.Status, 1, 20); err != nil {
+		return err
+	}
+	if r.Priority.Present {
+		if r.Priority.Null {
+			return invalidField("priority", "cannot be cleared")
+		}
+		if !slices.Contains(domain.Priorities, r.Priority.Value) {
+			return invalidField("priority", "must be one of low, medium, high or urgent")
+		}
+	}
+	if r.EstimateMinutes.IsSet() && (r.EstimateMinutes.Value < 1 || r.EstimateMinutes.Value > 525600) {
+		return invalidField("estimate_minutes", "must be between 1 and 525600")
+	}
+	for _, tag := range r.Tags.Value {
+		if n := utf8.RuneCountInString(tag); n < 1 || n > 64 {
+			return invalidField("tags", "must each be 1 to 64 characters long")
+		}
+	}
+	return nil
+}
+
+// TadaPatchDocument returns the fields of a tada that a patch can change,
+// which is the document a JSON Patch applies to.
+func TadaPatchDocument(tada *domain.Tada) map[string]interface{} {
+	return map[string]interface{}{
+		"name":             tada.Name,
+		"description":      tada.Description,
+		"assigned_to":      tada.AssignedTo,
+		"parent_id":        tada.ParentID,
+		"project_id":       tada.ProjectID,
+		"status":           tada.Status,
+		"priority":         tada.Priority,
+		"estimate_minutes": tada.Estimate,
+		"due_at":           tada.DueAt,
+		"recurrence":       tada.Recurrence,
+		"tags":             domain.TagNames(tada.Tags),
+	}
+}
+
+// PatchTadaQuery holds the query parameters of a tada PATCH. Cascade works
+// as in UpdateTadaRequest.
+type PatchTadaQuery struct {
+	Cascade bool `form:"cascade"`
+}
+
+// TadaFilter holds the query parameters accepted by the tada listing.
+// Sort names the column to order by (created_at, due_at, updated_at, name,
+// status or priority), ascending unless prefixed with "-"; it defaults to
+// -created_at. Priority sorts by urgency, so -priority puts urgent first.
+// Tag selects tadas carrying all of the named tags, or any of them when
+// TagMode is "any". Blocked selects tadas that are, or are not, waiting on
+// a blocker that is still open. Status selects workflow states by name and
+// StatusCategory by what they mean. SeriesID selects the tadas of a recurring
+// series, given the ID of its first tada. Tadas in archived projects are
+// left out unless IncludeArchived is set or ProjectID names the project.
+// A bulk request carries the filter as JSON, under the same names.
+type TadaFilter struct {
+	Status          []domain.TadaStatus     `form:"status" json:"status" binding:"dive,min=1,max=20"`
+	StatusCategory  []domain.StatusCategory `form:"status_category" json:"status_category" binding:"dive,oneof=open completed cancelled"`
+	CreatedBy       string                  `form:"created_by" json:"created_by" binding:"omitempty,uuid"`
+	SeriesID        string                  `form:"series_id" json:"series_id" binding:"omitempty,uuid"`
+	ProjectID       string                  `form:"project_id" json:"project_id" binding:"omitempty,uuid"`
+	IncludeArchived bool                    `form:"include_archived" json:"include_archived"`
+	AssignedTo      string                  `form:"assigned_to" json:"assigned_to" binding:"omitempty,uuid"`
+	Unassigned      *bool                   `form:"unassigned" json:"unassigned"`
+	DueBefore       *time.Time              `form:"due_before" json:"due_before"`
+	DueAfter        *time.Time              `form:"due_after" json:"due_after"`
+	CompletedBefore *time.Time              `form:"completed_before" json:"completed_before"`
+	CompletedAfter  *time.Time              `form:"completed_after" json:"completed_after"`
+	Tag             []string                `form:"tag" json:"tag"`
+	TagMode         string                  `form:"tag_mode" json:"tag_mode" binding:"omitempty,oneof=all any"`
+	Blocked         *bool                   `form:"blocked" json:"blocked"`
+	Sort            string                  `form:"sort" json:"sort"`
+}
+
+// UserTadasQuery selects which of a user's tadas to list: those they created,
+// those assigned to them, or either (the default).
+type UserTadasQuery struct {
+	Role string `form:"role" binding:"omitempty,oneof=creator assignee any"`
+}
+
+// TadaOccurrencesQuery sets how many upcoming occurrences to preview.
+type TadaOccurrencesQuery struct {
+	Count int `form:"count" binding:"omitempty,min=1,max=100"`
+}
+
+// TadaOccurrencesResponse lists the due dates of the occurrences that will
+// follow a recurring tada, in order.
+type TadaOccurrencesResponse struct {
+	Recurrence  string      `json:"recurrence"`
+	Occurrences []time.Time `json:"occurrences"`
+}
+
+// TadaTreeQuery limits how many levels of subtasks a tree includes below
+// its root.
+type TadaTreeQuery struct {
+	Depth int `form:"depth" binding:"omitempty,min=1,max=10"`
+}
+
+// StreamFilter restricts the change stream to tadas created by or assigned
+// to a user. WorkspaceID is not bound from the query; it is always the
+// caller's workspace, and the stream only carries changes from there.
+type StreamFilter struct {
+	WorkspaceID uuid.UUID `form:"-"`
+	CreatedBy   string    `form:"created_by" binding:"omitempty,uuid"`
+	AssignedTo  string    `form:"assigned_to" binding:"omitempty,uuid"`
+}
+
+// TadaResponse describes a tada. Progress is only set when it has subtasks.
+// BlockedBy lists the tadas blocking it; Blocked reports whether any of
+// them is still open. StatusCategory is what Status means in its workflow.
+// Version is the one the tada's ETag names.
 type TadaResponse struct {
-	ID          uuid.UUID         `json:"id"`
-	Name        string            `json:"name"`
-	Description string            `json:"description"`
-	CreatedBy   uuid.UUID         `json:"created_by"`
-	AssignedTo  *uuid.UUID        `json:"assigned_to,omitempty"`
-	Status      domain.TadaStatus `json:"status"`
-	DueAt       *time.Time        `json:"due_at,omitempty"`
-	CompletedAt *time.Time        `json:"completed_at,omitempty"`
-	Creator     *UserResponse     `json:"creator,omitempty"`
-	Assignee    *UserResponse     `json:"assignee,omitempty"`
-	CreatedAt   time.Time         `json:"created_at"`
-	UpdatedAt   time.Time         `json:"updated_at"`
+	ID              uuid.UUID             `json:"id"`
+	WorkspaceID     uuid.UUID             `json:"workspace_id"`
+	Name            string                `json:"name"`
+	Description     string                `json:"description"`
+	CreatedBy       uuid.UUID             `json:"created_by"`
+	AssignedTo      *uuid.UUID            `json:"assigned_to,omitempty"`
+	ParentID        *uuid.UUID            `json:"parent_id,omitempty"`
+	ProjectID       *uuid.UUID            `json:"project_id,omitempty"`
+	Status          domain.TadaStatus     `json:"status"`
+	StatusCategory  domain.StatusCategory `json:"status_category"`
+	Priority        domain.TadaPriority   `json:"priority"`
+	EstimateMinutes *int                  `json:"estimate_minutes,omitempty"`
+	DueAt           *time.Time            `json:"due_at,omitempty"`
+	CompletedAt     *time.Time            `json:"completed_at,omitempty"`
+	Recurrence      string                `json:"recurrence,omitempty"`
+	SeriesID        *uuid.UUID            `json:"series_id,omitempty"`
+	Occurrence      int                   `json:"occurrence"`
+	Tags            []string              `json:"tags"`
+	Progress        *domain.TadaProgress  `json:"progress,omitempty"`
+	Blocked         bool                  `json:"blocked"`
+	BlockedBy       []uuid.UUID           `json:"blocked_by"`
+	CommentCount    int                   `json:"comment_count"`
+	ChecklistTotal  int                   `json:"checklist_total"`
+	ChecklistDone   int                   `json:"checklist_done"`
+	Creator         *UserResponse         `json:"creator,omitempty"`
+	Assignee        *UserResponse         `json:"assignee,omitempty"`
+	Version         int                   `json:"version"`
+	CreatedAt       time.Time             `json:"created_at"`
+	UpdatedAt       time.Time             `json:"updated_at"`
+}
+
+// TadaTreeResponse is a tada with its subtasks, nested up to the requested
+// depth. Tadas at the depth limit have no Children even if their Progress
+// shows subtasks.
+type TadaTreeResponse struct {
+	TadaResponse
+	Children []TadaTreeResponse `json:"children"`
+}
+
+// CreateTadaDependencyRequest names the tada that the tada in the path
+// blocks.
+type CreateTadaDependencyRequest struct {
+	TadaID uuid.UUID `json:"tada_id" binding:"required"`
+}
+
+type TadaDependencyResponse struct {
+	BlockerID uuid.UUID  `json:"blocker_id"`
+	BlockedID uuid.UUID  `json:"blocked_id"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
 }
 
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error  string `json:"error"`
+	Reason string `json:"reason,omitempty"`
 }
 
 func ToTadaResponse(tada *domain.Tada) *TadaResponse {
 	response := &TadaResponse{
-		ID:          tada.ID,
-		Name:        tada.Name,
-		Description: tada.Description,
-		CreatedBy:   tada.CreatedBy,
-		AssignedTo:  tada.AssignedTo,
-		Status:      tada.Status,
-		DueAt:       tada.DueAt,
-		CompletedAt: tada.CompletedAt,
-		CreatedAt:   tada.CreatedAt,
-		UpdatedAt:   tada.UpdatedAt,
+		ID:              tada.ID,
+		WorkspaceID:     tada.WorkspaceID,
+		Name:            tada.Name,
+		Description:     tada.Description,
+		CreatedBy:       tada.CreatedBy,
+		AssignedTo:      tada.AssignedTo,
+		ParentID:        tada.ParentID,
+		ProjectID:       tada.ProjectID,
+		Status:          tada.Status,
+		StatusCategory:  tada.Category,
+		Priority:        tada.Priority,
+		EstimateMinutes: tada.Estimate,
+		DueAt:           tada.DueAt,
+		CompletedAt:     tada.CompletedAt,
+		Recurrence:      tada.Recurrence,
+		SeriesID:        tada.SeriesID,
+		Occurrence:      tada.Occurrence,
+		Tags:            domain.TagNames(tada.Tags),
+		BlockedBy:       []uuid.UUID{},
+		Version:         tada.Version,
+		CreatedAt:       tada.CreatedAt,
+		UpdatedAt:       tada.UpdatedAt,
 	}
 
 	if tada.Creator.ID != uuid.Nil {
@@ -67,3 +306,12 @@ func ToTadaResponse(tada *domain.Tada) *TadaResponse {
 
 	return response
 }
+
+func ToTadaDependencyResponse(dependency *domain.TadaDependency) *TadaDependencyResponse {
+	return &TadaDependencyResponse{
+		BlockerID: dependency.BlockerID,
+		BlockedID: dependency.BlockedID,
+		CreatedBy: dependency.CreatedBy,
+		CreatedAt: dependency.CreatedAt,
+	}
+}
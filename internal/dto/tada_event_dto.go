@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kanutocd/tada/internal/domain"
+)
+
+type TadaEventResponse struct {
+	ID        uuid.UUID            `json:"id"`
+	TadaID    uuid.UUID            `json:"tada_id"`
+	ActorID   *uuid.UUID           `json:"actor_id,omitempty"`
+	Type      domain.TadaEventType `json:"type"`
+	Changes   domain.FieldChanges  `json:"changes"`
+	CreatedAt time.Time            `json:"created_at"`
+}
+
+// TadaChangeEvent describes a tada lifecycle change. It is the body POSTed
+// to webhooks and the data of each event on the change stream.
+type TadaChangeEvent struct {
+	ID         uuid.UUID             `json:"id"`
+	Type       domain.TadaChangeType `json:"type"`
+	OccurredAt time.Time             `json:"occurred_at"`
+	ActorID    uuid.UUID             `json:"actor_id"`
+	Data       *TadaResponse         `json:"data"`
+}
+
+func ToTadaEventResponse(event *domain.TadaEvent) *TadaEventResponse {
+	return &TadaEventResponse{
+		ID:        event.ID,
+		TadaID:    event.TadaID,
+		ActorID:   event.ActorID,
+		Type:      event.Type,
+		Changes:   event.Changes,
+		CreatedAt: event.CreatedAt,
+	}
+}
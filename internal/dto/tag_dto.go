@@ -0,0 +1,34 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kanutocd/tada/internal/domain"
+)
+
+type CreateTagRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=64"`
+}
+
+type UpdateTagRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=64"`
+}
+
+type TagResponse struct {
+	ID          uuid.UUID `json:"id"`
+	WorkspaceID uuid.UUID `json:"workspace_id"`
+	Name        string    `json:"name"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func ToTagResponse(tag *domain.Tag) *TagResponse {
+	return &TagResponse{
+		ID:          tag.ID,
+		WorkspaceID: tag.WorkspaceID,
+		Name:        tag.Name,
+		CreatedAt:   tag.CreatedAt,
+		UpdatedAt:   tag.UpdatedAt,
+	}
+}
@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"net/mail"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,19 +9,75 @@ import (
 )
 
 type CreateUserRequest struct {
-	Name  string `json:"name" binding:"required,min=1,max=255"`
-	Email string `json:"email" binding:"required,email,max=255"`
+	Name     string `json:"name" binding:"required,min=1,max=255"`
+	Email    string `json:"email" binding:"required,email,max=255"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
 }
 
+// UpdateUserRequest changes a user's details. Changing the email or
+// password takes CurrentPassword.
 type UpdateUserRequest struct {
-	Name  *string `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
-	Email *string `json:"email,omitempty" binding:"omitempty,email,max=255"`
+	Name            *string `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	Email           *string `json:"email,omitempty" binding:"omitempty,email,max=255"`
+	Password        *string `json:"password,omitempty" binding:"omitempty,min=8,max=72"`
+	CurrentPassword *string `json:"current_password,omitempty" binding:"omitempty,max=72"`
 }
 
+// Patch returns the partial update the request stands for.
+func (r UpdateUserRequest) Patch() PatchUserRequest {
+	return PatchUserRequest{
+		Name:            OptionalFromPtr(r.Name),
+		Email:           OptionalFromPtr(r.Email),
+		Password:        OptionalFromPtr(r.Password),
+		CurrentPassword: OptionalFromPtr(r.CurrentPassword),
+	}
+}
+
+// PatchUserRequest is a partial update of a user, decoded from a PATCH
+// body. None of its fields can be cleared. Password and CurrentPassword
+// are write-only: they are not part of the user's patch document, but a
+// JSON Patch can add them.
+type PatchUserRequest struct {
+	Name            Optional[string] `json:"name"`
+	Email           Optional[string] `json:"email"`
+	Password        Optional[string] `json:"password"`
+	CurrentPassword Optional[string] `json:"current_password"`
+}
+
+// Validate checks what binding tags check for UpdateUserRequest.
+func (r PatchUserRequest) Validate() error {
+	if err := requireLength("name", r.Name, 1, 255); err != nil {
+		return err
+	}
+	if err := requireLength("email", r.Email, 3, 255); err != nil {
+		return err
+	}
+	if r.Email.Present {
+		if address, err := mail.ParseAddress(r.Email.Value); err != nil || address.Address != r.Email.Value {
+			return invalidField("email", "must be an email address")
+		}
+	}
+	if err := requireLength("password", r.Password, 8, 72); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UserPatchDocument returns the fields of a user that a patch can change,
+// which is the document a JSON Patch applies to.
+func UserPatchDocument(user *domain.User) map[string]interface{} {
+	return map[string]interface{}{
+		"name":  user.Name,
+		"email": user.Email,
+	}
+}
+
+// UserResponse describes a user. Version is the one the user's ETag names.
 type UserResponse struct {
 	ID        uuid.UUID `json:"id"`
 	Name      string    `json:"name"`
 	Email     string    `json:"email"`
+	Version   int       `json:"version"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -30,6 +87,7 @@ func ToUserResponse(user *domain.User) *UserResponse {
 		ID:        user.ID,
 		Name:      user.Name,
 		Email:     user.Email,
+		Version:   user.Version,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
 	}
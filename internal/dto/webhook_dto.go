@@ -0,0 +1,79 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kanutocd/tada/internal/domain"
+)
+
+type CreateWebhookRequest struct {
+	URL    string                  `json:"url" binding:"required,url,max=2048"`
+	Events []domain.TadaChangeType `json:"events" binding:"required,min=1"`
+	Active *bool                   `json:"active,omitempty"`
+}
+
+type UpdateWebhookRequest struct {
+	URL    *string                 `json:"url,omitempty" binding:"omitempty,url,max=2048"`
+	Events []domain.TadaChangeType `json:"events,omitempty" binding:"omitempty,min=1"`
+	Active *bool                   `json:"active,omitempty"`
+}
+
+// WebhookResponse describes a webhook. It receives the changes of tadas in
+// WorkspaceID. Secret, used to sign deliveries, is only included when the
+// webhook is created.
+type WebhookResponse struct {
+	ID          uuid.UUID                 `json:"id"`
+	WorkspaceID uuid.UUID                 `json:"workspace_id"`
+	URL         string                    `json:"url"`
+	Events      domain.TadaChangeTypeList `json:"events"`
+	Active      bool                      `json:"active"`
+	Secret      string                    `json:"secret,omitempty"`
+	CreatedAt   time.Time                 `json:"created_at"`
+	UpdatedAt   time.Time                 `json:"updated_at"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID            uuid.UUID                    `json:"id"`
+	WebhookID     uuid.UUID                    `json:"webhook_id"`
+	EventType     domain.TadaChangeType        `json:"event_type"`
+	Status        domain.WebhookDeliveryStatus `json:"status"`
+	Attempts      int                          `json:"attempts"`
+	ResponseCode  *int                         `json:"response_code,omitempty"`
+	LastError     string                       `json:"last_error,omitempty"`
+	NextAttemptAt *time.Time                   `json:"next_attempt_at,omitempty"`
+	DeliveredAt   *time.Time                   `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time                    `json:"created_at"`
+}
+
+func ToWebhookResponse(webhook *domain.Webhook) *WebhookResponse {
+	return &WebhookResponse{
+		ID:          webhook.ID,
+		WorkspaceID: webhook.WorkspaceID,
+		URL:         webhook.URL,
+		Events:      webhook.Events,
+		Active:      webhook.Active,
+		CreatedAt:   webhook.CreatedAt,
+		UpdatedAt:   webhook.UpdatedAt,
+	}
+}
+
+func ToWebhookDeliveryResponse(delivery *domain.WebhookDelivery) *WebhookDeliveryResponse {
+	response := &WebhookDeliveryResponse{
+		ID:           delivery.ID,
+		WebhookID:    delivery.WebhookID,
+		EventType:    delivery.EventType,
+		Status:       delivery.Status,
+		Attempts:     delivery.Attempts,
+		ResponseCode: delivery.ResponseCode,
+		LastError:    delivery.LastError,
+		DeliveredAt:  delivery.DeliveredAt,
+		CreatedAt:    delivery.CreatedAt,
+	}
+
+	if delivery.Status == domain.DeliveryPending {
+		response.NextAttemptAt = &delivery.NextAttemptAt
+	}
+
+	return response
+}
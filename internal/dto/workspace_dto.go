@@ -0,0 +1,103 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kanutocd/tada/internal/domain"
+)
+
+type CreateWorkspaceRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=255"`
+}
+
+type UpdateWorkspaceRequest struct {
+	Name *string `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+}
+
+// UpdateWorkspaceMemberRequest changes a member's role. Owner cannot be
+// given or taken away.
+type UpdateWorkspaceMemberRequest struct {
+	Role domain.WorkspaceRole `json:"role" binding:"required,oneof=admin member guest"`
+}
+
+// CreateWorkspaceInvitationRequest invites whoever owns Email, who need not
+// have an account yet. Role defaults to member.
+type CreateWorkspaceInvitationRequest struct {
+	Email string               `json:"email" binding:"required,email"`
+	Role  domain.WorkspaceRole `json:"role,omitempty" binding:"omitempty,oneof=admin member guest"`
+}
+
+// AcceptWorkspaceInvitationRequest carries the token from an invitation
+// email.
+type AcceptWorkspaceInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// WorkspaceResponse describes a workspace and the caller's role in it.
+type WorkspaceResponse struct {
+	ID        uuid.UUID            `json:"id"`
+	Name      string               `json:"name"`
+	Role      domain.WorkspaceRole `json:"role"`
+	CreatedAt time.Time            `json:"created_at"`
+	UpdatedAt time.Time            `json:"updated_at"`
+}
+
+type WorkspaceMemberResponse struct {
+	WorkspaceID uuid.UUID            `json:"workspace_id"`
+	UserID      uuid.UUID            `json:"user_id"`
+	Role        domain.WorkspaceRole `json:"role"`
+	User        *UserResponse        `json:"user,omitempty"`
+	CreatedAt   time.Time            `json:"created_at"`
+}
+
+// WorkspaceInvitationResponse describes an invitation. The token is only
+// ever sent to the invitee.
+type WorkspaceInvitationResponse struct {
+	ID          uuid.UUID            `json:"id"`
+	WorkspaceID uuid.UUID            `json:"workspace_id"`
+	Email       string               `json:"email"`
+	Role        domain.WorkspaceRole `json:"role"`
+	InvitedBy   uuid.UUID            `json:"invited_by"`
+	ExpiresAt   time.Time            `json:"expires_at"`
+	CreatedAt   time.Time            `json:"created_at"`
+}
+
+// ToWorkspaceResponse describes member's workspace from member's point of
+// view.
+func ToWorkspaceResponse(member *domain.WorkspaceMember) *WorkspaceResponse {
+	return &WorkspaceResponse{
+		ID:        member.Workspace.ID,
+		Name:      member.Workspace.Name,
+		Role:      member.Role,
+		CreatedAt: member.Workspace.CreatedAt,
+		UpdatedAt: member.Workspace.UpdatedAt,
+	}
+}
+
+func ToWorkspaceMemberResponse(member *domain.WorkspaceMember) *WorkspaceMemberResponse {
+	response := &WorkspaceMemberResponse{
+		WorkspaceID: member.WorkspaceID,
+		UserID:      member.UserID,
+		Role:        member.Role,
+		CreatedAt:   member.CreatedAt,
+	}
+
+	if member.User.ID != uuid.Nil {
+		response.User = ToUserResponse(&member.User)
+	}
+
+	return response
+}
+
+func ToWorkspaceInvitationResponse(invitation *domain.WorkspaceInvitation) *WorkspaceInvitationResponse {
+	return &WorkspaceInvitationResponse{
+		ID:          invitation.ID,
+		WorkspaceID: invitation.WorkspaceID,
+		Email:       invitation.Email,
+		Role:        invitation.Role,
+		InvitedBy:   invitation.InvitedBy,
+		ExpiresAt:   invitation.ExpiresAt,
+		CreatedAt:   invitation.CreatedAt,
+	}
+}
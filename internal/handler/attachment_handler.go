@@ -0,0 +1,276 @@
+package handler
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/kanutocd/tada/internal/dto"
+	"github.com/kanutocd/tada/internal/middleware"
+	"github.com/kanutocd/tada/internal/service"
+)
+
+// multipartOverhead allows for the boundaries and part headers around an
+// uploaded file when capping the size of an upload request.
+const multipartOverhead = 64 << 10
+
+type AttachmentHandler struct {
+	attachmentService service.AttachmentService
+	maxSize           int64
+}
+
+// NewAttachmentHandler returns a handler that refuses upload requests
+// carrying more than maxSize bytes of file.
+func NewAttachmentHandler(attachmentService service.AttachmentService, maxSize int64) *AttachmentHandler {
+	return &AttachmentHandler{attachmentService: attachmentService, maxSize: maxSize}
+}
+
+// attachments returns the attachment service confined to the caller's workspace.
+func (h *AttachmentHandler) attachments(c *gin.Context) service.AttachmentService {
+	return h.attachmentService.InWorkspace(middleware.CurrentMembership(c).WorkspaceID)
+}
+
+// GetAttachments godoc
+// @Summary Get a tada's attachments
+// @Description Retrieve a paginated list of the files attached to a tada, newest first
+// @Tags attachments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tada ID"
+// @Param cursor query string false "Pagination cursor"
+// @Param limit query int false "Items per page (1-100)" minimum(1) maximum(100) default(10)
+// @Success 200 {object} dto.PaginationResponse{data=[]dto.AttachmentResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /tadas/{id}/attachments [get]
+func (h *AttachmentHandler) GetAttachments(c *gin.Context) {
+	tadaID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid tada ID",
+		})
+		return
+	}
+
+	var pagination dto.PaginationQuery
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid pagination parameters",
+		})
+		return
+	}
+
+	response, err := h.attachments(c).GetAttachments(c.Request.Context(), tadaID, pagination)
+	if errors.Is(err, service.ErrTadaNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: "Tada not found",
+		})
+		return
+	}
+	if errors.Is(err, dto.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UploadAttachment godoc
+// @Summary Attach a file to a tada
+// @Description Upload a file as the "file" field of a multipart form. Size and content type are limited by the server's configuration.
+// @Tags attachments
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tada ID"
+// @Param file formData file true "File to attach"
+// @Success 201 {object} dto.AttachmentResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 415 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /tadas/{id}/attachments [post]
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	tadaID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid tada ID",
+		})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxSize+multipartOverhead)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, dto.ErrorResponse{
+				Error: service.ErrAttachmentTooLarge.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid request body",
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid request body",
+		})
+		return
+	}
+	defer file.Close()
+
+	identity := middleware.CurrentIdentity(c)
+
+	attachment, err := h.attachments(c).UploadAttachment(c.Request.Context(), identity.User, tadaID, service.AttachmentUpload{
+		Filename:    fileHeader.Filename,
+		ContentType: fileHeader.Header.Get("Content-Type"),
+		Size:        fileHeader.Size,
+		Content:     file,
+	})
+	if errors.Is(err, service.ErrTadaNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: "Tada not found",
+		})
+		return
+	}
+	if errors.Is(err, service.ErrAttachmentTooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, service.ErrContentTypeBlocked) {
+		c.JSON(http.StatusUnsupportedMediaType, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// DownloadAttachment godoc
+// @Summary Download an attachment
+// @Description Download the contents of a file attached to a tada
+// @Tags attachments
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path string true "Tada ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Success 200 {file} file
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /tadas/{id}/attachments/{attachmentId} [get]
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	tadaID, id, ok := parseAttachmentPath(c)
+	if !ok {
+		return
+	}
+
+	attachment, content, err := h.attachments(c).OpenAttachment(c.Request.Context(), tadaID, id)
+	if errors.Is(err, service.ErrTadaNotFound) || errors.Is(err, service.ErrAttachmentNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	defer content.Close()
+
+	// Always download rather than render, so an uploaded file cannot run
+	// as a page on this origin.
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// DeleteAttachment godoc
+// @Summary Delete an attachment
+// @Description Delete a file attached to a tada. Its uploader or the tada's creator may delete it.
+// @Tags attachments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tada ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /tadas/{id}/attachments/{attachmentId} [delete]
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	tadaID, id, ok := parseAttachmentPath(c)
+	if !ok {
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	err := h.attachments(c).DeleteAttachment(c.Request.Context(), identity.User, tadaID, id)
+	if respondForbidden(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseAttachmentPath reads the tada and attachment IDs from the path,
+// writing a 400 response if either is malformed.
+func parseAttachmentPath(c *gin.Context) (tadaID, id uuid.UUID, ok bool) {
+	tadaID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid tada ID",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err = uuid.Parse(c.Param("attachmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid attachment ID",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return tadaID, id, true
+}
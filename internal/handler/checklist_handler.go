@@ -0,0 +1,276 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/kanutocd/tada/internal/dto"
+	"github.com/kanutocd/tada/internal/middleware"
+	"github.com/kanutocd/tada/internal/service"
+)
+
+type ChecklistHandler struct {
+	checklistService service.ChecklistService
+}
+
+func NewChecklistHandler(checklistService service.ChecklistService) *ChecklistHandler {
+	return &ChecklistHandler{checklistService: checklistService}
+}
+
+// checklists returns the checklist service confined to the caller's workspace.
+func (h *ChecklistHandler) checklists(c *gin.Context) service.ChecklistService {
+	return h.checklistService.InWorkspace(middleware.CurrentMembership(c).WorkspaceID)
+}
+
+// GetChecklist godoc
+// @Summary Get a tada's checklist
+// @Description List the items of a tada's checklist in order
+// @Tags checklists
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tada ID"
+// @Success 200 {array} dto.ChecklistItemResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /tadas/{id}/checklist [get]
+func (h *ChecklistHandler) GetChecklist(c *gin.Context) {
+	tadaID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid tada ID",
+		})
+		return
+	}
+
+	items, err := h.checklists(c).GetChecklist(c.Request.Context(), tadaID)
+	if errors.Is(err, service.ErrTadaNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: "Tada not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// AddChecklistItem godoc
+// @Summary Add a checklist item
+// @Description Add an item to the end of a tada's checklist. Only the tada's creator or assignee may change its checklist.
+// @Tags checklists
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tada ID"
+// @Param item body dto.CreateChecklistItemRequest true "Checklist item data"
+// @Success 201 {object} dto.ChecklistItemResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /tadas/{id}/checklist [post]
+func (h *ChecklistHandler) AddChecklistItem(c *gin.Context) {
+	tadaID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid tada ID",
+		})
+		return
+	}
+
+	var req dto.CreateChecklistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid request body",
+		})
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	item, err := h.checklists(c).AddChecklistItem(c.Request.Context(), identity.User, tadaID, req)
+	if respondForbidden(c, err) {
+		return
+	}
+	if errors.Is(err, service.ErrTadaNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: "Tada not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// ReorderChecklist godoc
+// @Summary Reorder a checklist
+// @Description Put a tada's checklist in the given order, listing every item exactly once.
+// @Description Fails with 409 if items were added or removed since the client read the checklist.
+// @Tags checklists
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tada ID"
+// @Param order body dto.ReorderChecklistRequest true "Item IDs in their new order"
+// @Success 200 {array} dto.ChecklistItemResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /tadas/{id}/checklist/order [put]
+func (h *ChecklistHandler) ReorderChecklist(c *gin.Context) {
+	tadaID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid tada ID",
+		})
+		return
+	}
+
+	var req dto.ReorderChecklistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid request body",
+		})
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	items, err := h.checklists(c).ReorderChecklist(c.Request.Context(), identity.User, tadaID, req)
+	if respondForbidden(c, err) {
+		return
+	}
+	if errors.Is(err, service.ErrChecklistConflict) {
+		c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// UpdateChecklistItem godoc
+// @Summary Update a checklist item
+// @Description Edit a checklist item's text, or check or uncheck it
+// @Tags checklists
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tada ID"
+// @Param itemId path string true "Checklist item ID"
+// @Param item body dto.UpdateChecklistItemRequest true "Checklist item update data"
+// @Success 200 {object} dto.ChecklistItemResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /tadas/{id}/checklist/{itemId} [put]
+func (h *ChecklistHandler) UpdateChecklistItem(c *gin.Context) {
+	tadaID, id, ok := parseChecklistPath(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateChecklistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid request body",
+		})
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	item, err := h.checklists(c).UpdateChecklistItem(c.Request.Context(), identity.User, tadaID, id, req)
+	if respondForbidden(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// DeleteChecklistItem godoc
+// @Summary Delete a checklist item
+// @Description Remove an item from a tada's checklist
+// @Tags checklists
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tada ID"
+// @Param itemId path string true "Checklist item ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /tadas/{id}/checklist/{itemId} [delete]
+func (h *ChecklistHandler) DeleteChecklistItem(c *gin.Context) {
+	tadaID, id, ok := parseChecklistPath(c)
+	if !ok {
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	err := h.checklists(c).DeleteChecklistItem(c.Request.Context(), identity.User, tadaID, id)
+	if respondForbidden(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseChecklistPath reads the tada and checklist item IDs from the path,
+// writing a 400 response if either is malformed.
+func parseChecklistPath(c *gin.Context) (tadaID, id uuid.UUID, ok bool) {
+	tadaID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid tada ID",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err = uuid.Parse(c.Param("itemId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid checklist item ID",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return tadaID, id, true
+}
@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/kanutocd/tada/internal/dto"
+	"github.com/kanutocd/tada/internal/middleware"
+	"github.com/kanutocd/tada/internal/service"
+)
+
+type CommentHandler struct {
+	commentService service.CommentService
+}
+
+func NewCommentHandler(commentService service.CommentService) *CommentHandler {
+	return &CommentHandler{commentService: commentService}
+}
+
+// comments returns the comment service confined to the caller's workspace.
+func (h *CommentHandler) comments(c *gin.Context) service.CommentService {
+	return h.commentService.InWorkspace(middleware.CurrentMembership(c).WorkspaceID)
+}
+
+// GetComments godoc
+// @Summary Get a tada's comments
+// @Description Retrieve a paginated list of the comments on a tada, oldest first
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tada ID"
+// @Param cursor query string false "Pagination cursor"
+// @Param limit query int false "Items per page (1-100)" minimum(1) maximum(100) default(10)
+// @Success 200 {object} dto.PaginationResponse{data=[]dto.CommentResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /tadas/{id}/comments [get]
+func (h *CommentHandler) GetComments(c *gin.Context) {
+	tadaID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid tada ID",
+		})
+		return
+	}
+
+	var pagination dto.PaginationQuery
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid pagination parameters",
+		})
+		return
+	}
+
+	response, err := h.comments(c).GetComments(c.Request.Context(), tadaID, pagination)
+	if errors.Is(err, service.ErrTadaNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: "Tada not found",
+		})
+		return
+	}
+	if errors.Is(err, dto.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateComment godoc
+// @Summary Comment on a tada
+// @Description Post a comment as the authenticated user. Mention members with "@" and their email; other emails stay plain text.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tada ID"
+// @Param comment body dto.CreateCommentRequest true "Comment data"
+// @Success 201 {object} dto.CommentResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /tadas/{id}/comments [post]
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	tadaID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid tada ID",
+		})
+		return
+	}
+
+	var req dto.CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid request body",
+		})
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	comment, err := h.comments(c).CreateComment(c.Request.Context(), identity.User, tadaID, req)
+	if errors.Is(err, service.ErrTadaNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: "Tada not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// UpdateComment godoc
+// @Summary Edit a comment
+// @Description Replace a comment's body. Only its author may edit it.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tada ID"
+// @Param commentId path string true "Comment ID"
+// @Param comment body dto.UpdateCommentRequest true "Comment update data"
+// @Success 200 {object} dto.CommentResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /tadas/{id}/comments/{commentId} [put]
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	tadaID, id, ok := parseCommentPath(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid request body",
+		})
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	comment, err := h.comments(c).UpdateComment(c.Request.Context(), identity.User, tadaID, id, req)
+	if respondForbidden(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// DeleteComment godoc
+// @Summary Delete a comment
+// @Description Delete a comment. Its author or the tada's creator may delete it.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tada ID"
+// @Param commentId path string true "Comment ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /tadas/{id}/comments/{commentId} [delete]
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	tadaID, id, ok := parseCommentPath(c)
+	if !ok {
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	err := h.comments(c).DeleteComment(c.Request.Context(), identity.User, tadaID, id)
+	if respondForbidden(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseCommentPath reads the tada and comment IDs from the path, writing a
+// 400 response if either is malformed.
+func parseCommentPath(c *gin.Context) (tadaID, id uuid.UUID, ok bool) {
+	tadaID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid tada ID",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err = uuid.Parse(c.Param("commentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid comment ID",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return tadaID, id, true
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kanutocd/tada/internal/dto"
+	"github.com/kanutocd/tada/internal/service"
+)
+
+// respondForbidden writes a 403 response and reports true if err is a
+// policy denial.
+func respondForbidden(c *gin.Context, err error) bool {
+	var forbidden *service.ForbiddenError
+	if !errors.As(err, &forbidden) {
+		return false
+	}
+
+	c.JSON(http.StatusForbidden, dto.ErrorResponse{
+		Error:  forbidden.Message,
+		Reason: forbidden.Reason,
+	})
+	return true
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kanutocd/tada/internal/dto"
+	"github.com/kanutocd/tada/internal/service"
+)
+
+// A tada or user's ETag is its version as a strong validator, such as "3".
+// It validates the stored record, which changes version whenever it is
+// saved; fields computed from other records, such as a tada's comment
+// count, are not part of it.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", etag(version))
+}
+
+// respondNotModified writes a 304 response with the ETag and reports true
+// if the request's If-None-Match header names version. ETags are compared
+// weakly, as RFC 9110 requires for If-None-Match.
+func respondNotModified(c *gin.Context, version int) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tagVersion(tag) == version {
+			setETag(c, version)
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// parseIfMatch reads the versions named by the request's If-Match header.
+// RFC 9110 requires If-Match to compare ETags strongly, so a weak ETag
+// names no version and, on its own, fails the precondition.
+func parseIfMatch(c *gin.Context) dto.IfMatch {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return dto.IfMatch{}
+	}
+
+	ifMatch := dto.IfMatch{Conditional: true}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") {
+			continue
+		}
+		if version := tagVersion(tag); version >= 0 {
+			ifMatch.Versions = append(ifMatch.Versions, version)
+		}
+	}
+	return ifMatch
+}
+
+// tagVersion returns the version an ETag, weak or strong, names, or -1 if
+// it names none.
+func tagVersion(tag string) int {
+	tag = strings.TrimPrefix(tag, "W/")
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return -1
+	}
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil || version < 0 {
+		return -1
+	}
+	return version
+}
+
+// respondVersionError writes the response for a failed If-Match
+// precondition (412) or a save that lost a race with another writer (409),
+// and reports whether err was either.
+func respondVersionError(c *gin.Context, err error) bool {
+	status := 0
+	switch {
+	case errors.Is(err, service.ErrPreconditionFailed):
+		status = http.StatusPreconditionFailed
+	case errors.Is(err, service.ErrEditConflict):
+		status = http.StatusConflict
+	default:
+		return false
+	}
+
+	c.JSON(status, dto.ErrorResponse{
+		Error: err.Error(),
+	})
+	return true
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kanutocd/tada/internal/dto"
+)
+
+// readPatch reads the body of a PATCH request along with its media type.
+func readPatch(c *gin.Context) (dto.Patch, error) {
+	body, err := c.GetRawData()
+	if err != nil {
+		return dto.Patch{}, err
+	}
+	return dto.Patch{MediaType: c.ContentType(), Body: body}, nil
+}
+
+// respondPatchError writes the response for a patch that could not be
+// decoded or applied, and reports whether err was such a failure.
+func respondPatchError(c *gin.Context, err error) bool {
+	status := 0
+	switch {
+	case errors.Is(err, dto.ErrUnsupportedPatch):
+		status = http.StatusUnsupportedMediaType
+	case errors.Is(err, dto.ErrInvalidPatch):
+		status = http.StatusBadRequest
+	case errors.Is(err, dto.ErrPatchConflict):
+		status = http.StatusConflict
+	default:
+		return false
+	}
+
+	c.JSON(status, dto.ErrorResponse{
+		Error: err.Error(),
+	})
+	return true
+}
@@ -0,0 +1,287 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/kanutocd/tada/internal/dto"
+	"github.com/kanutocd/tada/internal/middleware"
+	"github.com/kanutocd/tada/internal/service"
+)
+
+type ProjectHandler struct {
+	projectService service.ProjectService
+}
+
+func NewProjectHandler(projectService service.ProjectService) *ProjectHandler {
+	return &ProjectHandler{projectService: projectService}
+}
+
+// projects returns the project service confined to the caller's workspace.
+func (h *ProjectHandler) projects(c *gin.Context) service.ProjectService {
+	return h.projectService.InWorkspace(middleware.CurrentMembership(c).WorkspaceID)
+}
+
+// GetProjects godoc
+// @Summary Get projects with pagination
+// @Description Retrieve a paginated list of projects in use, or of archived ones
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param archived query bool false "List archived projects instead"
+// @Param cursor query string false "Pagination cursor"
+// @Param limit query int false "Items per page (1-100)" minimum(1) maximum(100) default(10)
+// @Success 200 {object} dto.PaginationResponse{data=[]dto.ProjectResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /projects [get]
+func (h *ProjectHandler) GetProjects(c *gin.Context) {
+	var pagination dto.PaginationQuery
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid pagination parameters",
+		})
+		return
+	}
+
+	var filter dto.ProjectFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid filter parameters",
+		})
+		return
+	}
+
+	response, err := h.projects(c).GetProjects(c.Request.Context(), filter, pagination)
+	if errors.Is(err, dto.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateProject godoc
+// @Summary Create a new project
+// @Description Create a project owned by the authenticated user. Its workflow defines the statuses of its tadas;
+// @Description without one, they use in_progress, completed and cancelled.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param project body dto.CreateProjectRequest true "Project creation data"
+// @Success 201 {object} dto.ProjectResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /projects [post]
+func (h *ProjectHandler) CreateProject(c *gin.Context) {
+	var req dto.CreateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid request body",
+		})
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	project, err := h.projects(c).CreateProject(c.Request.Context(), identity.User, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, project)
+}
+
+// GetProject godoc
+// @Summary Get project by ID
+// @Description Get project details by ID
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Success 200 {object} dto.ProjectResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /projects/{id} [get]
+func (h *ProjectHandler) GetProject(c *gin.Context) {
+	id, ok := parseProjectID(c)
+	if !ok {
+		return
+	}
+
+	project, err := h.projects(c).GetProjectByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: "Project not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+// UpdateProject godoc
+// @Summary Update project
+// @Description Update a project, or archive or restore it. Only its owner may.
+// @Description Archiving hides the project's tadas from default listings without deleting them.
+// @Description A new workflow must keep every status the project's tadas are in.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param project body dto.UpdateProjectRequest true "Project update data"
+// @Success 200 {object} dto.ProjectResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /projects/{id} [put]
+func (h *ProjectHandler) UpdateProject(c *gin.Context) {
+	id, ok := parseProjectID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid request body",
+		})
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	project, err := h.projects(c).UpdateProject(c.Request.Context(), identity.User, id, req)
+	if respondForbidden(c, err) {
+		return
+	}
+	if errors.Is(err, service.ErrInvalidWorkflow) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, service.ErrWorkflowInUse) {
+		c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+// DeleteProject godoc
+// @Summary Delete project
+// @Description Delete a project. Only its owner may, and only once it has no tadas; archive it otherwise.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /projects/{id} [delete]
+func (h *ProjectHandler) DeleteProject(c *gin.Context) {
+	id, ok := parseProjectID(c)
+	if !ok {
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	err := h.projects(c).DeleteProject(c.Request.Context(), identity.User, id)
+	if respondForbidden(c, err) {
+		return
+	}
+	if errors.Is(err, service.ErrProjectNotEmpty) {
+		c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetProjectStats godoc
+// @Summary Get project stats
+// @Description Count a project's open, completed, cancelled and overdue tadas. Overdue tadas are also open.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Success 200 {object} dto.ProjectStatsResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /projects/{id}/stats [get]
+func (h *ProjectHandler) GetProjectStats(c *gin.Context) {
+	id, ok := parseProjectID(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.projects(c).GetProjectStats(c.Request.Context(), id)
+	if errors.Is(err, service.ErrProjectNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: "Project not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// parseProjectID reads the project ID from the path, writing a 400
+// response and reporting false if it is malformed.
+func parseProjectID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid project ID",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kanutocd/tada/internal/dto"
+	"github.com/kanutocd/tada/internal/middleware"
+	"github.com/kanutocd/tada/internal/service"
+)
+
+type StreamHandler struct {
+	stream    *service.TadaStream
+	heartbeat time.Duration
+}
+
+func NewStreamHandler(stream *service.TadaStream, heartbeat time.Duration) *StreamHandler {
+	return &StreamHandler{stream: stream, heartbeat: heartbeat}
+}
+
+// Stream godoc
+// @Summary Stream tada changes
+// @Description Server-Sent Events stream of tada changes. Each event's name is the change type and its data a
+// @Description dto.TadaChangeEvent. Send Last-Event-ID (or last_event_id) to resume; a stream.reset event means
+// @Description changes were missed and state should be reloaded. Only changes to tadas in the caller's workspace
+// @Description are streamed.
+// @Tags stream
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param created_by query string false "Only tadas created by this user"
+// @Param assigned_to query string false "Only tadas assigned to this user"
+// @Param last_event_id query int false "Resume after this event ID, as an alternative to the Last-Event-ID header"
+// @Param access_token query string false "Access token, for clients that cannot set the Authorization header"
+// @Param workspace_id query string false "Workspace ID, for clients that cannot set the X-Workspace-ID header"
+// @Success 200 {object} dto.TadaChangeEvent
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Router /stream [get]
+func (h *StreamHandler) Stream(c *gin.Context) {
+	var filter dto.StreamFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid filter parameters",
+		})
+		return
+	}
+	filter.WorkspaceID = middleware.CurrentMembership(c).WorkspaceID
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	var lastID uint64
+	if lastEventID != "" {
+		id, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error: "Invalid Last-Event-ID",
+			})
+			return
+		}
+		lastID = id
+	}
+
+	sub, err := h.stream.Subscribe(lastID, filter)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	defer sub.Close()
+
+	// The stream outlives the server's write timeout.
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	fmt.Fprint(w, "retry: 3000\n\n")
+	if sub.Gap {
+		fmt.Fprint(w, "event: stream.reset\ndata: {}\n\n")
+	}
+	for _, event := range sub.Replay {
+		if err := writeStreamEvent(w, event); err != nil {
+			return
+		}
+	}
+	w.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				return
+			}
+			if err := writeStreamEvent(w, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		w.Flush()
+	}
+}
+
+func writeStreamEvent(w io.Writer, event service.StreamEvent) error {
+	data, err := json.Marshal(event.Change.Event())
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Change.Type, data)
+	return err
+}
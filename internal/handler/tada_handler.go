@@ -1,12 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
 	"github.com/kanutocd/tada/internal/dto"
+	"github.com/kanutocd/tada/internal/middleware"
 	"github.com/kanutocd/tada/internal/service"
 )
 
@@ -18,14 +20,37 @@ func NewTadaHandler(tadaService service.TadaService) *TadaHandler {
 	return &TadaHandler{tadaService: tadaService}
 }
 
+// tadas returns the tada service confined to the caller's workspace.
+func (h *TadaHandler) tadas(c *gin.Context) service.TadaService {
+	return h.tadaService.InWorkspace(middleware.CurrentMembership(c).WorkspaceID)
+}
+
 // GetTadas godoc
 // @Summary Get tadas with pagination
-// @Description Retrieve a paginated list of tadas
+// @Description Retrieve a filtered, sorted and paginated list of tadas. Sort by created_at, due_at, updated_at,
+// @Description name, status or priority, prefixed with - for descending order.
 // @Tags tadas
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param cursor query string false "Pagination cursor"
 // @Param limit query int false "Items per page (1-100)" minimum(1) maximum(100) default(10)
+// @Param status query []string false "Filter by workflow status" collectionFormat(multi)
+// @Param status_category query []string false "Filter by status category" collectionFormat(multi) Enums(open, completed, cancelled)
+// @Param created_by query string false "Filter by creator ID"
+// @Param assigned_to query string false "Filter by assignee ID"
+// @Param series_id query string false "Filter by recurring series (ID of its first tada)"
+// @Param project_id query string false "Filter by project ID"
+// @Param include_archived query bool false "Include tadas in archived projects"
+// @Param unassigned query bool false "Only unassigned (true) or only assigned (false) tadas"
+// @Param due_before query string false "Due before (RFC 3339)"
+// @Param due_after query string false "Due after (RFC 3339)"
+// @Param completed_before query string false "Completed before (RFC 3339)"
+// @Param completed_after query string false "Completed after (RFC 3339)"
+// @Param tag query []string false "Filter by tag name" collectionFormat(multi)
+// @Param tag_mode query string false "Match all or any of the tags" Enums(all, any) default(all)
+// @Param blocked query bool false "Only tadas waiting on (true) or not waiting on (false) a blocker that is still open"
+// @Param sort query string false "Sort key, prefix with - for descending" default(-created_at)
 // @Success 200 {object} dto.PaginationResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
@@ -39,7 +64,231 @@ func (h *TadaHandler) GetTadas(c *gin.Context) {
 		return
 	}
 
-	response, err := h.tadaService.GetTadas(pagination)
+	var filter dto.TadaFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid filter parameters",
+		})
+		return
+	}
+
+	response, err := h.tadas(c).GetTadas(c.Request.Context(), filter, pagination)
+	if errors.Is(err, dto.ErrInvalidCursor) || errors.Is(err, dto.ErrInvalidSort) || errors.Is(err, service.ErrInvalidTagName) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SearchTadas godoc
+// @Summary Search tadas
+// @Description Full-text search over tada names, descriptions and comments, best match first.
+// @Description The listing filters narrow the tadas searched; sort is not supported.
+// @Description Snippets are HTML with the matched words wrapped in <mark> elements.
+// @Tags tadas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search text; supports quoted phrases, or, and -word" minlength(1) maxlength(256)
+// @Param cursor query string false "Pagination cursor"
+// @Param limit query int false "Items per page (1-100)" minimum(1) maximum(100) default(10)
+// @Param status query []string false "Filter by workflow status" collectionFormat(multi)
+// @Param status_category query []string false "Filter by status category" collectionFormat(multi) Enums(open, completed, cancelled)
+// @Param created_by query string false "Filter by creator ID"
+// @Param assigned_to query string false "Filter by assignee ID"
+// @Param series_id query string false "Filter by recurring series (ID of its first tada)"
+// @Param project_id query string false "Filter by project ID"
+// @Param include_archived query bool false "Include tadas in archived projects"
+// @Param unassigned query bool false "Only unassigned (true) or only assigned (false) tadas"
+// @Param due_before query string false "Due before (RFC 3339)"
+// @Param due_after query string false "Due after (RFC 3339)"
+// @Param completed_before query string false "Completed before (RFC 3339)"
+// @Param completed_after query string false "Completed after (RFC 3339)"
+// @Param tag query []string false "Filter by tag name" collectionFormat(multi)
+// @Param tag_mode query string false "Match all or any of the tags" Enums(all, any) default(all)
+// @Param blocked query bool false "Only tadas waiting on (true) or not waiting on (false) a blocker that is still open"
+// @Success 200 {object} dto.PaginationResponse{data=[]dto.SearchResultResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /search [get]
+func (h *TadaHandler) SearchTadas(c *gin.Context) {
+	var query dto.SearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid search query",
+		})
+		return
+	}
+
+	var pagination dto.PaginationQuery
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid pagination parameters",
+		})
+		return
+	}
+
+	var filter dto.TadaFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid filter parameters",
+		})
+		return
+	}
+	filter.Sort = ""
+
+	response, err := h.tadas(c).Search(c.Request.Context(), query, filter, pagination)
+	if errors.Is(err, dto.ErrInvalidCursor) || errors.Is(err, service.ErrInvalidTagName) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetUserTadas godoc
+// @Summary Get a user's tadas with pagination
+// @Description Retrieve a paginated list of the tadas a member of the workspace created, is assigned to, or either
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param role query string false "Which tadas to list" Enums(creator, assignee, any) default(any)
+// @Param cursor query string false "Pagination cursor"
+// @Param limit query int false "Items per page (1-100)" minimum(1) maximum(100) default(10)
+// @Success 200 {object} dto.PaginationResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /users/{id}/tadas [get]
+func (h *TadaHandler) GetUserTadas(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid user ID",
+		})
+		return
+	}
+
+	var pagination dto.PaginationQuery
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid pagination parameters",
+		})
+		return
+	}
+
+	var query dto.UserTadasQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid role",
+		})
+		return
+	}
+
+	response, err := h.tadas(c).GetUserTadas(c.Request.Context(), id, query.Role, pagination)
+	if errors.Is(err, service.ErrUserNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: "User not found",
+		})
+		return
+	}
+	if errors.Is(err, dto.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetProjectTadas godoc
+// @Summary Get a project's tadas with pagination
+// @Description Retrieve a filtered, sorted and paginated list of a project's tadas, including when it is archived
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param cursor query string false "Pagination cursor"
+// @Param limit query int false "Items per page (1-100)" minimum(1) maximum(100) default(10)
+// @Param status query []string false "Filter by workflow status" collectionFormat(multi)
+// @Param status_category query []string false "Filter by status category" collectionFormat(multi) Enums(open, completed, cancelled)
+// @Param assigned_to query string false "Filter by assignee ID"
+// @Param due_before query string false "Due before (RFC 3339)"
+// @Param due_after query string false "Due after (RFC 3339)"
+// @Param tag query []string false "Filter by tag name" collectionFormat(multi)
+// @Param sort query string false "Sort key, prefix with - for descending" default(-created_at)
+// @Success 200 {object} dto.PaginationResponse{data=[]dto.TadaResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /projects/{id}/tadas [get]
+func (h *TadaHandler) GetProjectTadas(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid project ID",
+		})
+		return
+	}
+
+	var pagination dto.PaginationQuery
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid pagination parameters",
+		})
+		return
+	}
+
+	var filter dto.TadaFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid filter parameters",
+		})
+		return
+	}
+
+	response, err := h.tadas(c).GetProjectTadas(c.Request.Context(), id, filter, pagination)
+	if errors.Is(err, service.ErrProjectNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: "Project not found",
+		})
+		return
+	}
+	if errors.Is(err, dto.ErrInvalidCursor) || errors.Is(err, dto.ErrInvalidSort) || errors.Is(err, service.ErrInvalidTagName) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error: err.Error(),
@@ -52,13 +301,21 @@ func (h *TadaHandler) GetTadas(c *gin.Context) {
 
 // CreateTada godoc
 // @Summary Create a new tada
-// @Description Create a new tada task
+// @Description Create a new tada task owned by the authenticated user. Retries sent with the same
+// @Description Idempotency-Key get the first response, with the Idempotent-Replayed header set.
 // @Tags tadas
 // @Accept json
 // @Produce json
+// @Security BearerAuth
+// @Param Idempotency-Key header string false "Key that makes the request safe to retry" maxlength(255)
 // @Param tada body dto.CreateTadaRequest true "Tada creation data"
 // @Success 201 {object} dto.TadaResponse
+// @Header 201 {string} Idempotent-Replayed "Set to true on a replayed response"
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
 // @Router /tadas [post]
 func (h *TadaHandler) CreateTada(c *gin.Context) {
 	var req dto.CreateTadaRequest
@@ -69,25 +326,46 @@ func (h *TadaHandler) CreateTada(c *gin.Context) {
 		return
 	}
 
-	tada, err := h.tadaService.CreateTada(req)
-	if err != nil {
+	identity := middleware.CurrentIdentity(c)
+
+	tada, err := h.tadas(c).CreateTada(c.Request.Context(), identity.User.ID, req)
+	if isInvalidTadaUpdate(err) {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error: err.Error(),
 		})
 		return
 	}
+	if errors.Is(err, service.ErrProjectArchived) {
+		c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	// Anything else is the server's fault, and must not be stored as the
+	// answer to an Idempotency-Key.
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
 
 	c.JSON(http.StatusCreated, tada)
 }
 
 // GetTada godoc
 // @Summary Get tada by ID
-// @Description Get tada details by ID
+// @Description Get tada details by ID. The ETag header carries the tada's version; send it back in
+// @Description If-None-Match to get 304 Not Modified while it is unchanged.
 // @Tags tadas
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "Tada ID"
+// @Param If-None-Match header string false "ETag of the copy the client has"
 // @Success 200 {object} dto.TadaResponse
+// @Header 200 {string} ETag "The tada's version, as a strong ETag"
+// @Success 304
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
 // @Router /tadas/{id} [get]
@@ -101,7 +379,7 @@ func (h *TadaHandler) GetTada(c *gin.Context) {
 		return
 	}
 
-	tada, err := h.tadaService.GetTadaByID(id)
+	tada, err := h.tadas(c).GetTadaByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, dto.ErrorResponse{
 			Error: "Tada not found",
@@ -109,20 +387,32 @@ func (h *TadaHandler) GetTada(c *gin.Context) {
 		return
 	}
 
+	if respondNotModified(c, tada.Version) {
+		return
+	}
+
+	setETag(c, tada.Version)
 	c.JSON(http.StatusOK, tada)
 }
 
 // UpdateTada godoc
 // @Summary Update tada
-// @Description Update tada details
+// @Description Update tada details. A status change must be allowed by the workflow of the tada's project.
+// @Description With If-Match, the update is only made if the tada is still at that version.
 // @Tags tadas
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "Tada ID"
+// @Param If-Match header string false "Strong ETag of the version being updated"
 // @Param tada body dto.UpdateTadaRequest true "Tada update data"
 // @Success 200 {object} dto.TadaResponse
+// @Header 200 {string} ETag "The tada's new version, as a strong ETag"
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 412 {object} dto.ErrorResponse
 // @Router /tadas/{id} [put]
 func (h *TadaHandler) UpdateTada(c *gin.Context) {
 	idStr := c.Param("id")
@@ -142,7 +432,103 @@ func (h *TadaHandler) UpdateTada(c *gin.Context) {
 		return
 	}
 
-	tada, err := h.tadaService.UpdateTada(id, req)
+	identity := middleware.CurrentIdentity(c)
+
+	tada, err := h.tadas(c).UpdateTada(c.Request.Context(), identity.User, id, req, parseIfMatch(c))
+	if respondForbidden(c, err) || respondVersionError(c, err) {
+		return
+	}
+	if isInvalidTadaUpdate(err) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, service.ErrIncompleteSubtasks) || errors.Is(err, service.ErrTadaBlocked) ||
+		errors.Is(err, service.ErrProjectArchived) || errors.Is(err, service.ErrStatusTransition) {
+		c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	setETag(c, tada.Version)
+	c.JSON(http.StatusOK, tada)
+}
+
+// PatchTada godoc
+// @Summary Patch tada
+// @Description Change some of a tada's fields with a JSON merge patch (RFC 7396), where null clears a field,
+// @Description or a JSON Patch (RFC 6902) applied to the tada's changeable fields. A plain JSON body is taken
+// @Description to be a merge patch. The rules of PUT apply, and If-Match works as it does there.
+// @Tags tadas
+// @Accept application/merge-patch+json,application/json-patch+json,json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tada ID"
+// @Param If-Match header string false "Strong ETag of the version being updated"
+// @Param cascade query bool false "Complete open subtasks along with the tada"
+// @Param patch body object true "Merge patch object or JSON Patch array"
+// @Success 200 {object} dto.TadaResponse
+// @Header 200 {string} ETag "The tada's new version, as a strong ETag"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 412 {object} dto.ErrorResponse
+// @Failure 415 {object} dto.ErrorResponse
+// @Router /tadas/{id} [patch]
+func (h *TadaHandler) PatchTada(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid tada ID",
+		})
+		return
+	}
+
+	var query dto.PatchTadaQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid query parameters",
+		})
+		return
+	}
+
+	patch, err := readPatch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid request body",
+		})
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	tada, err := h.tadas(c).PatchTada(c.Request.Context(), identity.User, id, patch, query.Cascade, parseIfMatch(c))
+	if respondForbidden(c, err) || respondVersionError(c, err) || respondPatchError(c, err) {
+		return
+	}
+	if isInvalidTadaUpdate(err) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, service.ErrIncompleteSubtasks) || errors.Is(err, service.ErrTadaBlocked) ||
+		errors.Is(err, service.ErrProjectArchived) || errors.Is(err, service.ErrStatusTransition) {
+		c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, dto.ErrorResponse{
 			Error: err.Error(),
@@ -150,19 +536,26 @@ func (h *TadaHandler) UpdateTada(c *gin.Context) {
 		return
 	}
 
+	setETag(c, tada.Version)
 	c.JSON(http.StatusOK, tada)
 }
 
 // DeleteTada godoc
 // @Summary Delete tada
-// @Description Delete tada by ID
+// @Description Delete tada by ID. Tadas with subtasks cannot be deleted.
+// @Description With If-Match, the tada is only deleted if it is still at that version.
 // @Tags tadas
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "Tada ID"
+// @Param If-Match header string false "Strong ETag of the version being deleted"
 // @Success 204
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 412 {object} dto.ErrorResponse
 // @Router /tadas/{id} [delete]
 func (h *TadaHandler) DeleteTada(c *gin.Context) {
 	idStr := c.Param("id")
@@ -174,7 +567,18 @@ func (h *TadaHandler) DeleteTada(c *gin.Context) {
 		return
 	}
 
-	err = h.tadaService.DeleteTada(id)
+	identity := middleware.CurrentIdentity(c)
+
+	err = h.tadas(c).DeleteTada(c.Request.Context(), identity.User, id, parseIfMatch(c))
+	if respondForbidden(c, err) || respondVersionError(c, err) {
+		return
+	}
+	if errors.Is(err, service.ErrHasSubtasks) {
+		c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, dto.ErrorResponse{
 			Error: err.Error(),
@@ -184,3 +588,436 @@ func (h *TadaHandler) DeleteTada(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// BulkTadas godoc
+// @Summary Change tadas in bulk
+// @Description Create, update and delete tadas in one transaction, either through a list of operations or by
+// @Description applying a merge patch to every tada a filter matches. Each operation follows the rules of its
+// @Description single-tada counterpart, with version in place of If-Match. In all_or_nothing mode (the default)
+// @Description the first failure rolls everything back and its status is the response's; in per_item mode each
+// @Description operation that succeeds is kept and the response is 200 with each operation's status.
+// @Tags tadas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.BulkTadaRequest true "Operations, or a filter and patch"
+// @Success 200 {object} dto.BulkTadaResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.BulkTadaResponse
+// @Failure 404 {object} dto.BulkTadaResponse
+// @Failure 409 {object} dto.BulkTadaResponse
+// @Failure 412 {object} dto.BulkTadaResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /tadas/bulk [post]
+func (h *TadaHandler) BulkTadas(c *gin.Context) {
+	var req dto.BulkTadaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid request body",
+		})
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	response, err := h.tadas(c).BulkTadas(c.Request.Context(), identity.User, req)
+	if errors.Is(err, service.ErrInvalidBulk) || errors.Is(err, service.ErrBulkTooLarge) ||
+		errors.Is(err, dto.ErrInvalidPatch) || errors.Is(err, dto.ErrInvalidSort) || errors.Is(err, service.ErrInvalidTagName) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	for i := range response.Results {
+		result := &response.Results[i]
+		result.Status = bulkResultStatus(result.Op, result.Err)
+		if result.Err != nil {
+			result.Error = result.Err.Error()
+		}
+	}
+
+	status := http.StatusOK
+	if !response.Committed {
+		status = response.Results[0].Status
+	}
+	c.JSON(status, response)
+}
+
+// GetTadaHistory godoc
+// @Summary Get tada history
+// @Description Retrieve a paginated timeline of changes to a tada, newest first
+// @Tags tadas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tada ID"
+// @Param cursor query string false "Pagination cursor"
+// @Param limit query int false "Items per page (1-100)" minimum(1) maximum(100) default(10)
+// @Success 200 {object} dto.PaginationResponse{data=[]dto.TadaEventResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /tadas/{id}/history [get]
+func (h *TadaHandler) GetTadaHistory(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid tada ID",
+		})
+		return
+	}
+
+	var pagination dto.PaginationQuery
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid pagination parameters",
+		})
+		return
+	}
+
+	response, err := h.tadas(c).GetTadaHistory(c.Request.Context(), id, pagination)
+	if errors.Is(err, service.ErrTadaNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: "Tada not found",
+		})
+		return
+	}
+	if errors.Is(err, dto.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetTadaChildren godoc
+// @Summary Get a tada's subtasks
+// @Description Retrieve a paginated list of a tada's direct subtasks
+// @Tags tadas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tada ID"
+// @Param cursor query string false "Pagination cursor"
+// @Param limit query int false "Items per page (1-100)" minimum(1) maximum(100) default(10)
+// @Success 200 {object} dto.PaginationResponse{data=[]dto.TadaResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /tadas/{id}/children [get]
+func (h *TadaHandler) GetTadaChildren(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid tada ID",
+		})
+		return
+	}
+
+	var pagination dto.PaginationQuery
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid pagination parameters",
+		})
+		return
+	}
+
+	response, err := h.tadas(c).GetTadaChildren(c.Request.Context(), id, pagination)
+	if errors.Is(err, service.ErrTadaNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: "Tada not found",
+		})
+		return
+	}
+	if errors.Is(err, dto.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetTadaTree godoc
+// @Summary Get a tada's subtask tree
+// @Description Retrieve a tada with its subtasks nested up to the given depth
+// @Tags tadas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tada ID"
+// @Param depth query int false "Levels of subtasks to include (1-10)" minimum(1) maximum(10) default(3)
+// @Success 200 {object} dto.TadaTreeResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /tadas/{id}/tree [get]
+func (h *TadaHandler) GetTadaTree(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid tada ID",
+		})
+		return
+	}
+
+	var query dto.TadaTreeQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid depth",
+		})
+		return
+	}
+
+	tree, err := h.tadas(c).GetTadaTree(c.Request.Context(), id, query.Depth)
+	if errors.Is(err, service.ErrTadaNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: "Tada not found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, tree)
+}
+
+// AddDependency godoc
+// @Summary Add a blocking dependency
+// @Description Record that this tada blocks another, which then cannot be completed until this one is
+// @Tags tadas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Blocking tada ID"
+// @Param dependency body dto.CreateTadaDependencyRequest true "Tada to block"
+// @Success 201 {object} dto.TadaDependencyResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /tadas/{id}/blocks [post]
+func (h *TadaHandler) AddDependency(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid tada ID",
+		})
+		return
+	}
+
+	var req dto.CreateTadaDependencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid request body",
+		})
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	dependency, err := h.tadas(c).AddDependency(c.Request.Context(), identity.User, id, req.TadaID)
+	if respondForbidden(c, err) {
+		return
+	}
+	if errors.Is(err, service.ErrTadaNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: "Tada not found",
+		})
+		return
+	}
+	if errors.Is(err, service.ErrDependencyCycle) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, service.ErrDependencyExists) {
+		c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dependency)
+}
+
+// RemoveDependency godoc
+// @Summary Remove a blocking dependency
+// @Description Remove the record that this tada blocks another
+// @Tags tadas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Blocking tada ID"
+// @Param blocked_id path string true "Blocked tada ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /tadas/{id}/blocks/{blocked_id} [delete]
+func (h *TadaHandler) RemoveDependency(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid tada ID",
+		})
+		return
+	}
+
+	blockedID, err := uuid.Parse(c.Param("blocked_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid blocked tada ID",
+		})
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	err = h.tadas(c).RemoveDependency(c.Request.Context(), identity.User, id, blockedID)
+	if respondForbidden(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetTadaOccurrences godoc
+// @Summary Preview a recurring tada's occurrences
+// @Description List the due dates of the occurrences that will follow a recurring tada
+// @Tags tadas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tada ID"
+// @Param count query int false "Number of occurrences (1-100)" minimum(1) maximum(100) default(5)
+// @Success 200 {object} dto.TadaOccurrencesResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /tadas/{id}/occurrences [get]
+func (h *TadaHandler) GetTadaOccurrences(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid tada ID",
+		})
+		return
+	}
+
+	var query dto.TadaOccurrencesQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid count",
+		})
+		return
+	}
+
+	response, err := h.tadas(c).GetTadaOccurrences(c.Request.Context(), id, query.Count)
+	if errors.Is(err, service.ErrTadaNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: "Tada not found",
+		})
+		return
+	}
+	if errors.Is(err, service.ErrNotRecurring) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// bulkResultStatus returns the status an operation of a bulk request would
+// have had as a request of its own.
+func bulkResultStatus(op string, err error) int {
+	var forbidden *service.ForbiddenError
+	switch {
+	case err == nil && op == dto.BulkCreate:
+		return http.StatusCreated
+	case err == nil && op == dto.BulkDelete:
+		return http.StatusNoContent
+	case err == nil:
+		return http.StatusOK
+	case errors.As(err, &forbidden):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, service.ErrInvalidBulk) || errors.Is(err, dto.ErrInvalidPatch) || isInvalidTadaUpdate(err):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrEditConflict) || errors.Is(err, service.ErrIncompleteSubtasks) ||
+		errors.Is(err, service.ErrTadaBlocked) || errors.Is(err, service.ErrProjectArchived) ||
+		errors.Is(err, service.ErrStatusTransition) || errors.Is(err, service.ErrHasSubtasks):
+		return http.StatusConflict
+	case op == dto.BulkCreate:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusNotFound
+	}
+}
+
+// isInvalidTadaUpdate reports whether err rejects the values in a create or
+// update request, as opposed to the tada's state.
+func isInvalidTadaUpdate(err error) bool {
+	return errors.Is(err, service.ErrUserNotFound) ||
+		errors.Is(err, service.ErrInvalidTagName) ||
+		errors.Is(err, service.ErrParentNotFound) ||
+		errors.Is(err, service.ErrProjectNotFound) ||
+		errors.Is(err, service.ErrNotMember) ||
+		errors.Is(err, service.ErrInvalidStatus) ||
+		errors.Is(err, service.ErrTadaCycle) ||
+		errors.Is(err, service.ErrInvalidRecurrence) ||
+		errors.Is(err, service.ErrRecurrenceNoDueAt)
+}
@@ -1,12 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
 	"github.com/kanutocd/tada/internal/dto"
+	"github.com/kanutocd/tada/internal/middleware"
 	"github.com/kanutocd/tada/internal/service"
 )
 
@@ -20,10 +22,11 @@ func NewUserHandler(userService service.UserService) *UserHandler {
 
 // GetUsers godoc
 // @Summary Get users with pagination
-// @Description Retrieve a paginated list of users
+// @Description Retrieve a paginated list of the workspace's members
 // @Tags users
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param cursor query string false "Pagination cursor"
 // @Param limit query int false "Items per page (1-100)" minimum(1) maximum(100) default(10)
 // @Success 200 {object} dto.PaginationResponse
@@ -39,7 +42,7 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 		return
 	}
 
-	response, err := h.userService.GetUsers(pagination)
+	response, err := h.userService.GetUsers(c.Request.Context(), middleware.CurrentMembership(c).WorkspaceID, pagination)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error: err.Error(),
@@ -52,14 +55,20 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 
 // CreateUser godoc
 // @Summary Create a new user
-// @Description Create a new user account
+// @Description Create a new user account. Retries sent with the same Idempotency-Key get the first
+// @Description response, with the Idempotent-Replayed header set.
 // @Tags users
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Key that makes the request safe to retry" maxlength(255)
 // @Param user body dto.CreateUserRequest true "User creation data"
 // @Success 201 {object} dto.UserResponse
+// @Header 201 {string} Idempotent-Replayed "Set to true on a replayed response"
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 409 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
 // @Router /users [post]
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var req dto.CreateUserRequest
@@ -70,25 +79,38 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.CreateUser(req)
-	if err != nil {
+	user, err := h.userService.CreateUser(c.Request.Context(), req)
+	if errors.Is(err, service.ErrEmailTaken) {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error: err.Error(),
 		})
 		return
 	}
+	// Anything else is the server's fault, and must not be stored as the
+	// answer to an Idempotency-Key.
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
 
 	c.JSON(http.StatusCreated, user)
 }
 
 // GetUser godoc
 // @Summary Get user by ID
-// @Description Get user details by ID
+// @Description Get the details of a member of the workspace by ID. The ETag header carries the user's
+// @Description version; send it back in If-None-Match to get 304 Not Modified while it is unchanged.
 // @Tags users
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "User ID"
+// @Param If-None-Match header string false "ETag of the copy the client has"
 // @Success 200 {object} dto.UserResponse
+// @Header 200 {string} ETag "The user's version, as a strong ETag"
+// @Success 304
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
 // @Router /users/{id} [get]
@@ -102,7 +124,7 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.GetUserByID(id)
+	user, err := h.userService.GetUserByID(c.Request.Context(), middleware.CurrentMembership(c).WorkspaceID, id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, dto.ErrorResponse{
 			Error: "User not found",
@@ -110,20 +132,33 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 		return
 	}
 
+	if respondNotModified(c, user.Version) {
+		return
+	}
+
+	setETag(c, user.Version)
 	c.JSON(http.StatusOK, user)
 }
 
 // UpdateUser godoc
 // @Summary Update user
-// @Description Update user details
+// @Description Update the caller's own details. Changing the email or password takes current_password, and a new
+// @Description password signs out the caller's other sessions. With If-Match, the update is only made if the user
+// @Description is still at that version.
 // @Tags users
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "User ID"
+// @Param If-Match header string false "Strong ETag of the version being updated"
 // @Param user body dto.UpdateUserRequest true "User update data"
 // @Success 200 {object} dto.UserResponse
+// @Header 200 {string} ETag "The user's new version, as a strong ETag"
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 412 {object} dto.ErrorResponse
 // @Router /users/{id} [put]
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	idStr := c.Param("id")
@@ -143,7 +178,64 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.UpdateUser(id, req)
+	user, err := h.userService.UpdateUser(c.Request.Context(), middleware.CurrentIdentity(c), id, req, parseIfMatch(c))
+	if respondForbidden(c, err) || respondVersionError(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	setETag(c, user.Version)
+	c.JSON(http.StatusOK, user)
+}
+
+// PatchUser godoc
+// @Summary Patch user
+// @Description Change some of a user's details with a JSON merge patch (RFC 7396) or a JSON Patch (RFC 6902)
+// @Description applied to the caller's own name and email. A plain JSON body is taken to be a merge patch. None
+// @Description of the fields can be cleared. current_password and If-Match work as they do for PUT.
+// @Tags users
+// @Accept application/merge-patch+json,application/json-patch+json,json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param If-Match header string false "Strong ETag of the version being updated"
+// @Param patch body object true "Merge patch object or JSON Patch array"
+// @Success 200 {object} dto.UserResponse
+// @Header 200 {string} ETag "The user's new version, as a strong ETag"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 412 {object} dto.ErrorResponse
+// @Failure 415 {object} dto.ErrorResponse
+// @Router /users/{id} [patch]
+func (h *UserHandler) PatchUser(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid user ID",
+		})
+		return
+	}
+
+	patch, err := readPatch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid request body",
+		})
+		return
+	}
+
+	user, err := h.userService.PatchUser(c.Request.Context(), middleware.CurrentIdentity(c), id, patch, parseIfMatch(c))
+	if respondForbidden(c, err) || respondVersionError(c, err) || respondPatchError(c, err) {
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, dto.ErrorResponse{
 			Error: err.Error(),
@@ -151,19 +243,26 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	setETag(c, user.Version)
 	c.JSON(http.StatusOK, user)
 }
 
 // DeleteUser godoc
 // @Summary Delete user
-// @Description Delete user by ID
+// @Description Delete the caller's own account. With If-Match, the user is only deleted if they are still at that
+// @Description version.
 // @Tags users
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "User ID"
+// @Param If-Match header string false "Strong ETag of the version being deleted"
 // @Success 204
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 412 {object} dto.ErrorResponse
 // @Router /users/{id} [delete]
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	idStr := c.Param("id")
@@ -175,7 +274,10 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	err = h.userService.DeleteUser(id)
+	err = h.userService.DeleteUser(c.Request.Context(), middleware.CurrentIdentity(c), id, parseIfMatch(c))
+	if respondForbidden(c, err) || respondVersionError(c, err) {
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, dto.ErrorResponse{
 			Error: err.Error(),
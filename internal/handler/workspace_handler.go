@@ -0,0 +1,486 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/kanutocd/tada/internal/dto"
+	"github.com/kanutocd/tada/internal/middleware"
+	"github.com/kanutocd/tada/internal/service"
+)
+
+type WorkspaceHandler struct {
+	workspaceService service.WorkspaceService
+}
+
+func NewWorkspaceHandler(workspaceService service.WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{workspaceService: workspaceService}
+}
+
+// GetWorkspaces godoc
+// @Summary Get workspaces with pagination
+// @Description Retrieve a paginated list of the workspaces the authenticated user belongs to, with their role in each
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param cursor query string false "Pagination cursor"
+// @Param limit query int false "Items per page (1-100)" minimum(1) maximum(100) default(10)
+// @Success 200 {object} dto.PaginationResponse{data=[]dto.WorkspaceResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /workspaces [get]
+func (h *WorkspaceHandler) GetWorkspaces(c *gin.Context) {
+	var pagination dto.PaginationQuery
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid pagination parameters",
+		})
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	response, err := h.workspaceService.GetWorkspaces(c.Request.Context(), identity.User, pagination)
+	if errors.Is(err, dto.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateWorkspace godoc
+// @Summary Create a new workspace
+// @Description Create a workspace owned by the authenticated user
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param workspace body dto.CreateWorkspaceRequest true "Workspace creation data"
+// @Success 201 {object} dto.WorkspaceResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /workspaces [post]
+func (h *WorkspaceHandler) CreateWorkspace(c *gin.Context) {
+	var req dto.CreateWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid request body",
+		})
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	workspace, err := h.workspaceService.CreateWorkspace(c.Request.Context(), identity.User, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, workspace)
+}
+
+// GetWorkspace godoc
+// @Summary Get workspace by ID
+// @Description Get a workspace the authenticated user belongs to
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Workspace ID"
+// @Success 200 {object} dto.WorkspaceResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /workspaces/{id} [get]
+func (h *WorkspaceHandler) GetWorkspace(c *gin.Context) {
+	id, ok := parseWorkspaceID(c)
+	if !ok {
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	workspace, err := h.workspaceService.GetWorkspace(c.Request.Context(), identity.User, id)
+	if respondWorkspaceError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace)
+}
+
+// UpdateWorkspace godoc
+// @Summary Update workspace
+// @Description Rename a workspace. Admins and the owner may.
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Workspace ID"
+// @Param workspace body dto.UpdateWorkspaceRequest true "Workspace update data"
+// @Success 200 {object} dto.WorkspaceResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /workspaces/{id} [put]
+func (h *WorkspaceHandler) UpdateWorkspace(c *gin.Context) {
+	id, ok := parseWorkspaceID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid request body",
+		})
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	workspace, err := h.workspaceService.UpdateWorkspace(c.Request.Context(), identity.User, id, req)
+	if respondWorkspaceError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace)
+}
+
+// GetMembers godoc
+// @Summary Get workspace members with pagination
+// @Description Retrieve a paginated list of a workspace's members and their roles
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Workspace ID"
+// @Param cursor query string false "Pagination cursor"
+// @Param limit query int false "Items per page (1-100)" minimum(1) maximum(100) default(10)
+// @Success 200 {object} dto.PaginationResponse{data=[]dto.WorkspaceMemberResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /workspaces/{id}/members [get]
+func (h *WorkspaceHandler) GetMembers(c *gin.Context) {
+	id, ok := parseWorkspaceID(c)
+	if !ok {
+		return
+	}
+
+	var pagination dto.PaginationQuery
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid pagination parameters",
+		})
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	response, err := h.workspaceService.GetMembers(c.Request.Context(), identity.User, id, pagination)
+	if errors.Is(err, dto.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if respondWorkspaceError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateMember godoc
+// @Summary Change a member's role
+// @Description Give a member another role. Admins and the owner may; the owner's role cannot be changed.
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Workspace ID"
+// @Param userId path string true "User ID"
+// @Param member body dto.UpdateWorkspaceMemberRequest true "Member update data"
+// @Success 200 {object} dto.WorkspaceMemberResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /workspaces/{id}/members/{userId} [put]
+func (h *WorkspaceHandler) UpdateMember(c *gin.Context) {
+	id, userID, ok := parseWorkspacePath(c, "userId", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateWorkspaceMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid request body",
+		})
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	member, err := h.workspaceService.UpdateMember(c.Request.Context(), identity.User, id, userID, req)
+	if respondWorkspaceError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveMember godoc
+// @Summary Remove a member
+// @Description Take a user out of a workspace. Admins and the owner may remove anyone but the owner, and members may leave.
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Workspace ID"
+// @Param userId path string true "User ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /workspaces/{id}/members/{userId} [delete]
+func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
+	id, userID, ok := parseWorkspacePath(c, "userId", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	err := h.workspaceService.RemoveMember(c.Request.Context(), identity.User, id, userID)
+	if respondWorkspaceError(c, err) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetInvitations godoc
+// @Summary Get pending invitations with pagination
+// @Description Retrieve a paginated list of a workspace's invitations that can still be accepted. Admins and the owner may.
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Workspace ID"
+// @Param cursor query string false "Pagination cursor"
+// @Param limit query int false "Items per page (1-100)" minimum(1) maximum(100) default(10)
+// @Success 200 {object} dto.PaginationResponse{data=[]dto.WorkspaceInvitationResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /workspaces/{id}/invitations [get]
+func (h *WorkspaceHandler) GetInvitations(c *gin.Context) {
+	id, ok := parseWorkspaceID(c)
+	if !ok {
+		return
+	}
+
+	var pagination dto.PaginationQuery
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid pagination parameters",
+		})
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	response, err := h.workspaceService.GetInvitations(c.Request.Context(), identity.User, id, pagination)
+	if errors.Is(err, dto.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	if respondWorkspaceError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// InviteMember godoc
+// @Summary Invite someone to a workspace
+// @Description Email an invitation to join a workspace. Admins and the owner may.
+// @Description The emailed token expires after the configured invitation TTL.
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Workspace ID"
+// @Param invitation body dto.CreateWorkspaceInvitationRequest true "Invitation data"
+// @Success 201 {object} dto.WorkspaceInvitationResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /workspaces/{id}/invitations [post]
+func (h *WorkspaceHandler) InviteMember(c *gin.Context) {
+	id, ok := parseWorkspaceID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateWorkspaceInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid request body",
+		})
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	invitation, err := h.workspaceService.InviteMember(c.Request.Context(), identity.User, id, req)
+	if respondWorkspaceError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// RevokeInvitation godoc
+// @Summary Revoke an invitation
+// @Description Withdraw an invitation so that its token no longer works. Admins and the owner may.
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Workspace ID"
+// @Param invitationId path string true "Invitation ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /workspaces/{id}/invitations/{invitationId} [delete]
+func (h *WorkspaceHandler) RevokeInvitation(c *gin.Context) {
+	id, invitationID, ok := parseWorkspacePath(c, "invitationId", "Invalid invitation ID")
+	if !ok {
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	err := h.workspaceService.RevokeInvitation(c.Request.Context(), identity.User, id, invitationID)
+	if respondWorkspaceError(c, err) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AcceptInvitation godoc
+// @Summary Accept an invitation
+// @Description Join the workspace an invitation is for. It must have been sent to the authenticated user's email address.
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param invitation body dto.AcceptWorkspaceInvitationRequest true "Invitation token"
+// @Success 200 {object} dto.WorkspaceResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 410 {object} dto.ErrorResponse
+// @Router /workspaces/invitations/accept [post]
+func (h *WorkspaceHandler) AcceptInvitation(c *gin.Context) {
+	var req dto.AcceptWorkspaceInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid request body",
+		})
+		return
+	}
+
+	identity := middleware.CurrentIdentity(c)
+
+	workspace, err := h.workspaceService.AcceptInvitation(c.Request.Context(), identity.User, req)
+	if respondWorkspaceError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace)
+}
+
+// respondWorkspaceError writes the response for a failed workspace call
+// and reports true if err is non-nil.
+func respondWorkspaceError(c *gin.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+	if respondForbidden(c, err) {
+		return true
+	}
+
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrWorkspaceNotFound),
+		errors.Is(err, service.ErrMemberNotFound),
+		errors.Is(err, service.ErrInvitationNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrAlreadyMember):
+		status = http.StatusConflict
+	case errors.Is(err, service.ErrInvitationExpired):
+		status = http.StatusGone
+	}
+
+	c.JSON(status, dto.ErrorResponse{
+		Error: err.Error(),
+	})
+	return true
+}
+
+// parseWorkspaceID reads the workspace ID from the path, writing a 400
+// response and reporting false if it is malformed.
+func parseWorkspaceID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil || id == uuid.Nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "Invalid workspace ID",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// parseWorkspacePath reads the workspace ID and the named second ID from
+// the path, writing a 400 response if either is malformed.
+func parseWorkspacePath(c *gin.Context, param, invalid string) (id, otherID uuid.UUID, ok bool) {
+	id, ok = parseWorkspaceID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	otherID, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: invalid,
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return id, otherID, true
+}
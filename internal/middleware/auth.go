@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kanutocd/tada/internal/service"
+)
+
+const identityKey = "identity"
+
+// Auth requires a valid bearer access token and stores the caller's
+// identity on the context for CurrentIdentity.
+func Auth(authService service.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		scheme, token, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			c.Header("WWW-Authenticate", `Bearer realm="tada"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "missing bearer token",
+			})
+			return
+		}
+
+		identity, err := authService.Authenticate(c.Request.Context(), strings.TrimSpace(token))
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidToken) {
+				c.Header("WWW-Authenticate", `Bearer realm="tada", error="invalid_token"`)
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+					"error": err.Error(),
+				})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.Set(identityKey, identity)
+		c.Next()
+	}
+}
+
+// CurrentIdentity returns the identity stored by Auth. It must only be used
+// behind that middleware.
+func CurrentIdentity(c *gin.Context) *service.Identity {
+	return c.MustGet(identityKey).(*service.Identity)
+}
+
+// TokenFromQuery copies an access token from the given query parameter into
+// the Authorization header when the header is absent. It exists for clients
+// such as the browser EventSource API that cannot set request headers.
+func TokenFromQuery(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token := c.Query(param); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/service"
+)
+
+// IdempotencyKeyHeader carries the key that makes a request safe to retry.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader marks a response replayed from an earlier
+// request with the same key.
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// maxIdempotencyKeyLength is the longest key the store accepts.
+const maxIdempotencyKeyLength = 255
+
+// Idempotency makes requests sent with an Idempotency-Key header safe to
+// retry. The first request with a key runs and its response is stored; a
+// retry with the same query and body gets that response again, marked by
+// the Idempotent-Replayed header, and one with a different query or body
+// is refused with 422. A retry arriving while the first request is still
+// running waits for it. Server errors are not stored, so a retry after one
+// runs the request again. Keys are kept apart per route, and per caller
+// and workspace behind Auth and Workspace. Bodies over maxBodySize bytes
+// are refused with 413.
+func Idempotency(idempotencyService service.IdempotencyService, maxBodySize int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "Idempotency-Key header is too long",
+			})
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodySize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+					"error": "request body is too large",
+				})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "invalid request body",
+			})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := idempotencyScope(c)
+		claim, err := idempotencyService.Begin(c.Request.Context(), scope, key, requestFingerprint(c, body))
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrIdempotencyKeyReused):
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+					"error": err.Error(),
+				})
+			case errors.Is(err, service.ErrIdempotencyKeyBusy):
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+					"error": err.Error(),
+				})
+			default:
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"error": err.Error(),
+				})
+			}
+			return
+		}
+		if claim.Completed() {
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(claim.Status, claim.ContentType, claim.Body)
+			c.Abort()
+			return
+		}
+
+		// The key is released or the response stored even if the client
+		// has gone away by the time the handler returns.
+		releaseCtx := context.WithoutCancel(c.Request.Context())
+
+		// Hold the key for as long as the handler runs, however long that
+		// is, and stop before storing the response or releasing it.
+		holdCtx, cancelHold := context.WithCancel(releaseCtx)
+		held := make(chan struct{})
+		go func() {
+			defer close(held)
+			if err := idempotencyService.Hold(holdCtx, claim); err != nil {
+				log.Printf("Failed to hold idempotency key: %v", err)
+			}
+		}()
+		stopHolding := func() {
+			cancelHold()
+			<-held
+		}
+
+		// Release the key unless a response is stored, including when the
+		// handler panics.
+		stored := false
+		defer func() {
+			stopHolding()
+			if stored {
+				return
+			}
+			if err := idempotencyService.Abandon(releaseCtx, claim); err != nil {
+				log.Printf("Failed to release idempotency key: %v", err)
+			}
+		}()
+
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		stopHolding()
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+		err = idempotencyService.Finish(releaseCtx, claim, status, writer.Header().Get("Content-Type"), writer.body.Bytes())
+		if err != nil {
+			log.Printf("Failed to store idempotent response: %v", err)
+			return
+		}
+		stored = true
+	}
+}
+
+// idempotencyScope names the route and, when known, the caller and
+// workspace a key belongs to.
+func idempotencyScope(c *gin.Context) string {
+	scope := c.Request.Method + " " + c.FullPath()
+	if identity, ok := c.Get(identityKey); ok {
+		scope += " user:" + identity.(*service.Identity).User.ID.String()
+	}
+	if member, ok := c.Get(membershipKey); ok {
+		scope += " workspace:" + member.(*domain.WorkspaceMember).WorkspaceID.String()
+	}
+	return scope
+}
+
+// requestFingerprint identifies a request by its query and body.
+func requestFingerprint(c *gin.Context, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(c.Request.URL.RawQuery))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// recordingWriter keeps a copy of the response body it writes.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/service"
+)
+
+// WorkspaceHeader names the workspace a request works in.
+const WorkspaceHeader = "X-Workspace-ID"
+
+const membershipKey = "workspace_membership"
+
+// Workspace resolves the workspace named by the X-Workspace-ID header, or
+// the caller's default workspace without one, and stores the caller's
+// membership on the context for CurrentMembership. Workspaces the caller
+// is not a member of are reported as not found. It must run behind Auth.
+func Workspace(workspaceService service.WorkspaceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var workspaceID uuid.UUID
+		if header := c.GetHeader(WorkspaceHeader); header != "" {
+			id, err := uuid.Parse(header)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+					"error": "invalid " + WorkspaceHeader + " header",
+				})
+				return
+			}
+			workspaceID = id
+		}
+
+		member, err := workspaceService.GetMembership(c.Request.Context(), CurrentIdentity(c).User.ID, workspaceID)
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrWorkspaceNotFound):
+				c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+					"error": err.Error(),
+				})
+			case errors.Is(err, service.ErrNoWorkspace):
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"error": err.Error(),
+				})
+			default:
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"error": err.Error(),
+				})
+			}
+			return
+		}
+
+		c.Header(WorkspaceHeader, member.WorkspaceID.String())
+		c.Set(membershipKey, member)
+		c.Next()
+	}
+}
+
+// CurrentMembership returns the membership stored by Workspace. It must
+// only be used behind that middleware.
+func CurrentMembership(c *gin.Context) *domain.WorkspaceMember {
+	return c.MustGet(membershipKey).(*domain.WorkspaceMember)
+}
+
+// RequireWorkspaceRole refuses the request unless the caller's role in the
+// current workspace grants at least role. It must run behind Workspace.
+func RequireWorkspaceRole(role domain.WorkspaceRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !CurrentMembership(c).Role.AtLeast(role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":  "requires the " + string(role) + " role in this workspace",
+				"reason": service.ReasonInsufficientRole,
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// WorkspaceFromQuery copies a workspace ID from the given query parameter
+// into the X-Workspace-ID header when the header is absent, for the same
+// clients as TokenFromQuery.
+func WorkspaceFromQuery(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader(WorkspaceHeader) == "" {
+			if id := c.Query(param); id != "" {
+				c.Request.Header.Set(WorkspaceHeader, id)
+			}
+		}
+		c.Next()
+	}
+}
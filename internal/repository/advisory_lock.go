@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type advisoryLocker struct {
+	db *gorm.DB
+}
+
+func NewAdvisoryLocker(db *gorm.DB) AdvisoryLocker {
+	return &advisoryLocker{db: db}
+}
+
+// TryWithLock runs fn while holding the Postgres advisory lock key, and
+// reports false without running it if another session holds the lock. The
+// lock belongs to a connection set aside for the call, so fn may use any
+// repository.
+func (l *advisoryLocker) TryWithLock(ctx context.Context, key int64, fn func() error) (bool, error) {
+	acquired := false
+	err := l.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.Raw("SELECT pg_try_advisory_lock(?)", key).Scan(&acquired).Error; err != nil {
+			return err
+		}
+		if !acquired {
+			return nil
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", key)
+
+		return fn()
+	})
+	return acquired, err
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/dto"
+)
+
+type attachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+func (r *attachmentRepository) Create(ctx context.Context, attachment *domain.Attachment) error {
+	return dbFor(ctx, r.db).Omit("Uploader").Create(attachment).Error
+}
+
+func (r *attachmentRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Attachment, error) {
+	var attachment domain.Attachment
+	err := dbFor(ctx, r.db).Preload("Uploader").First(&attachment, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *attachmentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return dbFor(ctx, r.db).Delete(&domain.Attachment{}, "id = ?", id).Error
+}
+
+// GetByTadaID lists a tada's attachments, newest first.
+func (r *attachmentRepository) GetByTadaID(
+	ctx context.Context, tadaID uuid.UUID, pagination dto.PaginationQuery,
+) ([]domain.Attachment, string, error) {
+	var attachments []domain.Attachment
+
+	order, err := parseSortOrder("", nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Set default limit
+	if pagination.Limit == 0 {
+		pagination.Limit = 10
+	}
+
+	// Apply cursor pagination
+	query := dbFor(ctx, r.db).Model(&domain.Attachment{}).Preload("Uploader").Where("tada_id = ?", tadaID)
+	query, err = order.applyCursor(query, pagination.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Fetch one extra record to check if there are more pages
+	query = query.Order(order.orderClause()).Limit(pagination.Limit + 1)
+
+	if err := query.Find(&attachments).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to fetch attachments: %w", err)
+	}
+
+	// Generate next cursor
+	var nextCursor string
+	if len(attachments) > pagination.Limit {
+		attachments = attachments[:pagination.Limit] // Remove extra record
+		lastAttachment := attachments[len(attachments)-1]
+		nextCursor = order.nextCursor(lastAttachment.ID, lastAttachment.CreatedAt, nil)
+	}
+
+	return attachments, nextCursor, nil
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/kanutocd/tada/internal/config"
+)
+
+// ErrBlobNotFound is returned when a blob store has nothing under a key.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore keeps file contents outside the database, addressed by keys
+// such as "tadas/<id>/<attachment id>". Keys use "/" as a separator and
+// never start with one.
+type BlobStore interface {
+	Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewBlobStore returns the blob store selected by cfg.Driver.
+func NewBlobStore(cfg config.StorageConfig) (BlobStore, error) {
+	switch cfg.Driver {
+	case "", "local":
+		if cfg.Local.Path == "" {
+			return nil, fmt.Errorf("attachments.storage.local.path must be set")
+		}
+		return NewLocalBlobStore(cfg.Local.Path), nil
+	case "s3":
+		if cfg.S3.Bucket == "" || cfg.S3.Region == "" {
+			return nil, fmt.Errorf("attachments.storage.s3.bucket and attachments.storage.s3.region must be set")
+		}
+		return NewS3BlobStore(cfg.S3, nil)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
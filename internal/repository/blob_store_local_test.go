@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func putBlob(t *testing.T, store BlobStore, key, content string) {
+	t.Helper()
+	err := store.Put(context.Background(), key, strings.NewReader(content), int64(len(content)), "text/plain")
+	if err != nil {
+		t.Fatalf("Put(%q) error = %v", key, err)
+	}
+}
+
+func readBlob(t *testing.T, store BlobStore, key string) string {
+	t.Helper()
+	reader, err := store.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Get(%q) error = %v", key, err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("reading %q: %v", key, err)
+	}
+	return string(content)
+}
+
+func TestLocalBlobStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store := NewLocalBlobStore(root)
+	key := "tadas/1/attachment"
+
+	putBlob(t, store, key, "first")
+	if got := readBlob(t, store, key); got != "first" {
+		t.Errorf("Get() = %q, want %q", got, "first")
+	}
+
+	putBlob(t, store, key, "second")
+	if got := readBlob(t, store, key); got != "second" {
+		t.Errorf("Get() after overwrite = %q, want %q", got, "second")
+	}
+
+	// Only the blob itself is left behind, not the temporary upload files.
+	entries, err := os.ReadDir(filepath.Join(root, "tadas", "1"))
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "attachment" {
+		names := make([]string, len(entries))
+		for i, entry := range entries {
+			names[i] = entry.Name()
+		}
+		t.Errorf("blob directory holds %v, want [attachment]", names)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("Get() after Delete error = %v, want ErrBlobNotFound", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("Delete() of a missing blob error = %v, want nil", err)
+	}
+}
+
+func TestLocalBlobStoreGetMissing(t *testing.T) {
+	store := NewLocalBlobStore(t.TempDir())
+
+	if _, err := store.Get(context.Background(), "tadas/1/missing"); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("Get() error = %v, want ErrBlobNotFound", err)
+	}
+}
+
+func TestLocalBlobStoreRejectsInvalidKeys(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store := NewLocalBlobStore(filepath.Join(root, "blobs"))
+
+	keys := []string{
+		"",
+		"/etc/passwd",
+		"../outside",
+		"tadas/../../outside",
+		"tadas//attachment",
+		"tadas/./attachment",
+		"tadas/",
+	}
+
+	for _, key := range keys {
+		t.Run(key, func(t *testing.T) {
+			if err := store.Put(ctx, key, strings.NewReader("x"), 1, "text/plain"); err == nil {
+				t.Errorf("Put(%q) succeeded, want an error", key)
+			}
+			if _, err := store.Get(ctx, key); err == nil || errors.Is(err, ErrBlobNotFound) {
+				t.Errorf("Get(%q) error = %v, want an invalid key error", key, err)
+			}
+			if err := store.Delete(ctx, key); err == nil {
+				t.Errorf("Delete(%q) succeeded, want an error", key)
+			}
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "outside")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("a blob was written outside the store's root")
+	}
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kanutocd/tada/internal/domain"
+)
+
+// ErrChecklistMismatch is returned when a reorder does not list exactly
+// the items the checklist holds.
+var ErrChecklistMismatch = errors.New("checklist items do not match")
+
+type checklistRepository struct {
+	db *gorm.DB
+}
+
+func NewChecklistRepository(db *gorm.DB) ChecklistRepository {
+	return &checklistRepository{db: db}
+}
+
+// Create appends an item to its tada's checklist. The tada's row is locked
+// while the position is chosen, so concurrent appends get distinct ones.
+func (r *checklistRepository) Create(ctx context.Context, item *domain.ChecklistItem) error {
+	return dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := lockTada(tx, item.TadaID); err != nil {
+			return err
+		}
+
+		var next int
+		err := tx.Model(&domain.ChecklistItem{}).
+			Select("COALESCE(MAX(position) + 1, 0)").
+			Where("tada_id = ?", item.TadaID).
+			Scan(&next).Error
+		if err != nil {
+			return err
+		}
+
+		item.Position = next
+		return tx.Create(item).Error
+	})
+}
+
+func (r *checklistRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.ChecklistItem, error) {
+	var item domain.ChecklistItem
+	err := dbFor(ctx, r.db).First(&item, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// GetByTadaID lists a tada's checklist in order.
+func (r *checklistRepository) GetByTadaID(ctx context.Context, tadaID uuid.UUID) ([]domain.ChecklistItem, error) {
+	var items []domain.ChecklistItem
+	err := dbFor(ctx, r.db).Where("tada_id = ?", tadaID).Order("position ASC, id ASC").Find(&items).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch checklist: %w", err)
+	}
+	return items, nil
+}
+
+func (r *checklistRepository) Update(ctx context.Context, item *domain.ChecklistItem) error {
+	return dbFor(ctx, r.db).Save(item).Error
+}
+
+func (r *checklistRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return dbFor(ctx, r.db).Delete(&domain.ChecklistItem{}, "id = ?", id).Error
+}
+
+// Reorder gives the items of a tada's checklist the order of itemIDs. It
+// returns ErrChecklistMismatch unless itemIDs names every item exactly
+// once, so a client working from a stale list cannot drop or duplicate
+// items. The tada's row is locked throughout, serializing concurrent
+// reorders and appends.
+func (r *checklistRepository) Reorder(ctx context.Context, tadaID uuid.UUID, itemIDs []uuid.UUID) error {
+	return dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := lockTada(tx, tadaID); err != nil {
+			return err
+		}
+
+		var current []uuid.UUID
+		if err := tx.Model(&domain.ChecklistItem{}).Where("tada_id = ?", tadaID).Pluck("id", &current).Error; err != nil {
+			return err
+		}
+
+		if len(current) != len(itemIDs) {
+			return ErrChecklistMismatch
+		}
+		remaining := make(map[uuid.UUID]bool, len(current))
+		for _, id := range current {
+			remaining[id] = true
+		}
+		for _, id := range itemIDs {
+			if !remaining[id] {
+				return ErrChecklistMismatch
+			}
+			delete(remaining, id)
+		}
+
+		for position, id := range itemIDs {
+			err := tx.Model(&domain.ChecklistItem{}).
+				Where("id = ?", id).
+				Update("position", position).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetSummaries counts the checklist items of each of the given tadas.
+// Tadas without a checklist are left out of the result.
+func (r *checklistRepository) GetSummaries(ctx context.Context, tadaIDs []uuid.UUID) (map[uuid.UUID]domain.ChecklistSummary, error) {
+	summaries := make(map[uuid.UUID]domain.ChecklistSummary)
+	if len(tadaIDs) == 0 {
+		return summaries, nil
+	}
+
+	var rows []struct {
+		TadaID uuid.UUID
+		Total  int
+		Done   int
+	}
+	err := dbFor(ctx, r.db).Model(&domain.ChecklistItem{}).
+		Select("tada_id, COUNT(*) AS total, COUNT(*) FILTER (WHERE checked) AS done").
+		Where("tada_id IN ?", tadaIDs).
+		Group("tada_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count checklist items: %w", err)
+	}
+
+	for _, row := range rows {
+		summaries[row.TadaID] = domain.ChecklistSummary{Total: row.Total, Done: row.Done}
+	}
+
+	return summaries, nil
+}
+
+// lockTada takes a row lock on a tada for the rest of the transaction.
+func lockTada(tx *gorm.DB, id uuid.UUID) error {
+	var tada domain.Tada
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&tada, "id = ?", id).Error
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/dto"
+)
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &commentRepository{db: db}
+}
+
+func (r *commentRepository) Create(ctx context.Context, comment *domain.Comment) error {
+	return dbFor(ctx, r.db).Create(comment).Error
+}
+
+func (r *commentRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Comment, error) {
+	var comment domain.Comment
+	err := dbFor(ctx, r.db).Preload("Author").First(&comment, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (r *commentRepository) Update(ctx context.Context, comment *domain.Comment) error {
+	return dbFor(ctx, r.db).Omit("Author").Save(comment).Error
+}
+
+func (r *commentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return dbFor(ctx, r.db).Delete(&domain.Comment{}, "id = ?", id).Error
+}
+
+var commentSortColumns = map[string]sortColumn{
+	"created_at": {name: "created_at", isTime: true},
+}
+
+// GetByTadaID lists a tada's comments, oldest first.
+func (r *commentRepository) GetByTadaID(
+	ctx context.Context, tadaID uuid.UUID, pagination dto.PaginationQuery,
+) ([]domain.Comment, string, error) {
+	var comments []domain.Comment
+
+	order, err := parseSortOrder("created_at", commentSortColumns)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Set default limit
+	if pagination.Limit == 0 {
+		pagination.Limit = 10
+	}
+
+	// Apply cursor pagination
+	query := dbFor(ctx, r.db).Model(&domain.Comment{}).Preload("Author").Where("tada_id = ?", tadaID)
+	query, err = order.applyCursor(query, pagination.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Fetch one extra record to check if there are more pages
+	query = query.Order(order.orderClause()).Limit(pagination.Limit + 1)
+
+	if err := query.Find(&comments).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to fetch comments: %w", err)
+	}
+
+	// Generate next cursor
+	var nextCursor string
+	if len(comments) > pagination.Limit {
+		comments = comments[:pagination.Limit] // Remove extra record
+		lastComment := comments[len(comments)-1]
+		nextCursor = order.nextCursor(lastComment.ID, lastComment.CreatedAt, timeSortValue(&lastComment.CreatedAt))
+	}
+
+	return comments, nextCursor, nil
+}
+
+// CountByTadaIDs counts the comments on each of the given tadas. Tadas
+// without comments are left out of the result.
+func (r *commentRepository) CountByTadaIDs(ctx context.Context, tadaIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	counts := make(map[uuid.UUID]int)
+	if len(tadaIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		TadaID uuid.UUID
+		Count  int
+	}
+	err := dbFor(ctx, r.db).Model(&domain.Comment{}).
+		Select("tada_id, COUNT(*) AS count").
+		Where("tada_id IN ?", tadaIDs).
+		Group("tada_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count comments: %w", err)
+	}
+
+	for _, row := range rows {
+		counts[row.TadaID] = row.Count
+	}
+
+	return counts, nil
+}
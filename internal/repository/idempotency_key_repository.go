@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kanutocd/tada/internal/domain"
+)
+
+type idempotencyKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyKeyRepository(db *gorm.DB) IdempotencyKeyRepository {
+	return &idempotencyKeyRepository{db: db}
+}
+
+func (r *idempotencyKeyRepository) Claim(ctx context.Context, key *domain.IdempotencyKey, now time.Time) (bool, error) {
+	result := dbFor(ctx, r.db).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "scope"}, {Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"fingerprint", "status", "content_type", "body", "token", "locked_until", "expires_at", "created_at",
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{clause.Or(
+			clause.Expr{SQL: "idempotency_keys.expires_at <= ?", Vars: []interface{}{now}},
+			clause.Expr{
+				SQL: "idempotency_keys.status = 0 AND idempotency_keys.locked_until <= ? AND " +
+					"idempotency_keys.fingerprint = excluded.fingerprint",
+				Vars: []interface{}{now},
+			},
+		)}},
+	}).Create(key)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *idempotencyKeyRepository) Get(ctx context.Context, scope, key string) (*domain.IdempotencyKey, error) {
+	var record domain.IdempotencyKey
+	err := dbFor(ctx, r.db).First(&record, "scope = ? AND key = ?", scope, key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Extend moves the lock of the key's claim on to key.LockedUntil. It
+// reports false if the claim, named by its token, no longer holds the key.
+func (r *idempotencyKeyRepository) Extend(ctx context.Context, key *domain.IdempotencyKey) (bool, error) {
+	result := dbFor(ctx, r.db).Model(&domain.IdempotencyKey{}).
+		Scopes(heldBy(key)).
+		Update("locked_until", key.LockedUntil)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Complete stores the response to the key's request. It reports false if
+// the claim, named by its token, no longer holds the key.
+func (r *idempotencyKeyRepository) Complete(ctx context.Context, key *domain.IdempotencyKey) (bool, error) {
+	result := dbFor(ctx, r.db).Model(&domain.IdempotencyKey{}).
+		Scopes(heldBy(key)).
+		Updates(map[string]interface{}{
+			"status":       key.Status,
+			"content_type": key.ContentType,
+			"body":         key.Body,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Delete releases the key if the claim, named by its token, still holds
+// it.
+func (r *idempotencyKeyRepository) Delete(ctx context.Context, key *domain.IdempotencyKey) error {
+	return dbFor(ctx, r.db).Scopes(heldBy(key)).Delete(&domain.IdempotencyKey{}).Error
+}
+
+// DeleteExpired removes the records that expired by now and returns how
+// many there were.
+func (r *idempotencyKeyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := dbFor(ctx, r.db).Delete(&domain.IdempotencyKey{}, "expires_at <= ?", now)
+	return result.RowsAffected, result.Error
+}
+
+// heldBy restricts a query on idempotency keys to the key's record while
+// the claim it carries the token of still holds it.
+func heldBy(key *domain.IdempotencyKey) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("scope = ? AND key = ? AND token = ? AND status = 0", key.Scope, key.Key, key.Token)
+	}
+}
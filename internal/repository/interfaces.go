@@ -1,26 +1,179 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/kanutocd/tada/internal/domain"
 	"github.com/kanutocd/tada/internal/dto"
 )
 
 type UserRepository interface {
-	Create(user *domain.User) error
-	GetByID(id uuid.UUID) (*domain.User, error)
-	GetByEmail(email string) (*domain.User, error)
-	Update(user *domain.User) error
-	Delete(id uuid.UUID) error
-	GetAll(pagination dto.PaginationQuery) ([]domain.User, string, error)
+	Create(ctx context.Context, user *domain.User) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error)
+	GetMember(ctx context.Context, workspaceID, id uuid.UUID) (*domain.User, error)
+	GetByEmail(ctx context.Context, email string) (*domain.User, error)
+	GetMemberByEmail(ctx context.Context, workspaceID uuid.UUID, email string) (*domain.User, error)
+	Update(ctx context.Context, user *domain.User) error
+	Delete(ctx context.Context, id uuid.UUID, version int) error
+	GetMembers(ctx context.Context, workspaceID uuid.UUID, pagination dto.PaginationQuery) ([]domain.User, string, error)
 }
 
+// TadaRepository reads and writes tadas. InWorkspace returns a repository
+// confined to one workspace: it reads only that workspace's tadas, creates
+// tadas in it and cannot change any other. A repository that has not been
+// confined sees no tadas at all.
 type TadaRepository interface {
-	Create(tada *domain.Tada) error
-	GetByID(id uuid.UUID) (*domain.Tada, error)
-	Update(tada *domain.Tada) error
-	Delete(id uuid.UUID) error
-	GetAll(pagination dto.PaginationQuery) ([]domain.Tada, string, error)
-	GetByUserID(userID uuid.UUID, pagination dto.PaginationQuery) ([]domain.Tada, string, error)
-	GetByAssigneeID(assigneeID uuid.UUID, pagination dto.PaginationQuery) ([]domain.Tada, string, error)
+	InWorkspace(workspaceID uuid.UUID) TadaRepository
+	Create(ctx context.Context, tada *domain.Tada, actorID uuid.UUID) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Tada, error)
+	GetByIDs(ctx context.Context, ids []uuid.UUID) ([]domain.Tada, error)
+	Update(ctx context.Context, tada *domain.Tada, actorID uuid.UUID) error
+	UpdateAll(ctx context.Context, tadas []*domain.Tada, actorID uuid.UUID) error
+	Delete(ctx context.Context, id uuid.UUID, version int, actorID uuid.UUID) error
+	GetAll(ctx context.Context, filter dto.TadaFilter, pagination dto.PaginationQuery) ([]domain.Tada, string, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID, pagination dto.PaginationQuery) ([]domain.Tada, string, error)
+	GetByAssigneeID(ctx context.Context, assigneeID uuid.UUID, pagination dto.PaginationQuery) ([]domain.Tada, string, error)
+	GetByParticipantID(ctx context.Context, userID uuid.UUID, pagination dto.PaginationQuery) ([]domain.Tada, string, error)
+	GetHistory(ctx context.Context, tadaID uuid.UUID, pagination dto.PaginationQuery) ([]domain.TadaEvent, string, error)
+	GetOccurrence(ctx context.Context, seriesID uuid.UUID, occurrence int) (*domain.Tada, error)
+	GetByParentID(ctx context.Context, parentID uuid.UUID, pagination dto.PaginationQuery) ([]domain.Tada, string, error)
+	GetDescendants(ctx context.Context, id uuid.UUID, maxDepth int) ([]domain.Tada, error)
+	GetAncestorIDs(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error)
+	GetProgress(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]domain.TadaProgress, error)
+}
+
+type TadaDependencyRepository interface {
+	Create(ctx context.Context, dependency *domain.TadaDependency) error
+	Get(ctx context.Context, blockerID, blockedID uuid.UUID) (*domain.TadaDependency, error)
+	Delete(ctx context.Context, blockerID, blockedID uuid.UUID) error
+	HasPath(ctx context.Context, from, to uuid.UUID) (bool, error)
+	GetBlockers(ctx context.Context, blockedIDs []uuid.UUID) ([]domain.TadaBlocker, error)
+}
+
+type CommentRepository interface {
+	Create(ctx context.Context, comment *domain.Comment) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Comment, error)
+	Update(ctx context.Context, comment *domain.Comment) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetByTadaID(ctx context.Context, tadaID uuid.UUID, pagination dto.PaginationQuery) ([]domain.Comment, string, error)
+	CountByTadaIDs(ctx context.Context, tadaIDs []uuid.UUID) (map[uuid.UUID]int, error)
+}
+
+type WorkspaceRepository interface {
+	Create(ctx context.Context, workspace *domain.Workspace, ownerID uuid.UUID) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Workspace, error)
+	Update(ctx context.Context, workspace *domain.Workspace) error
+	GetMember(ctx context.Context, workspaceID, userID uuid.UUID) (*domain.WorkspaceMember, error)
+	GetDefaultMember(ctx context.Context, userID uuid.UUID) (*domain.WorkspaceMember, error)
+	GetMembers(ctx context.Context, workspaceID uuid.UUID, pagination dto.PaginationQuery) ([]domain.WorkspaceMember, string, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID, pagination dto.PaginationQuery) ([]domain.WorkspaceMember, string, error)
+	UpdateMember(ctx context.Context, member *domain.WorkspaceMember) error
+	RemoveMember(ctx context.Context, workspaceID, userID uuid.UUID) error
+	CreateInvitation(ctx context.Context, invitation *domain.WorkspaceInvitation) error
+	GetInvitationByID(ctx context.Context, id uuid.UUID) (*domain.WorkspaceInvitation, error)
+	GetInvitationByTokenHash(ctx context.Context, hash string) (*domain.WorkspaceInvitation, error)
+	GetPendingInvitations(
+		ctx context.Context, workspaceID uuid.UUID, now time.Time, pagination dto.PaginationQuery,
+	) ([]domain.WorkspaceInvitation, string, error)
+	DeleteInvitation(ctx context.Context, id uuid.UUID) error
+	AcceptInvitation(ctx context.Context, invitation *domain.WorkspaceInvitation, member *domain.WorkspaceMember) error
+}
+
+// ProjectRepository reads and writes projects. Like TadaRepository, it
+// only sees the projects of the workspace it was confined to.
+type ProjectRepository interface {
+	InWorkspace(workspaceID uuid.UUID) ProjectRepository
+	Create(ctx context.Context, project *domain.Project) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Project, error)
+	Update(ctx context.Context, project *domain.Project) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetAll(ctx context.Context, filter dto.ProjectFilter, pagination dto.PaginationQuery) ([]domain.Project, string, error)
+	CountTadas(ctx context.Context, id uuid.UUID) (int64, error)
+	GetStatuses(ctx context.Context, id uuid.UUID) ([]domain.TadaStatus, error)
+	GetStats(ctx context.Context, id uuid.UUID, now time.Time) (*domain.ProjectStats, error)
+}
+
+type ChecklistRepository interface {
+	Create(ctx context.Context, item *domain.ChecklistItem) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.ChecklistItem, error)
+	GetByTadaID(ctx context.Context, tadaID uuid.UUID) ([]domain.ChecklistItem, error)
+	Update(ctx context.Context, item *domain.ChecklistItem) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	Reorder(ctx context.Context, tadaID uuid.UUID, itemIDs []uuid.UUID) error
+	GetSummaries(ctx context.Context, tadaIDs []uuid.UUID) (map[uuid.UUID]domain.ChecklistSummary, error)
+}
+
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *domain.Attachment) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Attachment, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetByTadaID(ctx context.Context, tadaID uuid.UUID, pagination dto.PaginationQuery) ([]domain.Attachment, string, error)
+}
+
+type TagRepository interface {
+	InWorkspace(workspaceID uuid.UUID) TagRepository
+	Create(ctx context.Context, tag *domain.Tag) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Tag, error)
+	GetByName(ctx context.Context, name string) (*domain.Tag, error)
+	Update(ctx context.Context, tag *domain.Tag) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetAll(ctx context.Context, pagination dto.PaginationQuery) ([]domain.Tag, string, error)
+	FindOrCreate(ctx context.Context, names []string) ([]domain.Tag, error)
+}
+
+type ReminderRepository interface {
+	Create(ctx context.Context, reminder *domain.Reminder) error
+	RecordFailure(ctx context.Context, reminder *domain.Reminder, maxAttempts int) error
+	GetUnreminded(ctx context.Context, kind domain.ReminderKind, from, to, now time.Time, limit int) ([]domain.Tada, error)
+}
+
+// AdvisoryLocker runs work under a database-wide lock, so that only one
+// replica does it at a time.
+type AdvisoryLocker interface {
+	TryWithLock(ctx context.Context, key int64, fn func() error) (bool, error)
+}
+
+// IdempotencyKeyRepository stores Idempotency-Key records. Claim inserts
+// a record unless one is held for the key; it takes over an expired
+// record, or one whose request has held it past LockedUntil without
+// completing and matches its fingerprint. It reports whether the caller
+// now holds the key.
+type IdempotencyKeyRepository interface {
+	Claim(ctx context.Context, key *domain.IdempotencyKey, now time.Time) (bool, error)
+	Get(ctx context.Context, scope, key string) (*domain.IdempotencyKey, error)
+	Extend(ctx context.Context, key *domain.IdempotencyKey) (bool, error)
+	Complete(ctx context.Context, key *domain.IdempotencyKey) (bool, error)
+	Delete(ctx context.Context, key *domain.IdempotencyKey) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *domain.Session) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Session, error)
+	GetByRefreshTokenHash(ctx context.Context, hash string) (*domain.Session, error)
+	Update(ctx context.Context, session *domain.Session) error
+	GetByRetiredRefreshTokenHash(ctx context.Context, hash string) (*domain.Session, error)
+	Rotate(ctx context.Context, session *domain.Session, previousHash string) (bool, error)
+	Revoke(ctx context.Context, id uuid.UUID, now time.Time) error
+	RevokeOthers(ctx context.Context, userID, keepID uuid.UUID, now time.Time) error
+}
+
+// WebhookRepository reads and writes webhooks and their deliveries. Like
+// TadaRepository, its webhook reads and writes only see the workspace it
+// was confined to; GetSubscribed and the delivery methods serve the
+// background dispatcher and are not confined.
+type WebhookRepository interface {
+	InWorkspace(workspaceID uuid.UUID) WebhookRepository
+	Create(ctx context.Context, webhook *domain.Webhook) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Webhook, error)
+	Update(ctx context.Context, webhook *domain.Webhook) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetByOwnerID(ctx context.Context, ownerID uuid.UUID, pagination dto.PaginationQuery) ([]domain.Webhook, string, error)
+	GetSubscribed(ctx context.Context, changeType domain.TadaChangeType, workspaceID uuid.UUID) ([]domain.Webhook, error)
+	CreateDeliveries(ctx context.Context, deliveries []domain.WebhookDelivery) error
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	GetDeliveries(ctx context.Context, webhookID uuid.UUID, pagination dto.PaginationQuery) ([]domain.WebhookDelivery, string, error)
 }
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kanutocd/tada/internal/dto"
+)
+
+// sortColumn describes a column a listing can be ordered by. The name may
+// also be an SQL expression over the row. Nullable columns always sort their
+// NULLs last, whatever the direction.
+type sortColumn struct {
+	name     string
+	nullable bool
+	isTime   bool
+	isInt    bool
+	isFloat  bool
+}
+
+// sortOrder is a parsed sort parameter such as "-due_at". Rows are ordered
+// by the column and then by id in the same direction, so the pair is unique
+// and can drive keyset pagination.
+type sortOrder struct {
+	key    string
+	column sortColumn
+	desc   bool
+}
+
+// defaultSortKey is the ordering listings have always used. Cursors issued
+// for it leave Sort and Value empty and rely on Cursor.CreatedAt instead.
+const defaultSortKey = "-created_at"
+
+// parseSortOrder resolves a sort key against the columns a listing allows.
+// An empty key selects the default ordering, which every listing supports.
+func parseSortOrder(key string, columns map[string]sortColumn) (sortOrder, error) {
+	if key == "" || key == defaultSortKey {
+		return sortOrder{
+			key:    defaultSortKey,
+			column: sortColumn{name: "created_at", isTime: true},
+			desc:   true,
+		}, nil
+	}
+
+	name := strings.TrimPrefix(key, "-")
+	column, ok := columns[name]
+	if !ok {
+		return sortOrder{}, fmt.Errorf("%w: %q", dto.ErrInvalidSort, key)
+	}
+
+	return sortOrder{key: key, column: column, desc: strings.HasPrefix(key, "-")}, nil
+}
+
+func (o sortOrder) isDefault() bool {
+	return o.key == defaultSortKey
+}
+
+func (o sortOrder) orderClause() string {
+	direction := "ASC"
+	if o.desc {
+		direction = "DESC"
+	}
+
+	nulls := ""
+	if o.column.nullable {
+		nulls = " NULLS LAST"
+	}
+
+	return fmt.Sprintf("%s %s%s, id %s", o.column.name, direction, nulls, direction)
+}
+
+// applyCursor restricts query to the rows that follow the encoded cursor
+// under this ordering.
+func (o sortOrder) applyCursor(query *gorm.DB, encoded string) (*gorm.DB, error) {
+	if encoded == "" {
+		return query, nil
+	}
+
+	cursor, err := dto.DecodeCursor(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", dto.ErrInvalidCursor, err)
+	}
+
+	if _, err := uuid.Parse(cursor.ID); err != nil {
+		return nil, fmt.Errorf("%w: %v", dto.ErrInvalidCursor, err)
+	}
+
+	cmp := ">"
+	if o.desc {
+		cmp = "<"
+	}
+
+	if o.isDefault() {
+		if cursor.Sort != "" {
+			return nil, fmt.Errorf("%w: issued for sort %q", dto.ErrInvalidCursor, cursor.Sort)
+		}
+
+		return query.Where("(created_at < ? OR (created_at = ? AND id < ?))",
+			cursor.CreatedAt, cursor.CreatedAt, cursor.ID), nil
+	}
+
+	if cursor.Sort != o.key {
+		return nil, fmt.Errorf("%w: issued for sort %q", dto.ErrInvalidCursor, cursor.Sort)
+	}
+
+	col := o.column.name
+	if cursor.Value == nil {
+		if !o.column.nullable {
+			return nil, fmt.Errorf("%w: missing sort value", dto.ErrInvalidCursor)
+		}
+
+		// NULLs come last, so only the remaining NULL rows are left.
+		return query.Where(fmt.Sprintf("(%s IS NULL AND id %s ?)", col, cmp), cursor.ID), nil
+	}
+
+	var value interface{} = *cursor.Value
+	if o.column.isTime {
+		t, err := time.Parse(time.RFC3339Nano, *cursor.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", dto.ErrInvalidCursor, err)
+		}
+		value = t
+	}
+	if o.column.isInt {
+		n, err := strconv.Atoi(*cursor.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", dto.ErrInvalidCursor, err)
+		}
+		value = n
+	}
+	if o.column.isFloat {
+		f, err := strconv.ParseFloat(*cursor.Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", dto.ErrInvalidCursor, err)
+		}
+		value = f
+	}
+
+	clause := fmt.Sprintf("(%s %s ? OR (%s = ? AND id %s ?)", col, cmp, col, cmp)
+	if o.column.nullable {
+		clause += fmt.Sprintf(" OR %s IS NULL", col)
+	}
+	clause += ")"
+
+	return query.Where(clause, value, value, cursor.ID), nil
+}
+
+// nextCursor encodes the position of the last row of a page. value is the
+// row's value for the sort column, or nil if it has none.
+func (o sortOrder) nextCursor(id uuid.UUID, createdAt time.Time, value *string) string {
+	cursor := dto.Cursor{
+		ID:        id.String(),
+		CreatedAt: createdAt,
+	}
+
+	if !o.isDefault() {
+		cursor.Sort = o.key
+		cursor.Value = value
+	}
+
+	return dto.EncodeCursor(cursor)
+}
+
+func intSortValue(n int) *string {
+	value := strconv.Itoa(n)
+	return &value
+}
+
+func floatSortValue(f float64) *string {
+	value := strconv.FormatFloat(f, 'g', -1, 64)
+	return &value
+}
+
+func timeSortValue(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+
+	value := t.UTC().Format(time.RFC3339Nano)
+	return &value
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/dto"
+)
+
+type projectRepository struct {
+	db          *gorm.DB
+	workspaceID uuid.UUID
+}
+
+func NewProjectRepository(db *gorm.DB) ProjectRepository {
+	return &projectRepository{db: db}
+}
+
+func (r *projectRepository) InWorkspace(workspaceID uuid.UUID) ProjectRepository {
+	return &projectRepository{db: r.db, workspaceID: workspaceID}
+}
+
+// inWorkspace restricts a query on projects to the repository's workspace.
+func (r *projectRepository) inWorkspace(db *gorm.DB) *gorm.DB {
+	return db.Where("projects.workspace_id = ?", r.workspaceID)
+}
+
+// Create inserts a project into the repository's workspace.
+func (r *projectRepository) Create(ctx context.Context, project *domain.Project) error {
+	project.WorkspaceID = r.workspaceID
+	return dbFor(ctx, r.db).Omit("Owner").Create(project).Error
+}
+
+func (r *projectRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Project, error) {
+	var project domain.Project
+	err := dbFor(ctx, r.db).Scopes(r.inWorkspace).Preload("Owner").First(&project, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// Update saves a project and brings its tadas' status categories, and with
+// them their completion times, in line with its workflow. Tadas changed this
+// way move on to their next version.
+func (r *projectRepository) Update(ctx context.Context, project *domain.Project) error {
+	return dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Owner").Save(project).Error; err != nil {
+			return err
+		}
+
+		category := "CASE status"
+		var args []interface{}
+		for _, state := range project.Workflow.Effective().States {
+			category += " WHEN ? THEN ?"
+			args = append(args, state.Name, state.Category)
+		}
+		category += " ELSE status_category END"
+
+		completedArgs := append(append([]interface{}{}, args...), domain.CategoryCompleted)
+		return tx.Model(&domain.Tada{}).
+			Where("project_id = ?", project.ID).
+			Where("status_category <> "+category, args...).
+			UpdateColumns(map[string]interface{}{
+				"status_category": gorm.Expr(category, args...),
+				"completed_at": gorm.Expr(
+					"CASE WHEN "+category+" = ? THEN COALESCE(completed_at, NOW()) ELSE NULL END", completedArgs...,
+				),
+				"version": gorm.Expr("version + 1"),
+			}).Error
+	})
+}
+
+func (r *projectRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return dbFor(ctx, r.db).Scopes(r.inWorkspace).Delete(&domain.Project{}, "id = ?", id).Error
+}
+
+// GetAll lists either the archived projects or those in use, newest first.
+func (r *projectRepository) GetAll(
+	ctx context.Context, filter dto.ProjectFilter, pagination dto.PaginationQuery,
+) ([]domain.Project, string, error) {
+	var projects []domain.Project
+
+	order, err := parseSortOrder("", nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Set default limit
+	if pagination.Limit == 0 {
+		pagination.Limit = 10
+	}
+
+	// Apply cursor pagination
+	query := dbFor(ctx, r.db).Model(&domain.Project{}).Scopes(r.inWorkspace).Preload("Owner").Where("archived = ?", filter.Archived)
+	query, err = order.applyCursor(query, pagination.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Fetch one extra record to check if there are more pages
+	query = query.Order(order.orderClause()).Limit(pagination.Limit + 1)
+
+	if err := query.Find(&projects).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to fetch projects: %w", err)
+	}
+
+	// Generate next cursor
+	var nextCursor string
+	if len(projects) > pagination.Limit {
+		projects = projects[:pagination.Limit] // Remove extra record
+		lastProject := projects[len(projects)-1]
+		nextCursor = order.nextCursor(lastProject.ID, lastProject.CreatedAt, nil)
+	}
+
+	return projects, nextCursor, nil
+}
+
+// CountTadas counts the tadas in a project.
+func (r *projectRepository) CountTadas(ctx context.Context, id uuid.UUID) (int64, error) {
+	var count int64
+	err := dbFor(ctx, r.db).Model(&domain.Tada{}).Where("project_id = ? AND workspace_id = ?", id, r.workspaceID).Count(&count).Error
+	return count, err
+}
+
+// GetStatuses lists the distinct statuses of a project's tadas.
+func (r *projectRepository) GetStatuses(ctx context.Context, id uuid.UUID) ([]domain.TadaStatus, error) {
+	var statuses []domain.TadaStatus
+	err := dbFor(ctx, r.db).Model(&domain.Tada{}).
+		Where("project_id = ? AND workspace_id = ?", id, r.workspaceID).
+		Distinct().Pluck("status", &statuses).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project statuses: %w", err)
+	}
+	return statuses, nil
+}
+
+// GetStats counts a project's tadas by status category, and the open ones
+// that were due before now.
+func (r *projectRepository) GetStats(ctx context.Context, id uuid.UUID, now time.Time) (*domain.ProjectStats, error) {
+	var stats domain.ProjectStats
+	err := dbFor(ctx, r.db).Model(&domain.Tada{}).
+		Select(
+			"COUNT(*) FILTER (WHERE status_category = ?) AS open, "+
+				"COUNT(*) FILTER (WHERE status_category = ?) AS completed, "+
+				"COUNT(*) FILTER (WHERE status_category = ?) AS cancelled, "+
+				"COUNT(*) FILTER (WHERE status_category = ? AND due_at < ?) AS overdue",
+			domain.CategoryOpen, domain.CategoryCompleted, domain.CategoryCancelled, domain.CategoryOpen, now,
+		).
+		Where("project_id = ? AND workspace_id = ?", id, r.workspaceID).
+		Scan(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count project tadas: %w", err)
+	}
+	return &stats, nil
+}
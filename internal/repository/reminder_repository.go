@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kanutocd/tada/internal/domain"
+)
+
+type reminderRepository struct {
+	db *gorm.DB
+}
+
+func NewReminderRepository(db *gorm.DB) ReminderRepository {
+	return &reminderRepository{db: db}
+}
+
+// reminderKey is the unique key of a reminder: one per tada, kind and due
+// time.
+var reminderKey = []clause.Column{{Name: "tada_id"}, {Name: "kind"}, {Name: "due_at"}}
+
+// unsentReminder limits a conflicting insert to reminders not yet sent.
+var unsentReminder = clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "reminders.sent_at IS NULL"}}}
+
+// Create records a sent reminder. A reminder already sent for the same
+// tada, kind and due time is left as is; one that failed before is marked
+// sent.
+func (r *reminderRepository) Create(ctx context.Context, reminder *domain.Reminder) error {
+	return dbFor(ctx, r.db).Clauses(clause.OnConflict{
+		Columns: reminderKey,
+		Where:   unsentReminder,
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"recipient_id":    gorm.Expr("excluded.recipient_id"),
+			"sent_at":         gorm.Expr("excluded.sent_at"),
+			"attempts":        gorm.Expr("reminders.attempts + 1"),
+			"next_attempt_at": nil,
+		}),
+	}).Create(reminder).Error
+}
+
+// RecordFailure records a failed attempt to send a reminder, which is tried
+// again at its NextAttemptAt. After maxAttempts failures for the same tada,
+// kind and due time, the reminder is given up.
+func (r *reminderRepository) RecordFailure(ctx context.Context, reminder *domain.Reminder, maxAttempts int) error {
+	if reminder.Attempts >= maxAttempts {
+		reminder.NextAttemptAt = nil
+	}
+	return dbFor(ctx, r.db).Clauses(clause.OnConflict{
+		Columns: reminderKey,
+		Where:   unsentReminder,
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"recipient_id":    gorm.Expr("excluded.recipient_id"),
+			"attempts":        gorm.Expr("reminders.attempts + 1"),
+			"last_error":      gorm.Expr("excluded.last_error"),
+			"next_attempt_at": gorm.Expr("CASE WHEN reminders.attempts + 1 < ? THEN excluded.next_attempt_at END", maxAttempts),
+		}),
+	}).Create(reminder).Error
+}
+
+// GetUnreminded returns open tadas due in [from, to) that have no reminder
+// of the given kind for their current due time, earliest first. A tada
+// whose reminder failed is returned again once its next attempt is due at
+// now.
+func (r *reminderRepository) GetUnreminded(
+	ctx context.Context, kind domain.ReminderKind, from, to, now time.Time, limit int,
+) ([]domain.Tada, error) {
+	var tadas []domain.Tada
+
+	// Sent and given-up reminders have no next attempt.
+	settled := dbFor(ctx, r.db).Model(&domain.Reminder{}).
+		Select("1").
+		Where("reminders.tada_id = tadas.id AND reminders.kind = ? AND reminders.due_at = tadas.due_at", kind).
+		Where("reminders.next_attempt_at IS NULL OR reminders.next_attempt_at > ?", now)
+
+	err := dbFor(ctx, r.db).Model(&domain.Tada{}).
+		Preload("Creator").Preload("Assignee").
+		Where("status_category = ?", domain.CategoryOpen).
+		Where("due_at >= ? AND due_at < ?", from, to).
+		Where("NOT EXISTS (?)", settled).
+		Order("due_at, id").
+		Limit(limit).
+		Find(&tadas).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tadas to remind: %w", err)
+	}
+
+	return tadas, nil
+}
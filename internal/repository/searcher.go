@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/dto"
+)
+
+// Searcher runs full-text searches over tadas and their comments. Hits
+// come best match first, and only tadas that match the listing filter, or
+// comments on them, are returned. The filter's Sort is ignored.
+type Searcher interface {
+	// InWorkspace returns the searcher confined to a workspace's tadas. It
+	// finds nothing until it is confined.
+	InWorkspace(workspaceID uuid.UUID) Searcher
+	Search(ctx context.Context, query string, filter dto.TadaFilter, pagination dto.PaginationQuery) ([]domain.SearchHit, string, error)
+}
+
+// searchSortKey orders hits by descending rank, and then by descending ID
+// so that ties page deterministically.
+const searchSortKey = "-rank"
+
+var searchSortColumns = map[string]sortColumn{
+	"rank": {name: "rank", isFloat: true},
+}
@@ -0,0 +1,313 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/dto"
+)
+
+// Weights the in-memory searcher gives each matched word, by where it is
+// found, mirroring the A and B weights of the tada search vector.
+const (
+	memoryNameWeight = 1.0
+	memoryTextWeight = 0.4
+)
+
+// memorySnippetWords is how many words of context a snippet keeps around
+// the first match.
+const memorySnippetWords = 8
+
+type memorySearchStore struct {
+	mu       sync.RWMutex
+	tadas    map[uuid.UUID]domain.Tada
+	comments map[uuid.UUID]domain.Comment
+}
+
+// MemorySearcher is a Searcher over tadas and comments held in memory, for
+// tests that run without Postgres. A hit must contain every word of the
+// query, compared case-insensitively; there is no stemming and no query
+// syntax. Of the listing filter it honours Status, StatusCategory,
+// CreatedBy, AssignedTo, Unassigned, SeriesID, ProjectID, the due and
+// completed ranges and Tag, going by the tags set on the stored tadas. It
+// knows nothing of blockers or archived projects, so it ignores Blocked and
+// IncludeArchived.
+type MemorySearcher struct {
+	store       *memorySearchStore
+	workspaceID uuid.UUID
+}
+
+func NewMemorySearcher() *MemorySearcher {
+	return &MemorySearcher{store: &memorySearchStore{
+		tadas:    make(map[uuid.UUID]domain.Tada),
+		comments: make(map[uuid.UUID]domain.Comment),
+	}}
+}
+
+// InWorkspace returns a view of the same contents confined to a workspace.
+func (s *MemorySearcher) InWorkspace(workspaceID uuid.UUID) Searcher {
+	return &MemorySearcher{store: s.store, workspaceID: workspaceID}
+}
+
+// PutTada adds a tada or replaces the stored copy.
+func (s *MemorySearcher) PutTada(tada domain.Tada) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	s.store.tadas[tada.ID] = tada
+}
+
+// PutComment adds a comment or replaces the stored copy.
+func (s *MemorySearcher) PutComment(comment domain.Comment) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	s.store.comments[comment.ID] = comment
+}
+
+// Remove forgets the tada or comment with the given ID. A removed tada's
+// comments are no longer found either.
+func (s *MemorySearcher) Remove(id uuid.UUID) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	delete(s.store.tadas, id)
+	delete(s.store.comments, id)
+}
+
+func (s *MemorySearcher) Search(
+	ctx context.Context, query string, filter dto.TadaFilter, pagination dto.PaginationQuery,
+) ([]domain.SearchHit, string, error) {
+	order, err := parseSortOrder(searchSortKey, searchSortColumns)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Set default limit
+	if pagination.Limit == 0 {
+		pagination.Limit = 10
+	}
+
+	hits := s.match(searchWords(query), filter)
+	sort.Slice(hits, func(i, j int) bool {
+		return searchHitBefore(hits[i].Rank, hits[i].ID, hits[j].Rank, hits[j].ID)
+	})
+
+	// Apply cursor pagination
+	if pagination.Cursor != "" {
+		rank, id, err := decodeMemorySearchCursor(order, pagination.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		start := sort.Search(len(hits), func(i int) bool {
+			return searchHitBefore(rank, id, hits[i].Rank, hits[i].ID)
+		})
+		hits = hits[start:]
+	}
+
+	// Generate next cursor
+	var nextCursor string
+	if len(hits) > pagination.Limit {
+		hits = hits[:pagination.Limit]
+		lastHit := hits[len(hits)-1]
+		nextCursor = order.nextCursor(lastHit.ID, lastHit.CreatedAt, floatSortValue(lastHit.Rank))
+	}
+
+	return hits, nextCursor, nil
+}
+
+func (s *MemorySearcher) match(terms []string, filter dto.TadaFilter) []domain.SearchHit {
+	hits := []domain.SearchHit{}
+	if len(terms) == 0 {
+		return hits
+	}
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	for _, tada := range s.store.tadas {
+		if tada.WorkspaceID != s.workspaceID || !memoryFilterMatches(tada, filter) {
+			continue
+		}
+		name, description := searchWords(tada.Name), searchWords(tada.Description)
+		if !containsAllWords(append(name, description...), terms) {
+			continue
+		}
+		hits = append(hits, domain.SearchHit{
+			Kind:      domain.SearchHitTada,
+			ID:        tada.ID,
+			TadaID:    tada.ID,
+			Rank:      memoryRank(name, terms, memoryNameWeight) + memoryRank(description, terms, memoryTextWeight),
+			Snippet:   memorySnippet(strings.TrimSpace(tada.Name+" "+tada.Description), terms),
+			CreatedAt: tada.CreatedAt,
+		})
+	}
+
+	for _, comment := range s.store.comments {
+		tada, ok := s.store.tadas[comment.TadaID]
+		if !ok || tada.WorkspaceID != s.workspaceID || !memoryFilterMatches(tada, filter) {
+			continue
+		}
+		body := searchWords(comment.Body)
+		if !containsAllWords(body, terms) {
+			continue
+		}
+		hits = append(hits, domain.SearchHit{
+			Kind:      domain.SearchHitComment,
+			ID:        comment.ID,
+			TadaID:    comment.TadaID,
+			Rank:      memoryRank(body, terms, memoryTextWeight),
+			Snippet:   memorySnippet(comment.Body, terms),
+			CreatedAt: comment.CreatedAt,
+		})
+	}
+
+	return hits
+}
+
+// searchHitBefore reports whether a hit ranked rankA with ID idA comes
+// before one ranked rankB with ID idB: by descending rank, then by
+// descending ID, compared byte by byte as Postgres compares UUIDs.
+func searchHitBefore(rankA float64, idA uuid.UUID, rankB float64, idB uuid.UUID) bool {
+	if rankA != rankB {
+		return rankA > rankB
+	}
+	return bytes.Compare(idA[:], idB[:]) > 0
+}
+
+func decodeMemorySearchCursor(order sortOrder, encoded string) (float64, uuid.UUID, error) {
+	cursor, err := dto.DecodeCursor(encoded)
+	if err != nil {
+		return 0, uuid.Nil, fmt.Errorf("%w: %v", dto.ErrInvalidCursor, err)
+	}
+	if cursor.Sort != order.key {
+		return 0, uuid.Nil, fmt.Errorf("%w: issued for sort %q", dto.ErrInvalidCursor, cursor.Sort)
+	}
+	id, err := uuid.Parse(cursor.ID)
+	if err != nil {
+		return 0, uuid.Nil, fmt.Errorf("%w: %v", dto.ErrInvalidCursor, err)
+	}
+	if cursor.Value == nil {
+		return 0, uuid.Nil, fmt.Errorf("%w: missing sort value", dto.ErrInvalidCursor)
+	}
+	rank, err := strconv.ParseFloat(*cursor.Value, 64)
+	if err != nil {
+		return 0, uuid.Nil, fmt.Errorf("%w: %v", dto.ErrInvalidCursor, err)
+	}
+	return rank, id, nil
+}
+
+func memoryFilterMatches(tada domain.Tada, filter dto.TadaFilter) bool {
+	if len(filter.Status) > 0 && !containsValue(filter.Status, tada.Status) {
+		return false
+	}
+	if len(filter.StatusCategory) > 0 && !containsValue(filter.StatusCategory, tada.Category) {
+		return false
+	}
+	if filter.CreatedBy != "" && tada.CreatedBy.String() != filter.CreatedBy {
+		return false
+	}
+	if filter.AssignedTo != "" && (tada.AssignedTo == nil || tada.AssignedTo.String() != filter.AssignedTo) {
+		return false
+	}
+	if filter.Unassigned != nil && *filter.Unassigned != (tada.AssignedTo == nil) {
+		return false
+	}
+	if filter.SeriesID != "" && tada.ID.String() != filter.SeriesID &&
+		(tada.SeriesID == nil || tada.SeriesID.String() != filter.SeriesID) {
+		return false
+	}
+	if filter.ProjectID != "" && (tada.ProjectID == nil || tada.ProjectID.String() != filter.ProjectID) {
+		return false
+	}
+	if filter.DueBefore != nil && (tada.DueAt == nil || !tada.DueAt.Before(*filter.DueBefore)) {
+		return false
+	}
+	if filter.DueAfter != nil && (tada.DueAt == nil || !tada.DueAt.After(*filter.DueAfter)) {
+		return false
+	}
+	if filter.CompletedBefore != nil && (tada.CompletedAt == nil || !tada.CompletedAt.Before(*filter.CompletedBefore)) {
+		return false
+	}
+	if filter.CompletedAfter != nil && (tada.CompletedAt == nil || !tada.CompletedAt.After(*filter.CompletedAfter)) {
+		return false
+	}
+	if len(filter.Tag) > 0 {
+		tags := domain.TagNames(tada.Tags)
+		matched := 0
+		for _, tag := range filter.Tag {
+			if containsValue(tags, tag) {
+				matched++
+			}
+		}
+		if matched == 0 || (filter.TagMode != "any" && matched < len(filter.Tag)) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsValue[T comparable](values []T, value T) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// searchWords splits text into lowercase words of letters and digits.
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func containsAllWords(words, terms []string) bool {
+	for _, term := range terms {
+		if !containsValue(words, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// memoryRank scores words by how many of them are query terms.
+func memoryRank(words, terms []string, weight float64) float64 {
+	matched := 0
+	for _, word := range words {
+		if containsValue(terms, word) {
+			matched++
+		}
+	}
+	return float64(matched) * weight
+}
+
+// memorySnippet cuts an excerpt of text around its first matching word and
+// highlights every matching word in it.
+func memorySnippet(text string, terms []string) string {
+	fields := strings.Fields(text)
+	first := 0
+	for i, field := range fields {
+		if memoryRank(searchWords(field), terms, 1) > 0 {
+			first = i
+			break
+		}
+	}
+
+	start := max(first-memorySnippetWords, 0)
+	end := min(first+memorySnippetWords+1, len(fields))
+	excerpt := fields[start:end]
+	for i, field := range excerpt {
+		if memoryRank(searchWords(field), terms, 1) > 0 {
+			excerpt[i] = domain.HighlightStart + field + domain.HighlightEnd
+		}
+	}
+	return strings.Join(excerpt, " ")
+}
@@ -0,0 +1,328 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/dto"
+)
+
+// searchFixture holds a workspace's worth of tadas and comments, and the
+// IDs tests refer to them by.
+type searchFixture struct {
+	searcher  *MemorySearcher
+	workspace uuid.UUID
+	assignee  uuid.UUID
+
+	inName        uuid.UUID
+	inDescription uuid.UUID
+	inComment     uuid.UUID
+	comment       uuid.UUID
+	completed     uuid.UUID
+	elsewhere     uuid.UUID
+}
+
+func newSearchFixture() *searchFixture {
+	f := &searchFixture{
+		searcher:      NewMemorySearcher(),
+		workspace:     uuid.New(),
+		assignee:      uuid.New(),
+		inName:        uuid.New(),
+		inDescription: uuid.New(),
+		inComment:     uuid.New(),
+		comment:       uuid.New(),
+		completed:     uuid.New(),
+		elsewhere:     uuid.New(),
+	}
+	due := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	completedAt := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	f.searcher.PutTada(domain.Tada{
+		ID:          f.inName,
+		WorkspaceID: f.workspace,
+		Name:        "Quarterly report",
+		Description: "Numbers for the board",
+		Status:      domain.StatusInProgress,
+		Category:    domain.CategoryOpen,
+		AssignedTo:  &f.assignee,
+		DueAt:       &due,
+		Tags:        []domain.Tag{{Name: "finance"}, {Name: "board"}},
+	})
+	f.searcher.PutTada(domain.Tada{
+		ID:          f.inDescription,
+		WorkspaceID: f.workspace,
+		Name:        "Board meeting",
+		Description: "Present the quarterly report",
+		Status:      domain.StatusInProgress,
+		Category:    domain.CategoryOpen,
+		Tags:        []domain.Tag{{Name: "board"}},
+	})
+	f.searcher.PutTada(domain.Tada{
+		ID:          f.inComment,
+		WorkspaceID: f.workspace,
+		Name:        "Send invoices",
+		Status:      domain.StatusInProgress,
+		Category:    domain.CategoryOpen,
+		AssignedTo:  &f.assignee,
+		Tags:        []domain.Tag{{Name: "finance"}},
+	})
+	f.searcher.PutComment(domain.Comment{
+		ID:     f.comment,
+		TadaID: f.inComment,
+		Body:   "Waiting on the quarterly report before sending these",
+	})
+	f.searcher.PutTada(domain.Tada{
+		ID:          f.completed,
+		WorkspaceID: f.workspace,
+		Name:        "Last year's report",
+		Status:      domain.StatusCompleted,
+		Category:    domain.CategoryCompleted,
+		CompletedAt: &completedAt,
+	})
+	f.searcher.PutTada(domain.Tada{
+		ID:          f.elsewhere,
+		WorkspaceID: uuid.New(),
+		Name:        "Quarterly report",
+		Status:      domain.StatusInProgress,
+		Category:    domain.CategoryOpen,
+	})
+	return f
+}
+
+func (f *searchFixture) search(t *testing.T, query string, filter dto.TadaFilter) []domain.SearchHit {
+	t.Helper()
+	hits, _, err := f.searcher.InWorkspace(f.workspace).Search(
+		context.Background(), query, filter, dto.PaginationQuery{Limit: 100},
+	)
+	if err != nil {
+		t.Fatalf("Search(%q) error = %v", query, err)
+	}
+	return hits
+}
+
+func hitIDs(hits []domain.SearchHit) []uuid.UUID {
+	ids := make([]uuid.UUID, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	return ids
+}
+
+func sameIDs(got, want []uuid.UUID) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMemorySearcherRanking(t *testing.T) {
+	f := newSearchFixture()
+
+	hits := f.search(t, "quarterly report", dto.TadaFilter{})
+
+	// A match in the name outranks one in the description or a comment,
+	// which are weighted alike and so fall back to descending ID order.
+	if len(hits) != 3 {
+		t.Fatalf("Search() returned %v, want 3 hits", hitIDs(hits))
+	}
+	if hits[0].ID != f.inName || hits[0].Kind != domain.SearchHitTada {
+		t.Errorf("first hit = %s %s, want the tada named after the query", hits[0].Kind, hits[0].ID)
+	}
+	if hits[1].Rank != hits[2].Rank || !(hits[0].Rank > hits[1].Rank) {
+		t.Errorf("ranks = %v, %v, %v, want the first highest and the rest equal", hits[0].Rank, hits[1].Rank, hits[2].Rank)
+	}
+	if !searchHitBefore(hits[1].Rank, hits[1].ID, hits[2].Rank, hits[2].ID) {
+		t.Errorf("hits of equal rank are not in descending ID order")
+	}
+
+	for _, hit := range hits[1:] {
+		switch hit.ID {
+		case f.inDescription:
+			if hit.Kind != domain.SearchHitTada || hit.TadaID != f.inDescription {
+				t.Errorf("description hit = %+v", hit)
+			}
+		case f.comment:
+			if hit.Kind != domain.SearchHitComment || hit.TadaID != f.inComment {
+				t.Errorf("comment hit = %+v, want a comment on %s", hit, f.inComment)
+			}
+		default:
+			t.Errorf("unexpected hit %s", hit.ID)
+		}
+	}
+
+	want := "Board meeting Present the " +
+		domain.HighlightStart + "quarterly" + domain.HighlightEnd + " " +
+		domain.HighlightStart + "report" + domain.HighlightEnd
+	for _, hit := range hits {
+		if hit.ID == f.inDescription && hit.Snippet != want {
+			t.Errorf("Snippet = %q, want %q", hit.Snippet, want)
+		}
+	}
+}
+
+func TestMemorySearcherFiltering(t *testing.T) {
+	f := newSearchFixture()
+	unassigned := true
+	dueBefore := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	completedAfter := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		query  string
+		filter dto.TadaFilter
+		want   []uuid.UUID
+	}{
+		{
+			name:  "every word must match",
+			query: "report board",
+			want:  []uuid.UUID{f.inName, f.inDescription},
+		},
+		{
+			name:  "case-insensitive",
+			query: "INVOICES",
+			want:  []uuid.UUID{f.inComment},
+		},
+		{
+			name:  "no match",
+			query: "holiday",
+			want:  []uuid.UUID{},
+		},
+		{
+			name:  "empty query",
+			query: "   ",
+			want:  []uuid.UUID{},
+		},
+		{
+			name:   "status category",
+			query:  "report",
+			filter: dto.TadaFilter{StatusCategory: []domain.StatusCategory{domain.CategoryCompleted}},
+			want:   []uuid.UUID{f.completed},
+		},
+		{
+			name:   "assignee, including comments on the tadas",
+			query:  "quarterly",
+			filter: dto.TadaFilter{AssignedTo: f.assignee.String()},
+			want:   []uuid.UUID{f.inName, f.comment},
+		},
+		{
+			name:   "unassigned",
+			query:  "quarterly",
+			filter: dto.TadaFilter{Unassigned: &unassigned},
+			want:   []uuid.UUID{f.inDescription},
+		},
+		{
+			name:   "due before",
+			query:  "quarterly",
+			filter: dto.TadaFilter{DueBefore: &dueBefore},
+			want:   []uuid.UUID{f.inName},
+		},
+		{
+			name:   "completed after",
+			query:  "report",
+			filter: dto.TadaFilter{CompletedAfter: &completedAfter},
+			want:   []uuid.UUID{f.completed},
+		},
+		{
+			name:   "all tags",
+			query:  "quarterly",
+			filter: dto.TadaFilter{Tag: []string{"finance", "board"}},
+			want:   []uuid.UUID{f.inName},
+		},
+		{
+			name:   "any tag",
+			query:  "quarterly",
+			filter: dto.TadaFilter{Tag: []string{"finance", "board"}, TagMode: "any"},
+			want:   []uuid.UUID{f.inName, f.inDescription, f.comment},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits := f.search(t, tt.query, tt.filter)
+
+			got := map[uuid.UUID]bool{}
+			for _, id := range hitIDs(hits) {
+				got[id] = true
+			}
+			want := map[uuid.UUID]bool{}
+			for _, id := range tt.want {
+				want[id] = true
+			}
+			if len(got) != len(hits) || len(got) != len(want) {
+				t.Fatalf("Search() = %v, want %v", hitIDs(hits), tt.want)
+			}
+			for id := range want {
+				if !got[id] {
+					t.Errorf("Search() = %v, want %v", hitIDs(hits), tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestMemorySearcherWorkspaces(t *testing.T) {
+	f := newSearchFixture()
+
+	hits, _, err := f.searcher.Search(context.Background(), "quarterly", dto.TadaFilter{}, dto.PaginationQuery{})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(hits) != 0 {
+		t.Errorf("unconfined Search() = %v, want no hits", hitIDs(hits))
+	}
+
+	for _, hit := range f.search(t, "quarterly", dto.TadaFilter{}) {
+		if hit.ID == f.elsewhere {
+			t.Errorf("Search() found a tada of another workspace")
+		}
+	}
+
+	f.searcher.Remove(f.inComment)
+	for _, hit := range f.search(t, "quarterly", dto.TadaFilter{}) {
+		if hit.ID == f.comment {
+			t.Errorf("Search() found a comment on a removed tada")
+		}
+	}
+}
+
+func TestMemorySearcherPagination(t *testing.T) {
+	f := newSearchFixture()
+	searcher := f.searcher.InWorkspace(f.workspace)
+	all := hitIDs(f.search(t, "quarterly", dto.TadaFilter{}))
+
+	var paged []uuid.UUID
+	cursor := ""
+	for page := 0; page < len(all)+1; page++ {
+		hits, next, err := searcher.Search(
+			context.Background(), "quarterly", dto.TadaFilter{}, dto.PaginationQuery{Limit: 1, Cursor: cursor},
+		)
+		if err != nil {
+			t.Fatalf("Search() page %d error = %v", page, err)
+		}
+		paged = append(paged, hitIDs(hits)...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	if !sameIDs(paged, all) {
+		t.Errorf("paged hits = %v, want %v", paged, all)
+	}
+
+	if _, _, err := searcher.Search(
+		context.Background(), "quarterly", dto.TadaFilter{}, dto.PaginationQuery{Cursor: "not a cursor"},
+	); err == nil {
+		t.Errorf("Search() with an invalid cursor succeeded, want an error")
+	}
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/dto"
+)
+
+// searchLanguage is the text search configuration the search_vector
+// columns are built with. Queries must use the same one.
+const searchLanguage = "english"
+
+// headlineOptions shape the snippets ts_headline cuts from matching text.
+var headlineOptions = fmt.Sprintf(
+	`StartSel=%s, StopSel=%s, MinWords=10, MaxWords=30, MaxFragments=2, FragmentDelimiter=" … "`,
+	domain.HighlightStart, domain.HighlightEnd,
+)
+
+// PostgresSearcher searches the generated search_vector columns of tadas
+// and comments, which are covered by GIN indexes.
+type PostgresSearcher struct {
+	db          *gorm.DB
+	workspaceID uuid.UUID
+}
+
+func NewPostgresSearcher(db *gorm.DB) *PostgresSearcher {
+	return &PostgresSearcher{db: db}
+}
+
+func (s *PostgresSearcher) InWorkspace(workspaceID uuid.UUID) Searcher {
+	return &PostgresSearcher{db: s.db, workspaceID: workspaceID}
+}
+
+// Search matches query, in web search syntax, against tada names and
+// descriptions and comment bodies. Names weigh more than the rest.
+func (s *PostgresSearcher) Search(
+	ctx context.Context, query string, filter dto.TadaFilter, pagination dto.PaginationQuery,
+) ([]domain.SearchHit, string, error) {
+	order, err := parseSortOrder(searchSortKey, searchSortColumns)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Set default limit
+	if pagination.Limit == 0 {
+		pagination.Limit = 10
+	}
+
+	tsquery := gorm.Expr("websearch_to_tsquery(?::regconfig, ?)", searchLanguage, query)
+
+	matching := dbFor(ctx, s.db).Model(&domain.Tada{}).
+		Select("tadas.id").
+		Where("tadas.workspace_id = ?", s.workspaceID).
+		Scopes(filterTadas(dbFor(ctx, s.db), filter))
+
+	tadaHits := dbFor(ctx, s.db).Table("tadas").
+		Select(
+			"? AS kind, tadas.id, tadas.id AS tada_id, tadas.created_at, "+
+				"ts_rank(tadas.search_vector, ?)::float8 AS rank, "+
+				"concat_ws(' ', tadas.name, tadas.description) AS document",
+			domain.SearchHitTada, tsquery,
+		).
+		Where("tadas.search_vector @@ ?", tsquery).
+		Where("tadas.id IN (?)", matching)
+
+	commentHits := dbFor(ctx, s.db).Table("comments").
+		Select(
+			"? AS kind, comments.id, comments.tada_id, comments.created_at, "+
+				"ts_rank(comments.search_vector, ?)::float8 AS rank, "+
+				"comments.body AS document",
+			domain.SearchHitComment, tsquery,
+		).
+		Where("comments.search_vector @@ ?", tsquery).
+		Where("comments.deleted_at IS NULL").
+		Where("comments.tada_id IN (?)", matching)
+
+	// Apply cursor pagination
+	hits := dbFor(ctx, s.db).Table("(? UNION ALL ?) AS hits", tadaHits, commentHits)
+	hits, err = order.applyCursor(hits, pagination.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Fetch one extra record to check if there are more pages, and only
+	// cut snippets for the page
+	page := hits.Order(order.orderClause()).Limit(pagination.Limit + 1)
+
+	var rows []struct {
+		Kind      domain.SearchHitKind
+		ID        uuid.UUID
+		TadaID    uuid.UUID
+		CreatedAt time.Time
+		Rank      float64
+		Snippet   string
+	}
+	err = dbFor(ctx, s.db).Table("(?) AS page", page).
+		Select(
+			"kind, id, tada_id, created_at, rank, ts_headline(?::regconfig, document, ?, ?) AS snippet",
+			searchLanguage, tsquery, headlineOptions,
+		).
+		Order(order.orderClause()).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to search: %w", err)
+	}
+
+	searchHits := make([]domain.SearchHit, len(rows))
+	for i, row := range rows {
+		searchHits[i] = domain.SearchHit{
+			Kind:      row.Kind,
+			ID:        row.ID,
+			TadaID:    row.TadaID,
+			Rank:      row.Rank,
+			Snippet:   row.Snippet,
+			CreatedAt: row.CreatedAt,
+		}
+	}
+
+	// Generate next cursor
+	var nextCursor string
+	if len(searchHits) > pagination.Limit {
+		searchHits = searchHits[:pagination.Limit] // Remove extra record
+		lastHit := searchHits[len(searchHits)-1]
+		nextCursor = order.nextCursor(lastHit.ID, lastHit.CreatedAt, floatSortValue(lastHit.Rank))
+	}
+
+	return searchHits, nextCursor, nil
+}
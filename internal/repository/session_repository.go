@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kanutocd/tada/internal/domain"
+)
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	return dbFor(ctx, r.db).Create(session).Error
+}
+
+func (r *sessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Session, error) {
+	var session domain.Session
+	err := dbFor(ctx, r.db).First(&session, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) GetByRefreshTokenHash(ctx context.Context, hash string) (*domain.Session, error) {
+	var session domain.Session
+	err := dbFor(ctx, r.db).First(&session, "refresh_token_hash = ?", hash).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) Update(ctx context.Context, session *domain.Session) error {
+	return dbFor(ctx, r.db).Save(session).Error
+}
+
+// GetByRetiredRefreshTokenHash finds the session whose token family the
+// hash was retired from.
+func (r *sessionRepository) GetByRetiredRefreshTokenHash(ctx context.Context, hash string) (*domain.Session, error) {
+	var session domain.Session
+	err := dbFor(ctx, r.db).
+		Where("id = (SELECT session_id FROM retired_refresh_tokens WHERE token_hash = ?)", hash).
+		First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Rotate saves the new refresh token hash and expiry of session, provided
+// the session is still active and holds previousHash, and retires
+// previousHash into the session's token family. It reports whether it
+// did. Run it in a transaction so that both happen or neither does.
+func (r *sessionRepository) Rotate(ctx context.Context, session *domain.Session, previousHash string) (bool, error) {
+	db := dbFor(ctx, r.db)
+	result := db.Model(&domain.Session{}).
+		Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", session.ID, previousHash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash": session.RefreshTokenHash,
+			"expires_at":         session.ExpiresAt,
+			"updated_at":         time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	retired := &domain.RetiredRefreshToken{TokenHash: previousHash, SessionID: session.ID}
+	if err := db.Create(retired).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *sessionRepository) Revoke(ctx context.Context, id uuid.UUID, now time.Time) error {
+	return dbFor(ctx, r.db).Model(&domain.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", now).Error
+}
+
+// RevokeOthers revokes every active session of a user but keepID.
+func (r *sessionRepository) RevokeOthers(ctx context.Context, userID, keepID uuid.UUID, now time.Time) error {
+	return dbFor(ctx, r.db).Model(&domain.Session{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, keepID).
+		Update("revoked_at", now).Error
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kanutocd/tada/internal/domain"
+)
+
+type tadaDependencyRepository struct {
+	db *gorm.DB
+}
+
+func NewTadaDependencyRepository(db *gorm.DB) TadaDependencyRepository {
+	return &tadaDependencyRepository{db: db}
+}
+
+func (r *tadaDependencyRepository) Create(ctx context.Context, dependency *domain.TadaDependency) error {
+	return dbFor(ctx, r.db).Create(dependency).Error
+}
+
+func (r *tadaDependencyRepository) Get(ctx context.Context, blockerID, blockedID uuid.UUID) (*domain.TadaDependency, error) {
+	var dependency domain.TadaDependency
+	err := dbFor(ctx, r.db).First(&dependency, "blocker_id = ? AND blocked_id = ?", blockerID, blockedID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &dependency, nil
+}
+
+func (r *tadaDependencyRepository) Delete(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	return dbFor(ctx, r.db).Delete(&domain.TadaDependency{}, "blocker_id = ? AND blocked_id = ?", blockerID, blockedID).Error
+}
+
+// HasPath reports whether from blocks to, directly or through other tadas.
+func (r *tadaDependencyRepository) HasPath(ctx context.Context, from, to uuid.UUID) (bool, error) {
+	var found bool
+	err := dbFor(ctx, r.db).Raw(`
+		WITH RECURSIVE reachable (id) AS (
+			SELECT blocked_id FROM tada_dependencies WHERE blocker_id = ?
+			UNION
+			SELECT d.blocked_id FROM tada_dependencies d JOIN reachable r ON d.blocker_id = r.id
+		)
+		SELECT EXISTS (SELECT 1 FROM reachable WHERE id = ?)`, from, to).
+		Scan(&found).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to walk dependencies: %w", err)
+	}
+
+	return found, nil
+}
+
+// GetBlockers returns the tadas blocking each of the given tadas, oldest
+// link first. Deleted blockers are left out.
+func (r *tadaDependencyRepository) GetBlockers(ctx context.Context, blockedIDs []uuid.UUID) ([]domain.TadaBlocker, error) {
+	var blockers []domain.TadaBlocker
+	if len(blockedIDs) == 0 {
+		return blockers, nil
+	}
+
+	err := dbFor(ctx, r.db).Table("tada_dependencies").
+		Select("tada_dependencies.blocked_id, tada_dependencies.blocker_id, tadas.status_category AS category").
+		Joins("JOIN tadas ON tadas.id = tada_dependencies.blocker_id AND tadas.deleted_at IS NULL").
+		Where("tada_dependencies.blocked_id IN ?", blockedIDs).
+		Order("tada_dependencies.created_at").
+		Scan(&blockers).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch blockers: %w", err)
+	}
+
+	return blockers, nil
+}
@@ -1,133 +1,451 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/kanutocd/tada/internal/domain"
 	"github.com/kanutocd/tada/internal/dto"
 )
 
 type tadaRepository struct {
-	db *gorm.DB
+	db          *gorm.DB
+	workspaceID uuid.UUID
 }
 
 func NewTadaRepository(db *gorm.DB) TadaRepository {
 	return &tadaRepository{db: db}
 }
 
-func (r *tadaRepository) Create(tada *domain.Tada) error {
-	return r.db.Create(tada).Error
+func (r *tadaRepository) InWorkspace(workspaceID uuid.UUID) TadaRepository {
+	return &tadaRepository{db: r.db, workspaceID: workspaceID}
 }
 
-func (r *tadaRepository) GetByID(id uuid.UUID) (*domain.Tada, error) {
+// inWorkspace restricts a query on tadas to the repository's workspace.
+func (r *tadaRepository) inWorkspace(db *gorm.DB) *gorm.DB {
+	return db.Where("tadas.workspace_id = ?", r.workspaceID)
+}
+
+// Create inserts a tada into the repository's workspace and records a
+// created event in the same transaction.
+func (r *tadaRepository) Create(ctx context.Context, tada *domain.Tada, actorID uuid.UUID) error {
+	tada.WorkspaceID = r.workspaceID
+	return dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(tada).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&domain.TadaEvent{
+			TadaID:  tada.ID,
+			ActorID: &actorID,
+			Type:    domain.TadaEventCreated,
+			Changes: domain.SnapshotTada(tada),
+		}).Error
+	})
+}
+
+func (r *tadaRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Tada, error) {
 	var tada domain.Tada
-	err := r.db.Preload("Creator").Preload("Assignee").First(&tada, "id = ?", id).Error
+	err := dbFor(ctx, r.db).Scopes(r.inWorkspace).
+		Preload("Creator").Preload("Assignee").Preload("Tags", orderTagsByName).
+		First(&tada, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &tada, nil
 }
 
-func (r *tadaRepository) Update(tada *domain.Tada) error {
-	return r.db.Save(tada).Error
+// GetByIDs returns the tadas with the given IDs, in no particular order.
+// IDs of missing tadas are skipped.
+func (r *tadaRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]domain.Tada, error) {
+	var tadas []domain.Tada
+	if len(ids) == 0 {
+		return tadas, nil
+	}
+
+	err := dbFor(ctx, r.db).Scopes(r.inWorkspace).
+		Preload("Creator").Preload("Assignee").Preload("Tags", orderTagsByName).
+		Where("id IN ?", ids).
+		Find(&tadas).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tadas: %w", err)
+	}
+	return tadas, nil
+}
+
+// Update saves a tada and records what changed in the same transaction.
+// Changes made by the caller and changes made by model hooks while saving
+// (such as the CompletedAt stamp) are recorded as separate events. The tada
+// must still be at the version it was read at, or ErrVersionConflict is
+// returned; on success its Version moves on by one.
+func (r *tadaRepository) Update(ctx context.Context, tada *domain.Tada, actorID uuid.UUID) error {
+	return r.UpdateAll(ctx, []*domain.Tada{tada}, actorID)
 }
 
-func (r *tadaRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&domain.Tada{}, "id = ?", id).Error
+// UpdateAll saves several tadas as Update does, all in one transaction. If
+// any of them is outdated, none is saved.
+func (r *tadaRepository) UpdateAll(ctx context.Context, tadas []*domain.Tada, actorID uuid.UUID) error {
+	return dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for _, tada := range tadas {
+			if err := updateTada(tx, r.inWorkspace, tada, actorID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
-func (r *tadaRepository) GetAll(pagination dto.PaginationQuery) ([]domain.Tada, string, error) {
-	var tadas []domain.Tada
-	var query *gorm.DB = r.db.Model(&domain.Tada{}).Preload("Creator").Preload("Assignee")
+// updateTada saves one tada for UpdateAll. The stored tada is looked up
+// under scope, and the tada stays in the workspace it was stored in.
+func updateTada(tx *gorm.DB, scope func(*gorm.DB) *gorm.DB, tada *domain.Tada, actorID uuid.UUID) error {
+	var stored domain.Tada
+	if err := tx.Scopes(scope).Clauses(clause.Locking{Strength: "UPDATE"}).First(&stored, "id = ?", tada.ID).Error; err != nil {
+		return err
+	}
+	tada.WorkspaceID = stored.WorkspaceID
+	if err := tx.Model(&stored).Association("Tags").Find(&stored.Tags); err != nil {
+		return err
+	}
+
+	// Associations are saved explicitly: a preloaded Assignee would
+	// otherwise overwrite AssignedTo, and saving never detaches tags.
+	requested := *tada
+	if err := saveVersioned(tx.Omit(clause.Associations), tada, &tada.Version); err != nil {
+		return err
+	}
+	if err := tx.Model(tada).Association("Tags").Replace(tada.Tags); err != nil {
+		return err
+	}
+
+	var events []domain.TadaEvent
+	if changes := domain.DiffTadas(&stored, &requested); len(changes) > 0 {
+		events = append(events, domain.TadaEvent{
+			TadaID:  tada.ID,
+			ActorID: &actorID,
+			Type:    domain.TadaEventUpdated,
+			Changes: changes,
+		})
+	}
+	if changes := domain.DiffTadas(&requested, tada); len(changes) > 0 {
+		events = append(events, domain.TadaEvent{
+			TadaID:  tada.ID,
+			ActorID: &actorID,
+			Type:    domain.TadaEventCompleted,
+			Changes: changes,
+		})
+	}
+
+	if len(events) == 0 {
+		return nil
+	}
+	return tx.Create(&events).Error
+}
+
+// Delete soft-deletes a tada and records a deleted event in the same
+// transaction. The tada must be at the given version, or ErrVersionConflict
+// is returned.
+func (r *tadaRepository) Delete(ctx context.Context, id uuid.UUID, version int, actorID uuid.UUID) error {
+	return dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := deleteVersioned(tx, &domain.Tada{}, id, version, r.inWorkspace); err != nil {
+			return err
+		}
+
+		return tx.Create(&domain.TadaEvent{
+			TadaID:  id,
+			ActorID: &actorID,
+			Type:    domain.TadaEventDeleted,
+		}).Error
+	})
+}
+
+// GetHistory lists a tada's events, newest first.
+func (r *tadaRepository) GetHistory(
+	ctx context.Context, tadaID uuid.UUID, pagination dto.PaginationQuery,
+) ([]domain.TadaEvent, string, error) {
+	var events []domain.TadaEvent
+
+	order, err := parseSortOrder("", nil)
+	if err != nil {
+		return nil, "", err
+	}
 
 	// Set default limit
 	if pagination.Limit == 0 {
 		pagination.Limit = 10
 	}
 
-	// Apply cursor pagination
-	if pagination.Cursor != "" {
-		cursor, err := dto.DecodeCursor(pagination.Cursor)
-		if err != nil {
-			return nil, "", fmt.Errorf("invalid cursor: %w", err)
-		}
-
-		query = query.Where("(created_at < ? OR (created_at = ? AND id < ?))",
-			cursor.CreatedAt, cursor.CreatedAt, cursor.ID)
+	query := dbFor(ctx, r.db).Model(&domain.TadaEvent{}).
+		Where("tada_id = ?", tadaID).
+		Where("tada_id IN (?)", dbFor(ctx, r.db).Unscoped().Model(&domain.Tada{}).Select("id").Scopes(r.inWorkspace))
+	query, err = order.applyCursor(query, pagination.Cursor)
+	if err != nil {
+		return nil, "", err
 	}
 
 	// Fetch one extra record to check if there are more pages
-	query = query.Order("created_at DESC, id DESC").Limit(pagination.Limit + 1)
+	query = query.Order(order.orderClause()).Limit(pagination.Limit + 1)
 
-	if err := query.Find(&tadas).Error; err != nil {
-		return nil, "", fmt.Errorf("failed to fetch tadas: %w", err)
+	if err := query.Find(&events).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to fetch tada events: %w", err)
 	}
 
 	// Generate next cursor
 	var nextCursor string
-	if len(tadas) > pagination.Limit {
-		tadas = tadas[:pagination.Limit] // Remove extra record
-		lastTada := tadas[len(tadas)-1]
-		nextCursor = dto.EncodeCursor(dto.Cursor{
-			ID:        lastTada.ID.String(),
-			CreatedAt: lastTada.CreatedAt,
-		})
+	if len(events) > pagination.Limit {
+		events = events[:pagination.Limit] // Remove extra record
+		lastEvent := events[len(events)-1]
+		nextCursor = order.nextCursor(lastEvent.ID, lastEvent.CreatedAt, nil)
 	}
 
-	return tadas, nextCursor, nil
+	return events, nextCursor, nil
 }
 
-func (r *tadaRepository) GetByUserID(userID uuid.UUID, pagination dto.PaginationQuery) ([]domain.Tada, string, error) {
-	var tadas []domain.Tada
-	var query *gorm.DB = r.db.Model(&domain.Tada{}).
-		Preload("Creator").Preload("Assignee").
-		Where("created_by = ?", userID)
+func (r *tadaRepository) GetAll(ctx context.Context, filter dto.TadaFilter, pagination dto.PaginationQuery) ([]domain.Tada, string, error) {
+	query := dbFor(ctx, r.db).Model(&domain.Tada{}).Scopes(r.inWorkspace).
+		Preload("Creator").Preload("Assignee").Preload("Tags", orderTagsByName).
+		Scopes(filterTadas(dbFor(ctx, r.db), filter))
 
-	// Set default limit
-	if pagination.Limit == 0 {
-		pagination.Limit = 10
-	}
+	return r.list(query, filter.Sort, pagination)
+}
 
-	// Apply cursor pagination
-	if pagination.Cursor != "" {
-		cursor, err := dto.DecodeCursor(pagination.Cursor)
-		if err != nil {
-			return nil, "", fmt.Errorf("invalid cursor: %w", err)
+// filterTadas narrows a query on tadas to those matching the listing
+// filter, leaving its Sort to the caller. db builds the subqueries.
+func filterTadas(db *gorm.DB, filter dto.TadaFilter) func(*gorm.DB) *gorm.DB {
+	return func(query *gorm.DB) *gorm.DB {
+		if len(filter.Status) > 0 {
+			query = query.Where("status IN ?", filter.Status)
+		}
+		if len(filter.StatusCategory) > 0 {
+			query = query.Where("status_category IN ?", filter.StatusCategory)
+		}
+		if filter.CreatedBy != "" {
+			query = query.Where("created_by = ?", filter.CreatedBy)
+		}
+		if filter.AssignedTo != "" {
+			query = query.Where("assigned_to = ?", filter.AssignedTo)
+		}
+		if filter.SeriesID != "" {
+			query = query.Where("(id = ? OR series_id = ?)", filter.SeriesID, filter.SeriesID)
+		}
+		if filter.ProjectID != "" {
+			query = query.Where("project_id = ?", filter.ProjectID)
+		} else if !filter.IncludeArchived {
+			query = query.Scopes(excludeArchivedProjects)
+		}
+		if filter.Unassigned != nil {
+			if *filter.Unassigned {
+				query = query.Where("assigned_to IS NULL")
+			} else {
+				query = query.Where("assigned_to IS NOT NULL")
+			}
+		}
+		if filter.DueBefore != nil {
+			query = query.Where("due_at < ?", *filter.DueBefore)
+		}
+		if filter.DueAfter != nil {
+			query = query.Where("due_at > ?", *filter.DueAfter)
+		}
+		if filter.CompletedBefore != nil {
+			query = query.Where("completed_at < ?", *filter.CompletedBefore)
+		}
+		if filter.CompletedAfter != nil {
+			query = query.Where("completed_at > ?", *filter.CompletedAfter)
+		}
+		if len(filter.Tag) > 0 {
+			tagged := db.Table("tada_tags").
+				Select("tada_tags.tada_id").
+				Joins("JOIN tags ON tags.id = tada_tags.tag_id").
+				Where("tags.name IN ?", filter.Tag)
+			if filter.TagMode != "any" {
+				tagged = tagged.Group("tada_tags.tada_id").
+					Having("COUNT(DISTINCT tags.id) = ?", len(filter.Tag))
+			}
+			query = query.Where("id IN (?)", tagged)
+		}
+		if filter.Blocked != nil {
+			blocking := db.Table("tada_dependencies").
+				Select("1").
+				Joins("JOIN tadas AS blockers ON blockers.id = tada_dependencies.blocker_id").
+				Where("tada_dependencies.blocked_id = tadas.id").
+				Where("blockers.status_category = ? AND blockers.deleted_at IS NULL", domain.CategoryOpen)
+			if *filter.Blocked {
+				query = query.Where("EXISTS (?)", blocking)
+			} else {
+				query = query.Where("NOT EXISTS (?)", blocking)
+			}
 		}
 
-		query = query.Where("(created_at < ? OR (created_at = ? AND id < ?))",
-			cursor.CreatedAt, cursor.CreatedAt, cursor.ID)
+		return query
 	}
+}
 
-	// Fetch one extra record to check if there are more pages
-	query = query.Order("created_at DESC, id DESC").Limit(pagination.Limit + 1)
+func (r *tadaRepository) GetByUserID(ctx context.Context, userID uuid.UUID, pagination dto.PaginationQuery) ([]domain.Tada, string, error) {
+	query := dbFor(ctx, r.db).Model(&domain.Tada{}).Scopes(r.inWorkspace).
+		Preload("Creator").Preload("Assignee").Preload("Tags", orderTagsByName).
+		Where("created_by = ?", userID).
+		Scopes(excludeArchivedProjects)
 
-	if err := query.Find(&tadas).Error; err != nil {
-		return nil, "", fmt.Errorf("failed to fetch tadas: %w", err)
+	return r.list(query, "", pagination)
+}
+
+func (r *tadaRepository) GetByAssigneeID(
+	ctx context.Context, assigneeID uuid.UUID, pagination dto.PaginationQuery,
+) ([]domain.Tada, string, error) {
+	query := dbFor(ctx, r.db).Model(&domain.Tada{}).Scopes(r.inWorkspace).
+		Preload("Creator").Preload("Assignee").Preload("Tags", orderTagsByName).
+		Where("assigned_to = ?", assigneeID).
+		Scopes(excludeArchivedProjects)
+
+	return r.list(query, "", pagination)
+}
+
+// GetByParticipantID lists the tadas a user either created or is assigned to.
+func (r *tadaRepository) GetByParticipantID(
+	ctx context.Context, userID uuid.UUID, pagination dto.PaginationQuery,
+) ([]domain.Tada, string, error) {
+	query := dbFor(ctx, r.db).Model(&domain.Tada{}).Scopes(r.inWorkspace).
+		Preload("Creator").Preload("Assignee").Preload("Tags", orderTagsByName).
+		Where("(created_by = ? OR assigned_to = ?)", userID, userID).
+		Scopes(excludeArchivedProjects)
+
+	return r.list(query, "", pagination)
+}
+
+// excludeArchivedProjects leaves out the tadas of archived projects.
+func excludeArchivedProjects(query *gorm.DB) *gorm.DB {
+	return query.Where("(project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived))")
+}
+
+func orderTagsByName(db *gorm.DB) *gorm.DB {
+	return db.Order("tags.name")
+}
+
+// GetOccurrence returns the given occurrence of a recurring series.
+func (r *tadaRepository) GetOccurrence(ctx context.Context, seriesID uuid.UUID, occurrence int) (*domain.Tada, error) {
+	var tada domain.Tada
+	err := dbFor(ctx, r.db).Scopes(r.inWorkspace).
+		Where("(id = ? OR series_id = ?) AND occurrence = ?", seriesID, seriesID, occurrence).
+		First(&tada).Error
+	if err != nil {
+		return nil, err
 	}
+	return &tada, nil
+}
 
-	// Generate next cursor
-	var nextCursor string
-	if len(tadas) > pagination.Limit {
-		tadas = tadas[:pagination.Limit] // Remove extra record
-		lastTada := tadas[len(tadas)-1]
-		nextCursor = dto.EncodeCursor(dto.Cursor{
-			ID:        lastTada.ID.String(),
-			CreatedAt: lastTada.CreatedAt,
-		})
+// GetByParentID lists a tada's direct subtasks.
+func (r *tadaRepository) GetByParentID(
+	ctx context.Context, parentID uuid.UUID, pagination dto.PaginationQuery,
+) ([]domain.Tada, string, error) {
+	query := dbFor(ctx, r.db).Model(&domain.Tada{}).Scopes(r.inWorkspace).
+		Preload("Creator").Preload("Assignee").Preload("Tags", orderTagsByName).
+		Where("parent_id = ?", parentID)
+
+	return r.list(query, "", pagination)
+}
+
+// GetDescendants returns the subtasks below a tada, down to maxDepth levels,
+// or all of them if maxDepth is 0. They are ordered oldest first.
+func (r *tadaRepository) GetDescendants(ctx context.Context, id uuid.UUID, maxDepth int) ([]domain.Tada, error) {
+	// path guards against cycles already present in the data.
+	descendantIDs := dbFor(ctx, r.db).Raw(`
+		WITH RECURSIVE tree (id, depth, path) AS (
+			SELECT id, 0, ARRAY[id] FROM tadas WHERE id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT t.id, tree.depth + 1, tree.path || t.id
+			FROM tadas t JOIN tree ON t.parent_id = tree.id
+			WHERE t.deleted_at IS NULL AND NOT t.id = ANY(tree.path) AND (? = 0 OR tree.depth < ?)
+		)
+		SELECT id FROM tree WHERE depth > 0`, id, maxDepth, maxDepth)
+
+	var tadas []domain.Tada
+	err := dbFor(ctx, r.db).Scopes(r.inWorkspace).
+		Preload("Creator").Preload("Assignee").Preload("Tags", orderTagsByName).
+		Where("id IN (?)", descendantIDs).
+		Order("created_at, id").
+		Find(&tadas).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch subtasks: %w", err)
 	}
 
-	return tadas, nextCursor, nil
+	return tadas, nil
+}
+
+// GetAncestorIDs returns the IDs of a tada and of every tada above it,
+// nearest first.
+func (r *tadaRepository) GetAncestorIDs(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := dbFor(ctx, r.db).Raw(`
+		WITH RECURSIVE ancestors (id, parent_id, path) AS (
+			SELECT id, parent_id, ARRAY[id] FROM tadas WHERE id = ? AND workspace_id = ?
+			UNION ALL
+			SELECT t.id, t.parent_id, a.path || t.id
+			FROM tadas t JOIN ancestors a ON t.id = a.parent_id
+			WHERE NOT t.id = ANY(a.path) AND t.workspace_id = ?
+		)
+		SELECT id FROM ancestors ORDER BY array_length(path, 1)`, id, r.workspaceID, r.workspaceID).
+		Scan(&ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch ancestors: %w", err)
+	}
+
+	return ids, nil
 }
 
-func (r *tadaRepository) GetByAssigneeID(assigneeID uuid.UUID, pagination dto.PaginationQuery) ([]domain.Tada, string, error) {
+// GetProgress counts the direct subtasks of each of the given tadas. Tadas
+// without subtasks are left out of the result.
+func (r *tadaRepository) GetProgress(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]domain.TadaProgress, error) {
+	progress := make(map[uuid.UUID]domain.TadaProgress)
+	if len(ids) == 0 {
+		return progress, nil
+	}
+
+	var rows []struct {
+		ParentID  uuid.UUID
+		Completed int
+		Total     int
+	}
+	err := dbFor(ctx, r.db).Model(&domain.Tada{}).Scopes(r.inWorkspace).
+		Select("parent_id, COUNT(*) FILTER (WHERE status_category = ?) AS completed, COUNT(*) AS total", domain.CategoryCompleted).
+		Where("parent_id IN ?", ids).
+		Group("parent_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count subtasks: %w", err)
+	}
+
+	for _, row := range rows {
+		progress[row.ParentID] = domain.TadaProgress{Completed: row.Completed, Total: row.Total}
+	}
+
+	return progress, nil
+}
+
+// priorityRank orders tadas by the urgency of their priority rather than
+// alphabetically. It matches domain.TadaPriority.Rank and the expression
+// index on tadas.
+const priorityRank = "(CASE priority WHEN 'low' THEN 0 WHEN 'medium' THEN 1 WHEN 'high' THEN 2 WHEN 'urgent' THEN 3 END)"
+
+var tadaSortColumns = map[string]sortColumn{
+	"created_at": {name: "created_at", isTime: true},
+	"updated_at": {name: "updated_at", isTime: true},
+	"due_at":     {name: "due_at", nullable: true, isTime: true},
+	"name":       {name: "name"},
+	"status":     {name: "status"},
+	"priority":   {name: priorityRank, isInt: true},
+}
+
+// list runs a tada query one page at a time under the given sort key.
+func (r *tadaRepository) list(query *gorm.DB, sort string, pagination dto.PaginationQuery) ([]domain.Tada, string, error) {
 	var tadas []domain.Tada
-	var query *gorm.DB = r.db.Model(&domain.Tada{}).
-		Preload("Creator").Preload("Assignee").
-		Where("assigned_to = ?", assigneeID)
+
+	order, err := parseSortOrder(sort, tadaSortColumns)
+	if err != nil {
+		return nil, "", err
+	}
 
 	// Set default limit
 	if pagination.Limit == 0 {
@@ -135,18 +453,13 @@ func (r *tadaRepository) GetByAssigneeID(assigneeID uuid.UUID, pagination dto.Pa
 	}
 
 	// Apply cursor pagination
-	if pagination.Cursor != "" {
-		cursor, err := dto.DecodeCursor(pagination.Cursor)
-		if err != nil {
-			return nil, "", fmt.Errorf("invalid cursor: %w", err)
-		}
-
-		query = query.Where("(created_at < ? OR (created_at = ? AND id < ?))",
-			cursor.CreatedAt, cursor.CreatedAt, cursor.ID)
+	query, err = order.applyCursor(query, pagination.Cursor)
+	if err != nil {
+		return nil, "", err
 	}
 
 	// Fetch one extra record to check if there are more pages
-	query = query.Order("created_at DESC, id DESC").Limit(pagination.Limit + 1)
+	query = query.Order(order.orderClause()).Limit(pagination.Limit + 1)
 
 	if err := query.Find(&tadas).Error; err != nil {
 		return nil, "", fmt.Errorf("failed to fetch tadas: %w", err)
@@ -157,11 +470,26 @@ func (r *tadaRepository) GetByAssigneeID(assigneeID uuid.UUID, pagination dto.Pa
 	if len(tadas) > pagination.Limit {
 		tadas = tadas[:pagination.Limit] // Remove extra record
 		lastTada := tadas[len(tadas)-1]
-		nextCursor = dto.EncodeCursor(dto.Cursor{
-			ID:        lastTada.ID.String(),
-			CreatedAt: lastTada.CreatedAt,
-		})
+		nextCursor = order.nextCursor(lastTada.ID, lastTada.CreatedAt, tadaSortValue(&lastTada, order.column.name))
 	}
 
 	return tadas, nextCursor, nil
 }
+
+func tadaSortValue(tada *domain.Tada, column string) *string {
+	switch column {
+	case "updated_at":
+		return timeSortValue(&tada.UpdatedAt)
+	case "due_at":
+		return timeSortValue(tada.DueAt)
+	case "name":
+		return &tada.Name
+	case "status":
+		status := string(tada.Status)
+		return &status
+	case priorityRank:
+		return intSortValue(tada.Priority.Rank())
+	default:
+		return timeSortValue(&tada.CreatedAt)
+	}
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/dto"
+)
+
+type tagRepository struct {
+	db          *gorm.DB
+	workspaceID uuid.UUID
+}
+
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+func (r *tagRepository) InWorkspace(workspaceID uuid.UUID) TagRepository {
+	return &tagRepository{db: r.db, workspaceID: workspaceID}
+}
+
+// inWorkspace restricts a query on tags to the repository's workspace.
+func (r *tagRepository) inWorkspace(db *gorm.DB) *gorm.DB {
+	return db.Where("tags.workspace_id = ?", r.workspaceID)
+}
+
+// Create inserts a tag into the repository's workspace.
+func (r *tagRepository) Create(ctx context.Context, tag *domain.Tag) error {
+	tag.WorkspaceID = r.workspaceID
+	return dbFor(ctx, r.db).Create(tag).Error
+}
+
+func (r *tagRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Tag, error) {
+	var tag domain.Tag
+	err := dbFor(ctx, r.db).Scopes(r.inWorkspace).First(&tag, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *tagRepository) GetByName(ctx context.Context, name string) (*domain.Tag, error) {
+	var tag domain.Tag
+	err := dbFor(ctx, r.db).Scopes(r.inWorkspace).First(&tag, "name = ?", name).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// Update saves a tag of the repository's workspace.
+func (r *tagRepository) Update(ctx context.Context, tag *domain.Tag) error {
+	return dbFor(ctx, r.db).Scopes(r.inWorkspace).
+		Select("name", "updated_at").
+		Updates(tag).Error
+}
+
+// Delete removes a tag of the repository's workspace and detaches it from
+// every tada.
+func (r *tagRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(
+			"DELETE FROM tada_tags WHERE tag_id IN (SELECT id FROM tags WHERE id = ? AND workspace_id = ?)", id, r.workspaceID,
+		).Error; err != nil {
+			return err
+		}
+		return tx.Scopes(r.inWorkspace).Delete(&domain.Tag{}, "id = ?", id).Error
+	})
+}
+
+var tagSortColumns = map[string]sortColumn{
+	"name": {name: "name"},
+}
+
+// GetAll lists tags alphabetically.
+func (r *tagRepository) GetAll(ctx context.Context, pagination dto.PaginationQuery) ([]domain.Tag, string, error) {
+	var tags []domain.Tag
+
+	order, err := parseSortOrder("name", tagSortColumns)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Set default limit
+	if pagination.Limit == 0 {
+		pagination.Limit = 10
+	}
+
+	// Apply cursor pagination
+	query, err := order.applyCursor(dbFor(ctx, r.db).Model(&domain.Tag{}).Scopes(r.inWorkspace), pagination.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Fetch one extra record to check if there are more pages
+	query = query.Order(order.orderClause()).Limit(pagination.Limit + 1)
+
+	if err := query.Find(&tags).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to fetch tags: %w", err)
+	}
+
+	// Generate next cursor
+	var nextCursor string
+	if len(tags) > pagination.Limit {
+		tags = tags[:pagination.Limit] // Remove extra record
+		lastTag := tags[len(tags)-1]
+		nextCursor = order.nextCursor(lastTag.ID, lastTag.CreatedAt, &lastTag.Name)
+	}
+
+	return tags, nextCursor, nil
+}
+
+// FindOrCreate returns the tags of the repository's workspace with the
+// given names, creating any that do not exist yet. Names must already be
+// normalized.
+func (r *tagRepository) FindOrCreate(ctx context.Context, names []string) ([]domain.Tag, error) {
+	if len(names) == 0 {
+		return []domain.Tag{}, nil
+	}
+
+	tags := make([]domain.Tag, len(names))
+	for i, name := range names {
+		tags[i] = domain.Tag{WorkspaceID: r.workspaceID, Name: name}
+	}
+
+	var found []domain.Tag
+	err := dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "workspace_id"}, {Name: "name"}},
+			DoNothing: true,
+		}).Create(&tags).Error
+		if err != nil {
+			return err
+		}
+		return tx.Scopes(r.inWorkspace).Where("name IN ?", names).Order("name").Find(&found).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return found, nil
+}
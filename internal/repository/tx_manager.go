@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// txKey is the context key under which TxManager keeps the transaction
+// in progress.
+type txKey struct{}
+
+// TxManager runs a unit of work in one database transaction. The
+// transaction travels in the context handed to the work, and every
+// repository given that context reads and writes through it.
+type TxManager interface {
+	// WithinTx calls fn with a context carrying a transaction, which is
+	// committed if fn returns nil and rolled back if it returns an error or
+	// panics. Called with a context that already carries a transaction, it
+	// runs fn under a savepoint of it instead, so that a failure undoes
+	// only what fn did.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txManager struct {
+	db         *gorm.DB
+	savepoints atomic.Uint64
+}
+
+func NewTxManager(db *gorm.DB) TxManager {
+	return &txManager{db: db}
+}
+
+func (m *txManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	tx, ok := ctx.Value(txKey{}).(*gorm.DB)
+	if !ok {
+		return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(context.WithValue(ctx, txKey{}, tx))
+		})
+	}
+
+	// gorm names the savepoint of a nested transaction after the function
+	// it runs, which would be the same at every level here, and rolling
+	// back to a name undoes only up to its latest use.
+	name := fmt.Sprintf("tx_%d", m.savepoints.Add(1))
+	if err := tx.SavePoint(name).Error; err != nil {
+		return err
+	}
+	panicked := true
+	defer func() {
+		if panicked || err != nil {
+			tx.RollbackTo(name)
+		}
+	}()
+
+	err = fn(ctx)
+	panicked = false
+	return err
+}
+
+// dbFor returns the transaction ctx carries, or db bound to ctx when it
+// carries none. Repositories start every query from it.
+func dbFor(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx
+	}
+	return db.WithContext(ctx)
+}
@@ -1,10 +1,12 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/kanutocd/tada/internal/domain"
 	"github.com/kanutocd/tada/internal/dto"
@@ -18,39 +20,70 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
-func (r *userRepository) Create(user *domain.User) error {
-	return r.db.Create(user).Error
+func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
+	return dbFor(ctx, r.db).Create(user).Error
 }
 
-func (r *userRepository) GetByID(id uuid.UUID) (*domain.User, error) {
+func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
 	var user domain.User
-	err := r.db.First(&user, "id = ?", id).Error
+	err := dbFor(ctx, r.db).First(&user, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *userRepository) GetByEmail(email string) (*domain.User, error) {
+// GetMember finds a user who belongs to the given workspace.
+func (r *userRepository) GetMember(ctx context.Context, workspaceID, id uuid.UUID) (*domain.User, error) {
 	var user domain.User
-	err := r.db.First(&user, "email = ?", email).Error
+	err := dbFor(ctx, r.db).Scopes(memberOf(workspaceID)).First(&user, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *userRepository) Update(user *domain.User) error {
-	return r.db.Save(user).Error
+// GetMemberByEmail finds a user with the given email who belongs to the
+// given workspace.
+func (r *userRepository) GetMemberByEmail(ctx context.Context, workspaceID uuid.UUID, email string) (*domain.User, error) {
+	var user domain.User
+	err := dbFor(ctx, r.db).Scopes(memberOf(workspaceID)).First(&user, "email = ?", email).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
 }
 
-func (r *userRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&domain.User{}, "id = ?", id).Error
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	var user domain.User
+	err := dbFor(ctx, r.db).First(&user, "email = ?", email).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Update saves a user, who must still be at the version they were read at,
+// or ErrVersionConflict is returned. On success Version moves on by one.
+func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
+	return saveVersioned(dbFor(ctx, r.db).Omit(clause.Associations), user, &user.Version)
+}
+
+// Delete soft-deletes a user, who must be at the given version, or
+// ErrVersionConflict is returned.
+func (r *userRepository) Delete(ctx context.Context, id uuid.UUID, version int) error {
+	return deleteVersioned(dbFor(ctx, r.db), &domain.User{}, id, version)
 }
 
-func (r *userRepository) GetAll(pagination dto.PaginationQuery) ([]domain.User, string, error) {
+// GetMembers lists the users who belong to the given workspace, newest
+// first.
+func (r *userRepository) GetMembers(
+	ctx context.Context,
+	workspaceID uuid.UUID,
+	pagination dto.PaginationQuery,
+) ([]domain.User, string, error) {
 	var users []domain.User
-	var query *gorm.DB = r.db.Model(&domain.User{})
+	var query *gorm.DB = dbFor(ctx, r.db).Model(&domain.User{}).Scopes(memberOf(workspaceID))
 
 	// Set default limit
 	if pagination.Limit == 0 {
@@ -88,3 +121,13 @@ func (r *userRepository) GetAll(pagination dto.PaginationQuery) ([]domain.User,
 
 	return users, nextCursor, nil
 }
+
+// memberOf restricts a query on users to the members of a workspace.
+func memberOf(workspaceID uuid.UUID) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(
+			"EXISTS (SELECT 1 FROM workspace_members WHERE workspace_members.user_id = users.id AND workspace_members.workspace_id = ?)",
+			workspaceID,
+		)
+	}
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrVersionConflict is returned when a record is saved or deleted at a
+// version it is no longer at, because someone else changed it since it was
+// read.
+var ErrVersionConflict = errors.New("version conflict")
+
+// saveVersioned writes every column of record, as Save would, but only if
+// the stored row is still at *version. It then moves *version on by one.
+// Unlike Save, it never falls back to inserting the record.
+func saveVersioned(db *gorm.DB, record interface{}, version *int) error {
+	expected := *version
+	*version = expected + 1
+
+	result := db.Select("*").Where("version = ?", expected).Updates(record)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = ErrVersionConflict
+	}
+	if result.Error != nil {
+		*version = expected
+	}
+	return result.Error
+}
+
+// deleteVersioned soft-deletes the row of model with the given ID, under
+// scopes, if it is at version. It returns gorm.ErrRecordNotFound if there
+// is no such row at any version.
+func deleteVersioned(db *gorm.DB, model interface{}, id uuid.UUID, version int, scopes ...func(*gorm.DB) *gorm.DB) error {
+	result := db.Scopes(scopes...).Where("version = ?", version).Delete(model, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	var count int64
+	if err := db.Model(model).Scopes(scopes...).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return ErrVersionConflict
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/dto"
+)
+
+type webhookRepository struct {
+	db          *gorm.DB
+	workspaceID uuid.UUID
+}
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) InWorkspace(workspaceID uuid.UUID) WebhookRepository {
+	return &webhookRepository{db: r.db, workspaceID: workspaceID}
+}
+
+// inWorkspace restricts a query on webhooks to the repository's workspace.
+func (r *webhookRepository) inWorkspace(db *gorm.DB) *gorm.DB {
+	return db.Where("webhooks.workspace_id = ?", r.workspaceID)
+}
+
+// Create inserts a webhook into the repository's workspace.
+func (r *webhookRepository) Create(ctx context.Context, webhook *domain.Webhook) error {
+	webhook.WorkspaceID = r.workspaceID
+	return dbFor(ctx, r.db).Create(webhook).Error
+}
+
+func (r *webhookRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Webhook, error) {
+	var webhook domain.Webhook
+	err := dbFor(ctx, r.db).Scopes(r.inWorkspace).First(&webhook, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *webhookRepository) Update(ctx context.Context, webhook *domain.Webhook) error {
+	return dbFor(ctx, r.db).Scopes(r.inWorkspace).
+		Select("url", "events", "active", "updated_at").
+		Updates(webhook).Error
+}
+
+func (r *webhookRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return dbFor(ctx, r.db).Scopes(r.inWorkspace).Delete(&domain.Webhook{}, "id = ?", id).Error
+}
+
+func (r *webhookRepository) GetByOwnerID(
+	ctx context.Context, ownerID uuid.UUID, pagination dto.PaginationQuery,
+) ([]domain.Webhook, string, error) {
+	var webhooks []domain.Webhook
+
+	order, err := parseSortOrder("", nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Set default limit
+	if pagination.Limit == 0 {
+		pagination.Limit = 10
+	}
+
+	// Apply cursor pagination
+	query := dbFor(ctx, r.db).Model(&domain.Webhook{}).Scopes(r.inWorkspace).Where("owner_id = ?", ownerID)
+	query, err = order.applyCursor(query, pagination.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Fetch one extra record to check if there are more pages
+	query = query.Order(order.orderClause()).Limit(pagination.Limit + 1)
+
+	if err := query.Find(&webhooks).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to fetch webhooks: %w", err)
+	}
+
+	// Generate next cursor
+	var nextCursor string
+	if len(webhooks) > pagination.Limit {
+		webhooks = webhooks[:pagination.Limit] // Remove extra record
+		lastWebhook := webhooks[len(webhooks)-1]
+		nextCursor = order.nextCursor(lastWebhook.ID, lastWebhook.CreatedAt, nil)
+	}
+
+	return webhooks, nextCursor, nil
+}
+
+// GetSubscribed returns the active webhooks of a workspace subscribed to
+// changeType, leaving out those whose owners are no longer members.
+func (r *webhookRepository) GetSubscribed(
+	ctx context.Context, changeType domain.TadaChangeType, workspaceID uuid.UUID,
+) ([]domain.Webhook, error) {
+	var webhooks []domain.Webhook
+	err := dbFor(ctx, r.db).Scopes(ownerIsMember).
+		Where("active = ? AND events @> ?", true, fmt.Sprintf("[%q]", changeType)).
+		Where("workspace_id = ?", workspaceID).
+		Find(&webhooks).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+func (r *webhookRepository) CreateDeliveries(ctx context.Context, deliveries []domain.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return dbFor(ctx, r.db).Create(&deliveries).Error
+}
+
+// ClaimDueDeliveries locks up to limit pending deliveries that are due and
+// pushes their next attempt back by lease, so that other workers (including
+// those in other replicas) skip them while this one is sending. Each
+// delivery comes with its webhook, marked OwnerRemoved if the owner has
+// since left the workspace.
+func (r *webhookRepository) ClaimDueDeliveries(
+	ctx context.Context, now time.Time, lease time.Duration, limit int,
+) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+
+	err := dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", domain.DeliveryPending, now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uuid.UUID, len(deliveries))
+		for i, delivery := range deliveries {
+			ids[i] = delivery.ID
+		}
+
+		return tx.Model(&domain.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	if len(deliveries) == 0 {
+		return nil, nil
+	}
+
+	// Load the target webhooks outside the locking query; FOR UPDATE cannot
+	// be combined with the joins a preload may need.
+	webhookIDs := make([]uuid.UUID, len(deliveries))
+	for i, delivery := range deliveries {
+		webhookIDs[i] = delivery.WebhookID
+	}
+
+	var webhooks []domain.Webhook
+	if err := dbFor(ctx, r.db).Unscoped().Where("id IN ?", webhookIDs).Find(&webhooks).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch webhooks: %w", err)
+	}
+
+	// A delivery queued before its owner left the workspace must not go out
+	// afterwards, so membership is checked again at delivery time.
+	var kept []uuid.UUID
+	err = dbFor(ctx, r.db).Unscoped().Model(&domain.Webhook{}).Scopes(ownerIsMember).
+		Where("id IN ?", webhookIDs).
+		Pluck("id", &kept).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to check webhook owners: %w", err)
+	}
+	members := make(map[uuid.UUID]bool, len(kept))
+	for _, id := range kept {
+		members[id] = true
+	}
+
+	byID := make(map[uuid.UUID]domain.Webhook, len(webhooks))
+	for _, webhook := range webhooks {
+		webhook.OwnerRemoved = !members[webhook.ID]
+		byID[webhook.ID] = webhook
+	}
+	for i := range deliveries {
+		deliveries[i].Webhook = byID[deliveries[i].WebhookID]
+	}
+
+	return deliveries, nil
+}
+
+func (r *webhookRepository) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	return dbFor(ctx, r.db).Omit(clause.Associations).Save(delivery).Error
+}
+
+func (r *webhookRepository) GetDeliveries(
+	ctx context.Context, webhookID uuid.UUID, pagination dto.PaginationQuery,
+) ([]domain.WebhookDelivery, string, error) {
+	var deliveries []domain.WebhookDelivery
+
+	order, err := parseSortOrder("", nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Set default limit
+	if pagination.Limit == 0 {
+		pagination.Limit = 10
+	}
+
+	// Apply cursor pagination
+	query, err := order.applyCursor(dbFor(ctx, r.db).Model(&domain.WebhookDelivery{}).Where("webhook_id = ?", webhookID), pagination.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Fetch one extra record to check if there are more pages
+	query = query.Order(order.orderClause()).Limit(pagination.Limit + 1)
+
+	if err := query.Find(&deliveries).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to fetch webhook deliveries: %w", err)
+	}
+
+	// Generate next cursor
+	var nextCursor string
+	if len(deliveries) > pagination.Limit {
+		deliveries = deliveries[:pagination.Limit] // Remove extra record
+		lastDelivery := deliveries[len(deliveries)-1]
+		nextCursor = order.nextCursor(lastDelivery.ID, lastDelivery.CreatedAt, nil)
+	}
+
+	return deliveries, nextCursor, nil
+}
+
+// ownerIsMember keeps the webhooks whose owner is still a member of the
+// webhook's workspace.
+func ownerIsMember(db *gorm.DB) *gorm.DB {
+	return db.Where(
+		"EXISTS (SELECT 1 FROM workspace_members WHERE workspace_members.user_id = webhooks.owner_id " +
+			"AND workspace_members.workspace_id = webhooks.workspace_id)",
+	)
+}
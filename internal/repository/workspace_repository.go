@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/dto"
+)
+
+// ErrInvitationUsed is returned when accepting an invitation that has
+// already been accepted.
+var ErrInvitationUsed = errors.New("invitation already accepted")
+
+type workspaceRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkspaceRepository(db *gorm.DB) WorkspaceRepository {
+	return &workspaceRepository{db: db}
+}
+
+// Create inserts a workspace and makes ownerID its owner in the same
+// transaction.
+func (r *workspaceRepository) Create(ctx context.Context, workspace *domain.Workspace, ownerID uuid.UUID) error {
+	return dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(workspace).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&domain.WorkspaceMember{
+			WorkspaceID: workspace.ID,
+			UserID:      ownerID,
+			Role:        domain.WorkspaceRoleOwner,
+		}).Error
+	})
+}
+
+func (r *workspaceRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Workspace, error) {
+	var workspace domain.Workspace
+	err := dbFor(ctx, r.db).First(&workspace, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+func (r *workspaceRepository) Update(ctx context.Context, workspace *domain.Workspace) error {
+	return dbFor(ctx, r.db).Save(workspace).Error
+}
+
+// GetMember returns a user's membership of a workspace.
+func (r *workspaceRepository) GetMember(ctx context.Context, workspaceID, userID uuid.UUID) (*domain.WorkspaceMember, error) {
+	var member domain.WorkspaceMember
+	err := dbFor(ctx, r.db).Preload("Workspace").Preload("User").
+		First(&member, "workspace_id = ? AND user_id = ?", workspaceID, userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// GetDefaultMember returns the user's oldest membership, which picks the
+// workspace used when a request does not name one.
+func (r *workspaceRepository) GetDefaultMember(ctx context.Context, userID uuid.UUID) (*domain.WorkspaceMember, error) {
+	var member domain.WorkspaceMember
+	err := dbFor(ctx, r.db).Preload("Workspace").Preload("User").
+		Where("user_id = ?", userID).
+		Order("created_at, id").
+		First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// GetMembers lists a workspace's members, newest first.
+func (r *workspaceRepository) GetMembers(
+	ctx context.Context, workspaceID uuid.UUID, pagination dto.PaginationQuery,
+) ([]domain.WorkspaceMember, string, error) {
+	query := dbFor(ctx, r.db).Model(&domain.WorkspaceMember{}).Preload("User").Where("workspace_id = ?", workspaceID)
+	return r.listMembers(query, pagination)
+}
+
+// GetByUserID lists the memberships of a user, with their workspaces,
+// newest first.
+func (r *workspaceRepository) GetByUserID(
+	ctx context.Context, userID uuid.UUID, pagination dto.PaginationQuery,
+) ([]domain.WorkspaceMember, string, error) {
+	query := dbFor(ctx, r.db).Model(&domain.WorkspaceMember{}).Preload("Workspace").Where("user_id = ?", userID)
+	return r.listMembers(query, pagination)
+}
+
+func (r *workspaceRepository) listMembers(query *gorm.DB, pagination dto.PaginationQuery) ([]domain.WorkspaceMember, string, error) {
+	var members []domain.WorkspaceMember
+
+	order, err := parseSortOrder("", nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Set default limit
+	if pagination.Limit == 0 {
+		pagination.Limit = 10
+	}
+
+	// Apply cursor pagination
+	query, err = order.applyCursor(query, pagination.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Fetch one extra record to check if there are more pages
+	query = query.Order(order.orderClause()).Limit(pagination.Limit + 1)
+
+	if err := query.Find(&members).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to fetch workspace members: %w", err)
+	}
+
+	// Generate next cursor
+	var nextCursor string
+	if len(members) > pagination.Limit {
+		members = members[:pagination.Limit] // Remove extra record
+		lastMember := members[len(members)-1]
+		nextCursor = order.nextCursor(lastMember.ID, lastMember.CreatedAt, nil)
+	}
+
+	return members, nextCursor, nil
+}
+
+func (r *workspaceRepository) UpdateMember(ctx context.Context, member *domain.WorkspaceMember) error {
+	return dbFor(ctx, r.db).Omit("Workspace", "User").Save(member).Error
+}
+
+func (r *workspaceRepository) RemoveMember(ctx context.Context, workspaceID, userID uuid.UUID) error {
+	return dbFor(ctx, r.db).Delete(&domain.WorkspaceMember{}, "workspace_id = ? AND user_id = ?", workspaceID, userID).Error
+}
+
+func (r *workspaceRepository) CreateInvitation(ctx context.Context, invitation *domain.WorkspaceInvitation) error {
+	return dbFor(ctx, r.db).Create(invitation).Error
+}
+
+func (r *workspaceRepository) GetInvitationByID(ctx context.Context, id uuid.UUID) (*domain.WorkspaceInvitation, error) {
+	var invitation domain.WorkspaceInvitation
+	err := dbFor(ctx, r.db).First(&invitation, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *workspaceRepository) GetInvitationByTokenHash(ctx context.Context, hash string) (*domain.WorkspaceInvitation, error) {
+	var invitation domain.WorkspaceInvitation
+	err := dbFor(ctx, r.db).First(&invitation, "token_hash = ?", hash).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// GetPendingInvitations lists a workspace's invitations that can still be
+// accepted at now, newest first.
+func (r *workspaceRepository) GetPendingInvitations(
+	ctx context.Context, workspaceID uuid.UUID, now time.Time, pagination dto.PaginationQuery,
+) ([]domain.WorkspaceInvitation, string, error) {
+	var invitations []domain.WorkspaceInvitation
+
+	order, err := parseSortOrder("", nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Set default limit
+	if pagination.Limit == 0 {
+		pagination.Limit = 10
+	}
+
+	// Apply cursor pagination
+	query := dbFor(ctx, r.db).Model(&domain.WorkspaceInvitation{}).
+		Where("workspace_id = ? AND accepted_at IS NULL AND expires_at > ?", workspaceID, now)
+	query, err = order.applyCursor(query, pagination.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Fetch one extra record to check if there are more pages
+	query = query.Order(order.orderClause()).Limit(pagination.Limit + 1)
+
+	if err := query.Find(&invitations).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to fetch invitations: %w", err)
+	}
+
+	// Generate next cursor
+	var nextCursor string
+	if len(invitations) > pagination.Limit {
+		invitations = invitations[:pagination.Limit] // Remove extra record
+		lastInvitation := invitations[len(invitations)-1]
+		nextCursor = order.nextCursor(lastInvitation.ID, lastInvitation.CreatedAt, nil)
+	}
+
+	return invitations, nextCursor, nil
+}
+
+func (r *workspaceRepository) DeleteInvitation(ctx context.Context, id uuid.UUID) error {
+	return dbFor(ctx, r.db).Delete(&domain.WorkspaceInvitation{}, "id = ?", id).Error
+}
+
+// AcceptInvitation marks an invitation accepted and adds the member it
+// invites, in one transaction. It returns ErrInvitationUsed if the
+// invitation was accepted first by another request.
+func (r *workspaceRepository) AcceptInvitation(
+	ctx context.Context, invitation *domain.WorkspaceInvitation, member *domain.WorkspaceMember,
+) error {
+	return dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.WorkspaceInvitation{}).
+			Where("id = ? AND accepted_at IS NULL", invitation.ID).
+			Update("accepted_at", invitation.AcceptedAt)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvitationUsed
+		}
+
+		return tx.Omit("Workspace", "User").Create(member).Error
+	})
+}
@@ -0,0 +1,329 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kanutocd/tada/internal/config"
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/dto"
+	"github.com/kanutocd/tada/internal/repository"
+)
+
+// TadaActionDeleteAttachment is reported in a ForbiddenError when someone
+// may not remove an attachment.
+const TadaActionDeleteAttachment TadaAction = "delete an attachment on"
+
+// ReasonNotUploader is reported when someone other than an attachment's
+// uploader tries to remove it.
+const ReasonNotUploader = "not_uploader"
+
+// maxFilenameLength matches the width of the attachments.filename column.
+const maxFilenameLength = 255
+
+// AttachmentUpload is a file received for a tada. ContentType is the type
+// the client declared, if any; Size is the length of Content in bytes.
+type AttachmentUpload struct {
+	Filename    string
+	ContentType string
+	Size        int64
+	Content     io.Reader
+}
+
+type AttachmentService interface {
+	UploadAttachment(ctx context.Context, actor *domain.User, tadaID uuid.UUID, upload AttachmentUpload) (*dto.AttachmentResponse, error)
+	GetAttachments(ctx context.Context, tadaID uuid.UUID, pagination dto.PaginationQuery) (*dto.PaginationResponse, error)
+	OpenAttachment(ctx context.Context, tadaID, id uuid.UUID) (*dto.AttachmentResponse, io.ReadCloser, error)
+	DeleteAttachment(ctx context.Context, actor *domain.User, tadaID, id uuid.UUID) error
+
+	// InWorkspace returns the service confined to the tadas of a workspace.
+	InWorkspace(workspaceID uuid.UUID) AttachmentService
+}
+
+type attachmentService struct {
+	attachmentRepo repository.AttachmentRepository
+	tadaRepo       repository.TadaRepository
+	blobStore      repository.BlobStore
+	config         config.AttachmentConfig
+}
+
+func NewAttachmentService(
+	attachmentRepo repository.AttachmentRepository,
+	tadaRepo repository.TadaRepository,
+	blobStore repository.BlobStore,
+	cfg config.AttachmentConfig,
+) AttachmentService {
+	return &attachmentService{
+		attachmentRepo: attachmentRepo,
+		tadaRepo:       tadaRepo,
+		blobStore:      blobStore,
+		config:         cfg,
+	}
+}
+
+func (s *attachmentService) InWorkspace(workspaceID uuid.UUID) AttachmentService {
+	scoped := *s
+	scoped.tadaRepo = s.tadaRepo.InWorkspace(workspaceID)
+	return &scoped
+}
+
+// UploadAttachment stores an upload's contents and records it against the
+// tada. When the client declares no content type, or only
+// application/octet-stream, it is detected from the contents.
+func (s *attachmentService) UploadAttachment(
+	ctx context.Context, actor *domain.User, tadaID uuid.UUID, upload AttachmentUpload,
+) (*dto.AttachmentResponse, error) {
+	if upload.Size > s.config.MaxSize {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	if _, err := s.getTada(ctx, tadaID); err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(upload.Content, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	head = head[:n]
+
+	contentType := detectContentType(upload.ContentType, head)
+	if !s.allowsContentType(contentType) {
+		return nil, ErrContentTypeBlocked
+	}
+
+	attachment := &domain.Attachment{
+		ID:          uuid.New(),
+		TadaID:      tadaID,
+		UploadedBy:  actor.ID,
+		Filename:    sanitizeFilename(upload.Filename),
+		ContentType: contentType,
+		Uploader:    *actor,
+	}
+	attachment.StorageKey = fmt.Sprintf("tadas/%s/%s", tadaID, attachment.ID)
+
+	// Hash and count the contents as they stream to the store, reading at
+	// most one byte past the limit so an understated Size is caught.
+	hash := sha256.New()
+	counter := &countingWriter{}
+	content := io.TeeReader(
+		io.LimitReader(io.MultiReader(bytes.NewReader(head), upload.Content), s.config.MaxSize+1),
+		io.MultiWriter(hash, counter),
+	)
+
+	if err := s.blobStore.Put(ctx, attachment.StorageKey, content, upload.Size, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+
+	if counter.n != upload.Size {
+		s.deleteBlob(attachment.StorageKey)
+		if counter.n > s.config.MaxSize {
+			return nil, ErrAttachmentTooLarge
+		}
+		return nil, fmt.Errorf("attachment was %d bytes, expected %d", counter.n, upload.Size)
+	}
+
+	attachment.Size = counter.n
+	attachment.Checksum = hex.EncodeToString(hash.Sum(nil))
+
+	if err := s.attachmentRepo.Create(ctx, attachment); err != nil {
+		s.deleteBlob(attachment.StorageKey)
+		return nil, fmt.Errorf("failed to create attachment: %w", err)
+	}
+
+	return dto.ToAttachmentResponse(attachment), nil
+}
+
+func (s *attachmentService) GetAttachments(
+	ctx context.Context, tadaID uuid.UUID, pagination dto.PaginationQuery,
+) (*dto.PaginationResponse, error) {
+	if _, err := s.getTada(ctx, tadaID); err != nil {
+		return nil, err
+	}
+
+	attachments, nextCursor, err := s.attachmentRepo.GetByTadaID(ctx, tadaID, pagination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+
+	attachmentResponses := make([]dto.AttachmentResponse, len(attachments))
+	for i, attachment := range attachments {
+		attachmentResponses[i] = *dto.ToAttachmentResponse(&attachment)
+	}
+
+	return &dto.PaginationResponse{
+		Data: attachmentResponses,
+		Pagination: dto.PaginationMeta{
+			Limit:      pagination.Limit,
+			Count:      len(attachmentResponses),
+			NextCursor: nextCursor,
+		},
+	}, nil
+}
+
+// OpenAttachment returns an attachment with a reader for its contents,
+// which the caller must close.
+func (s *attachmentService) OpenAttachment(ctx context.Context, tadaID, id uuid.UUID) (*dto.AttachmentResponse, io.ReadCloser, error) {
+	attachment, err := s.getAttachment(ctx, tadaID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := s.blobStore.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open attachment: %w", err)
+	}
+
+	return dto.ToAttachmentResponse(attachment), content, nil
+}
+
+// DeleteAttachment removes an attachment. Its uploader and the tada's
+// creator may delete it.
+func (s *attachmentService) DeleteAttachment(ctx context.Context, actor *domain.User, tadaID, id uuid.UUID) error {
+	tada, err := s.getTada(ctx, tadaID)
+	if err != nil {
+		return err
+	}
+
+	attachment, err := s.getAttachment(ctx, tadaID, id)
+	if err != nil {
+		return err
+	}
+
+	if attachment.UploadedBy != actor.ID && tada.CreatedBy != actor.ID {
+		return &ForbiddenError{
+			Action:  TadaActionDeleteAttachment,
+			Reason:  ReasonNotUploader,
+			Message: "only the uploader or the tada's creator may delete this attachment",
+		}
+	}
+
+	if err := s.attachmentRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+
+	// The record is gone, so a blob left behind is only wasted space.
+	if err := s.blobStore.Delete(ctx, attachment.StorageKey); err != nil {
+		log.Printf("Failed to delete contents of attachment %s: %v", attachment.ID, err)
+	}
+
+	return nil
+}
+
+func (s *attachmentService) getTada(ctx context.Context, id uuid.UUID) (*domain.Tada, error) {
+	tada, err := s.tadaRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTadaNotFound
+		}
+		return nil, fmt.Errorf("failed to get tada: %w", err)
+	}
+	return tada, nil
+}
+
+// getAttachment loads an attachment, treating attachments on other tadas
+// as missing.
+func (s *attachmentService) getAttachment(ctx context.Context, tadaID, id uuid.UUID) (*domain.Attachment, error) {
+	attachment, err := s.attachmentRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+
+	if attachment.TadaID != tadaID {
+		return nil, ErrAttachmentNotFound
+	}
+
+	return attachment, nil
+}
+
+// deleteBlob cleans up after a failed upload. It deliberately ignores the
+// request's context, which may be why the upload failed.
+func (s *attachmentService) deleteBlob(key string) {
+	if err := s.blobStore.Delete(context.Background(), key); err != nil {
+		log.Printf("Failed to clean up blob %s: %v", key, err)
+	}
+}
+
+// allowsContentType reports whether contentType matches the allowlist,
+// where an entry like "image/*" matches every image type.
+func (s *attachmentService) allowsContentType(contentType string) bool {
+	if len(s.config.AllowedContentTypes) == 0 {
+		return true
+	}
+
+	for _, allowed := range s.config.AllowedContentTypes {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed == contentType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(allowed, "*"); ok && strings.HasSuffix(prefix, "/") &&
+			strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// detectContentType returns the declared media type without parameters,
+// or one sniffed from head when none useful was declared.
+func detectContentType(declared string, head []byte) string {
+	if mediaType, _, err := mime.ParseMediaType(declared); err == nil && mediaType != "application/octet-stream" {
+		return mediaType
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	return mediaType
+}
+
+// sanitizeFilename keeps the last element of a client-supplied path, drops
+// control characters and truncates it to fit the filename column.
+func sanitizeFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+
+	if name == "" || name == "." || name == ".." || name == "/" {
+		return "attachment"
+	}
+
+	for len(name) > maxFilenameLength {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+
+	return name
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/kanutocd/tada/internal/config"
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/dto"
+	"github.com/kanutocd/tada/internal/repository"
+)
+
+// Identity is the authenticated caller of a request.
+type Identity struct {
+	User      *domain.User
+	SessionID uuid.UUID
+}
+
+type AuthService interface {
+	Login(ctx context.Context, req dto.LoginRequest) (*dto.TokenResponse, error)
+	Refresh(ctx context.Context, req dto.RefreshRequest) (*dto.TokenResponse, error)
+	Logout(ctx context.Context, sessionID uuid.UUID) error
+	Authenticate(ctx context.Context, accessToken string) (*Identity, error)
+}
+
+type authService struct {
+	userRepo    repository.UserRepository
+	sessionRepo repository.SessionRepository
+	txManager   repository.TxManager
+	config      config.AuthConfig
+}
+
+func NewAuthService(
+	userRepo repository.UserRepository, sessionRepo repository.SessionRepository, txManager repository.TxManager, cfg config.AuthConfig,
+) AuthService {
+	return &authService{
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		txManager:   txManager,
+		config:      cfg,
+	}
+}
+
+// accessClaims are the claims carried by an access token. The subject is
+// the user ID and SessionID ties the token to the session that issued it.
+type accessClaims struct {
+	SessionID string `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+func (s *authService) Login(ctx context.Context, req dto.LoginRequest) (*dto.TokenResponse, error) {
+	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if !checkPassword(user.PasswordHash, req.Password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	refreshToken, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &domain.Session{
+		UserID:           user.ID,
+		RefreshTokenHash: hashToken(refreshToken),
+		ExpiresAt:        time.Now().Add(s.config.RefreshTokenTTL),
+	}
+
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return s.issueTokens(session, refreshToken)
+}
+
+// Refresh exchanges a refresh token for a new token pair. The refresh token
+// is rotated, so each one can only be used once. If two requests present
+// the same token, only one rotates it, and the session is revoked since
+// the token has evidently been copied.
+func (s *authService) Refresh(ctx context.Context, req dto.RefreshRequest) (*dto.TokenResponse, error) {
+	presentedHash := hashToken(req.RefreshToken)
+	session, err := s.sessionRepo.GetByRefreshTokenHash(ctx, presentedHash)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, s.revokeReusedToken(ctx, presentedHash)
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	if !session.Active(time.Now()) {
+		return nil, ErrInvalidToken
+	}
+
+	refreshToken, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session.RefreshTokenHash = hashToken(refreshToken)
+	session.ExpiresAt = time.Now().Add(s.config.RefreshTokenTTL)
+
+	var rotated bool
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		rotated, err = s.sessionRepo.Rotate(ctx, session, presentedHash)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update session: %w", err)
+	}
+	if !rotated {
+		// Another request rotated the token first, so it has been used
+		// twice.
+		return nil, s.revokeReusedToken(ctx, presentedHash)
+	}
+
+	return s.issueTokens(session, refreshToken)
+}
+
+// revokeReusedToken handles a refresh token that is not the current one of
+// any session. If it was retired from a session's token family, it has been
+// replayed, most likely by someone who stole it, so that session is revoked
+// for the legitimate holder as well. It returns the error to report, which
+// is ErrInvalidToken unless the lookup or revocation failed.
+func (s *authService) revokeReusedToken(ctx context.Context, hash string) error {
+	session, err := s.sessionRepo.GetByRetiredRefreshTokenHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidToken
+		}
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+
+	if session.RevokedAt == nil {
+		if err := s.sessionRepo.Revoke(ctx, session.ID, time.Now()); err != nil {
+			return fmt.Errorf("failed to revoke session: %w", err)
+		}
+		log.Printf("Revoked session %s of user %s after its refresh token was reused", session.ID, session.UserID)
+	}
+	return ErrInvalidToken
+}
+
+// Logout revokes a session, invalidating its refresh token and every access
+// token issued for it.
+func (s *authService) Logout(ctx context.Context, sessionID uuid.UUID) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		return s.logout(ctx, sessionID)
+	})
+}
+
+func (s *authService) logout(ctx context.Context, sessionID uuid.UUID) error {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidToken
+		}
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+
+	if session.RevokedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	session.RevokedAt = &now
+
+	if err := s.sessionRepo.Update(ctx, session); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	return nil
+}
+
+func (s *authService) Authenticate(ctx context.Context, accessToken string) (*Identity, error) {
+	var claims accessClaims
+	_, err := jwt.ParseWithClaims(accessToken, &claims, func(*jwt.Token) (interface{}, error) {
+		return []byte(s.config.JWTSecret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(s.config.Issuer), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	if session.UserID != userID || !session.Active(time.Now()) {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return &Identity{User: user, SessionID: session.ID}, nil
+}
+
+func (s *authService) issueTokens(session *domain.Session, refreshToken string) (*dto.TokenResponse, error) {
+	now := time.Now()
+	claims := accessClaims{
+		SessionID: session.ID.String(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   session.UserID.String(),
+			Issuer:    s.config.Issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.config.AccessTokenTTL)),
+		},
+	}
+
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.config.JWTSecret))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	return &dto.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.config.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+func checkPassword(hash, password string) bool {
+	if hash == "" {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// newOpaqueToken returns a random token, as used for refresh tokens and
+// invitations. Only its SHA-256 hash is stored, so a leaked table cannot be
+// replayed.
+func newOpaqueToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/dto"
+	"github.com/kanutocd/tada/internal/repository"
+)
+
+// ChecklistService manages the checklists inside tadas. Changing a
+// checklist counts as updating its tada, so the tada policy decides who
+// may do it.
+type ChecklistService interface {
+	GetChecklist(ctx context.Context, tadaID uuid.UUID) ([]dto.ChecklistItemResponse, error)
+	AddChecklistItem(
+		ctx context.Context, actor *domain.User, tadaID uuid.UUID, req dto.CreateChecklistItemRequest,
+	) (*dto.ChecklistItemResponse, error)
+	UpdateChecklistItem(
+		ctx context.Context, actor *domain.User, tadaID, id uuid.UUID, req dto.UpdateChecklistItemRequest,
+	) (*dto.ChecklistItemResponse, error)
+	ReorderChecklist(
+		ctx context.Context, actor *domain.User, tadaID uuid.UUID, req dto.ReorderChecklistRequest,
+	) ([]dto.ChecklistItemResponse, error)
+	DeleteChecklistItem(ctx context.Context, actor *domain.User, tadaID, id uuid.UUID) error
+
+	// InWorkspace returns the service confined to the tadas of a workspace.
+	InWorkspace(workspaceID uuid.UUID) ChecklistService
+}
+
+type checklistService struct {
+	checklistRepo repository.ChecklistRepository
+	tadaRepo      repository.TadaRepository
+	txManager     repository.TxManager
+	policy        TadaPolicy
+}
+
+func NewChecklistService(
+	checklistRepo repository.ChecklistRepository,
+	tadaRepo repository.TadaRepository,
+	txManager repository.TxManager,
+	policy TadaPolicy,
+) ChecklistService {
+	return &checklistService{
+		checklistRepo: checklistRepo,
+		tadaRepo:      tadaRepo,
+		txManager:     txManager,
+		policy:        policy,
+	}
+}
+
+func (s *checklistService) InWorkspace(workspaceID uuid.UUID) ChecklistService {
+	scoped := *s
+	scoped.tadaRepo = s.tadaRepo.InWorkspace(workspaceID)
+	return &scoped
+}
+
+func (s *checklistService) GetChecklist(ctx context.Context, tadaID uuid.UUID) ([]dto.ChecklistItemResponse, error) {
+	if _, err := s.getTada(ctx, tadaID); err != nil {
+		return nil, err
+	}
+
+	items, err := s.checklistRepo.GetByTadaID(ctx, tadaID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.ToChecklistItemResponses(items), nil
+}
+
+func (s *checklistService) AddChecklistItem(
+	ctx context.Context, actor *domain.User, tadaID uuid.UUID, req dto.CreateChecklistItemRequest,
+) (*dto.ChecklistItemResponse, error) {
+	var response *dto.ChecklistItemResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		response, err = s.addChecklistItem(ctx, actor, tadaID, req)
+		return err
+	})
+	return response, err
+}
+
+func (s *checklistService) addChecklistItem(
+	ctx context.Context, actor *domain.User, tadaID uuid.UUID, req dto.CreateChecklistItemRequest,
+) (*dto.ChecklistItemResponse, error) {
+	if _, err := s.authorize(ctx, actor, tadaID); err != nil {
+		return nil, err
+	}
+
+	item := &domain.ChecklistItem{
+		TadaID: tadaID,
+		Text:   req.Text,
+	}
+
+	if err := s.checklistRepo.Create(ctx, item); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTadaNotFound
+		}
+		return nil, fmt.Errorf("failed to create checklist item: %w", err)
+	}
+
+	return dto.ToChecklistItemResponse(item), nil
+}
+
+// UpdateChecklistItem edits an item's text or checks and unchecks it,
+// recording who checked it and when.
+func (s *checklistService) UpdateChecklistItem(
+	ctx context.Context, actor *domain.User, tadaID, id uuid.UUID, req dto.UpdateChecklistItemRequest,
+) (*dto.ChecklistItemResponse, error) {
+	var response *dto.ChecklistItemResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		response, err = s.updateChecklistItem(ctx, actor, tadaID, id, req)
+		return err
+	})
+	return response, err
+}
+
+func (s *checklistService) updateChecklistItem(
+	ctx context.Context, actor *domain.User, tadaID, id uuid.UUID, req dto.UpdateChecklistItemRequest,
+) (*dto.ChecklistItemResponse, error) {
+	if _, err := s.authorize(ctx, actor, tadaID); err != nil {
+		return nil, err
+	}
+
+	item, err := s.getItem(ctx, tadaID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Text != nil {
+		item.Text = *req.Text
+	}
+
+	if req.Checked != nil && *req.Checked != item.Checked {
+		item.Checked = *req.Checked
+		if item.Checked {
+			now := time.Now()
+			item.CheckedBy = &actor.ID
+			item.CheckedAt = &now
+		} else {
+			item.CheckedBy = nil
+			item.CheckedAt = nil
+		}
+	}
+
+	if err := s.checklistRepo.Update(ctx, item); err != nil {
+		return nil, fmt.Errorf("failed to update checklist item: %w", err)
+	}
+
+	return dto.ToChecklistItemResponse(item), nil
+}
+
+// ReorderChecklist puts a tada's checklist in the order given, which must
+// list every item exactly once. It returns ErrChecklistConflict when the
+// checklist no longer holds the listed items, as happens when another
+// client adds or removes one first.
+func (s *checklistService) ReorderChecklist(
+	ctx context.Context, actor *domain.User, tadaID uuid.UUID, req dto.ReorderChecklistRequest,
+) ([]dto.ChecklistItemResponse, error) {
+	var response []dto.ChecklistItemResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		response, err = s.reorderChecklist(ctx, actor, tadaID, req)
+		return err
+	})
+	return response, err
+}
+
+func (s *checklistService) reorderChecklist(
+	ctx context.Context, actor *domain.User, tadaID uuid.UUID, req dto.ReorderChecklistRequest,
+) ([]dto.ChecklistItemResponse, error) {
+	if _, err := s.authorize(ctx, actor, tadaID); err != nil {
+		return nil, err
+	}
+
+	if err := s.checklistRepo.Reorder(ctx, tadaID, req.ItemIDs); err != nil {
+		if errors.Is(err, repository.ErrChecklistMismatch) {
+			return nil, ErrChecklistConflict
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTadaNotFound
+		}
+		return nil, fmt.Errorf("failed to reorder checklist: %w", err)
+	}
+
+	items, err := s.checklistRepo.GetByTadaID(ctx, tadaID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.ToChecklistItemResponses(items), nil
+}
+
+func (s *checklistService) DeleteChecklistItem(ctx context.Context, actor *domain.User, tadaID, id uuid.UUID) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		return s.deleteChecklistItem(ctx, actor, tadaID, id)
+	})
+}
+
+func (s *checklistService) deleteChecklistItem(ctx context.Context, actor *domain.User, tadaID, id uuid.UUID) error {
+	if _, err := s.authorize(ctx, actor, tadaID); err != nil {
+		return err
+	}
+
+	if _, err := s.getItem(ctx, tadaID, id); err != nil {
+		return err
+	}
+
+	if err := s.checklistRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete checklist item: %w", err)
+	}
+
+	return nil
+}
+
+// authorize loads a tada and checks that actor may update it.
+func (s *checklistService) authorize(ctx context.Context, actor *domain.User, tadaID uuid.UUID) (*domain.Tada, error) {
+	tada, err := s.getTada(ctx, tadaID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Authorize(actor, TadaActionUpdate, tada); err != nil {
+		return nil, err
+	}
+
+	return tada, nil
+}
+
+func (s *checklistService) getTada(ctx context.Context, id uuid.UUID) (*domain.Tada, error) {
+	tada, err := s.tadaRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTadaNotFound
+		}
+		return nil, fmt.Errorf("failed to get tada: %w", err)
+	}
+	return tada, nil
+}
+
+// getItem loads a checklist item, treating items of other tadas as
+// missing.
+func (s *checklistService) getItem(ctx context.Context, tadaID, id uuid.UUID) (*domain.ChecklistItem, error) {
+	item, err := s.checklistRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrChecklistItemNotFound
+		}
+		return nil, fmt.Errorf("failed to get checklist item: %w", err)
+	}
+
+	if item.TadaID != tadaID {
+		return nil, ErrChecklistItemNotFound
+	}
+
+	return item, nil
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/dto"
+	"github.com/kanutocd/tada/internal/repository"
+)
+
+// Actions on comments reported in a ForbiddenError.
+const (
+	TadaActionEditComment   TadaAction = "edit a comment on"
+	TadaActionDeleteComment TadaAction = "delete a comment on"
+)
+
+// ReasonNotAuthor is reported when someone other than a comment's author
+// tries to change it.
+const ReasonNotAuthor = "not_author"
+
+// mentionPattern matches an @mention of a user's email address.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w.@])@([\w.%+\-]+@[\w\-]+(?:\.[\w\-]+)*\.[A-Za-z]{2,})`)
+
+type CommentService interface {
+	CreateComment(ctx context.Context, actor *domain.User, tadaID uuid.UUID, req dto.CreateCommentRequest) (*dto.CommentResponse, error)
+	GetComments(ctx context.Context, tadaID uuid.UUID, pagination dto.PaginationQuery) (*dto.PaginationResponse, error)
+	UpdateComment(ctx context.Context, actor *domain.User, tadaID, id uuid.UUID, req dto.UpdateCommentRequest) (*dto.CommentResponse, error)
+	DeleteComment(ctx context.Context, actor *domain.User, tadaID, id uuid.UUID) error
+
+	// InWorkspace returns the service confined to the tadas of a workspace.
+	InWorkspace(workspaceID uuid.UUID) CommentService
+}
+
+type commentService struct {
+	commentRepo repository.CommentRepository
+	tadaRepo    repository.TadaRepository
+	userRepo    repository.UserRepository
+	txManager   repository.TxManager
+	workspaceID uuid.UUID
+}
+
+func NewCommentService(
+	commentRepo repository.CommentRepository,
+	tadaRepo repository.TadaRepository,
+	userRepo repository.UserRepository,
+	txManager repository.TxManager,
+) CommentService {
+	return &commentService{
+		commentRepo: commentRepo,
+		tadaRepo:    tadaRepo,
+		userRepo:    userRepo,
+		txManager:   txManager,
+	}
+}
+
+func (s *commentService) InWorkspace(workspaceID uuid.UUID) CommentService {
+	scoped := *s
+	scoped.tadaRepo = s.tadaRepo.InWorkspace(workspaceID)
+	scoped.workspaceID = workspaceID
+	return &scoped
+}
+
+func (s *commentService) CreateComment(
+	ctx context.Context, actor *domain.User, tadaID uuid.UUID, req dto.CreateCommentRequest,
+) (*dto.CommentResponse, error) {
+	var response *dto.CommentResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		response, err = s.createComment(ctx, actor, tadaID, req)
+		return err
+	})
+	return response, err
+}
+
+func (s *commentService) createComment(
+	ctx context.Context, actor *domain.User, tadaID uuid.UUID, req dto.CreateCommentRequest,
+) (*dto.CommentResponse, error) {
+	if _, err := s.getTada(ctx, tadaID); err != nil {
+		return nil, err
+	}
+
+	mentions, err := s.resolveMentions(ctx, req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &domain.Comment{
+		TadaID:   tadaID,
+		AuthorID: actor.ID,
+		Body:     req.Body,
+		Mentions: mentions,
+		Author:   *actor,
+	}
+
+	if err := s.commentRepo.Create(ctx, comment); err != nil {
+		return nil, fmt.Errorf("failed to create comment: %w", err)
+	}
+
+	return dto.ToCommentResponse(comment), nil
+}
+
+func (s *commentService) GetComments(
+	ctx context.Context, tadaID uuid.UUID, pagination dto.PaginationQuery,
+) (*dto.PaginationResponse, error) {
+	if _, err := s.getTada(ctx, tadaID); err != nil {
+		return nil, err
+	}
+
+	comments, nextCursor, err := s.commentRepo.GetByTadaID(ctx, tadaID, pagination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comments: %w", err)
+	}
+
+	commentResponses := make([]dto.CommentResponse, len(comments))
+	for i, comment := range comments {
+		commentResponses[i] = *dto.ToCommentResponse(&comment)
+	}
+
+	return &dto.PaginationResponse{
+		Data: commentResponses,
+		Pagination: dto.PaginationMeta{
+			Limit:      pagination.Limit,
+			Count:      len(commentResponses),
+			NextCursor: nextCursor,
+		},
+	}, nil
+}
+
+// UpdateComment edits a comment's body. Only its author may edit it.
+func (s *commentService) UpdateComment(
+	ctx context.Context, actor *domain.User, tadaID, id uuid.UUID, req dto.UpdateCommentRequest,
+) (*dto.CommentResponse, error) {
+	var response *dto.CommentResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		response, err = s.updateComment(ctx, actor, tadaID, id, req)
+		return err
+	})
+	return response, err
+}
+
+func (s *commentService) updateComment(
+	ctx context.Context, actor *domain.User, tadaID, id uuid.UUID, req dto.UpdateCommentRequest,
+) (*dto.CommentResponse, error) {
+	if _, err := s.getTada(ctx, tadaID); err != nil {
+		return nil, err
+	}
+
+	comment, err := s.getComment(ctx, tadaID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.AuthorID != actor.ID {
+		return nil, &ForbiddenError{
+			Action:  TadaActionEditComment,
+			Reason:  ReasonNotAuthor,
+			Message: "only the author may edit this comment",
+		}
+	}
+
+	mentions, err := s.resolveMentions(ctx, req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	comment.Body = req.Body
+	comment.Mentions = mentions
+	comment.EditedAt = &now
+
+	if err := s.commentRepo.Update(ctx, comment); err != nil {
+		return nil, fmt.Errorf("failed to update comment: %w", err)
+	}
+
+	return dto.ToCommentResponse(comment), nil
+}
+
+// DeleteComment removes a comment. Its author and the tada's creator may
+// delete it.
+func (s *commentService) DeleteComment(ctx context.Context, actor *domain.User, tadaID, id uuid.UUID) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		return s.deleteComment(ctx, actor, tadaID, id)
+	})
+}
+
+func (s *commentService) deleteComment(ctx context.Context, actor *domain.User, tadaID, id uuid.UUID) error {
+	tada, err := s.getTada(ctx, tadaID)
+	if err != nil {
+		return err
+	}
+
+	comment, err := s.getComment(ctx, tadaID, id)
+	if err != nil {
+		return err
+	}
+
+	if comment.AuthorID != actor.ID && tada.CreatedBy != actor.ID {
+		return &ForbiddenError{
+			Action:  TadaActionDeleteComment,
+			Reason:  ReasonNotAuthor,
+			Message: "only the author or the tada's creator may delete this comment",
+		}
+	}
+
+	if err := s.commentRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+
+	return nil
+}
+
+func (s *commentService) getTada(ctx context.Context, id uuid.UUID) (*domain.Tada, error) {
+	tada, err := s.tadaRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTadaNotFound
+		}
+		return nil, fmt.Errorf("failed to get tada: %w", err)
+	}
+	return tada, nil
+}
+
+// getComment loads a comment, treating comments on other tadas as missing.
+func (s *commentService) getComment(ctx context.Context, tadaID, id uuid.UUID) (*domain.Comment, error) {
+	comment, err := s.commentRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+
+	if comment.TadaID != tadaID {
+		return nil, ErrCommentNotFound
+	}
+
+	return comment, nil
+}
+
+// resolveMentions returns the IDs of the users whose emails are @mentioned
+// in body, in order of first mention. Only members of the service's
+// workspace can be mentioned; other emails are ignored, so that comments
+// do not reveal who has an account elsewhere.
+func (s *commentService) resolveMentions(ctx context.Context, body string) (domain.UUIDList, error) {
+	mentions := domain.UUIDList{}
+	seen := make(map[string]bool)
+
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		email := strings.TrimRight(match[1], ".")
+		if seen[strings.ToLower(email)] {
+			continue
+		}
+		seen[strings.ToLower(email)] = true
+
+		user, err := s.userRepo.GetMemberByEmail(ctx, s.workspaceID, email)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve mention of %s: %w", email, err)
+		}
+		mentions = append(mentions, user.ID)
+	}
+
+	return mentions, nil
+}
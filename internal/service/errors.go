@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/dto"
+)
+
+var (
+	ErrUserNotFound          = errors.New("user not found")
+	ErrEmailTaken            = errors.New("email already exists")
+	ErrTadaNotFound          = errors.New("tada not found")
+	ErrInvalidCredentials    = errors.New("invalid email or password")
+	ErrInvalidToken          = errors.New("invalid or expired token")
+	ErrWebhookNotFound       = errors.New("webhook not found")
+	ErrInvalidEventType      = errors.New("invalid event type")
+	ErrInvalidWebhookURL     = errors.New("webhook URL must be an http or https URL of a public host")
+	ErrTagNotFound           = errors.New("tag not found")
+	ErrTagExists             = errors.New("tag already exists")
+	ErrInvalidTagName        = errors.New("invalid tag name")
+	ErrParentNotFound        = errors.New("parent tada not found")
+	ErrTadaCycle             = errors.New("a tada cannot be nested under itself or its subtasks")
+	ErrIncompleteSubtasks    = errors.New("tada has subtasks in progress")
+	ErrHasSubtasks           = errors.New("tada has subtasks")
+	ErrTadaBlocked           = errors.New("tada is blocked by tadas in progress")
+	ErrDependencyCycle       = errors.New("dependency would create a cycle")
+	ErrDependencyExists      = errors.New("dependency already exists")
+	ErrDependencyNotFound    = errors.New("dependency not found")
+	ErrInvalidRecurrence     = domain.ErrInvalidRecurrence
+	ErrRecurrenceNoDueAt     = errors.New("a recurring tada needs a due date")
+	ErrNotRecurring          = errors.New("tada does not recur")
+	ErrCommentNotFound       = errors.New("comment not found")
+	ErrAttachmentNotFound    = errors.New("attachment not found")
+	ErrAttachmentTooLarge    = errors.New("attachment is too large")
+	ErrContentTypeBlocked    = errors.New("attachment content type is not allowed")
+	ErrChecklistItemNotFound = errors.New("checklist item not found")
+	ErrChecklistConflict     = errors.New("checklist has changed; reload it and try again")
+	ErrProjectNotFound       = errors.New("project not found")
+	ErrProjectArchived       = errors.New("project is archived")
+	ErrProjectNotEmpty       = errors.New("project still has tadas")
+	ErrWorkspaceNotFound     = errors.New("workspace not found")
+	ErrNoWorkspace           = errors.New("not a member of any workspace")
+	ErrMemberNotFound        = errors.New("workspace member not found")
+	ErrAlreadyMember         = errors.New("already a member of the workspace")
+	ErrNotMember             = errors.New("user is not a member of the workspace")
+	ErrInvitationNotFound    = errors.New("invitation not found")
+	ErrInvitationExpired     = errors.New("invitation has expired or was already accepted")
+	ErrInvalidWorkflow       = domain.ErrInvalidWorkflow
+	ErrWorkflowInUse         = errors.New("tadas of the project are in states the workflow does not have")
+	ErrInvalidStatus         = errors.New("status is not a state of the tada's workflow")
+	ErrStatusTransition      = errors.New("the workflow does not allow this status change")
+	ErrPreconditionFailed    = errors.New("current version does not match If-Match")
+	ErrEditConflict          = errors.New("record was changed by someone else; reload it and try again")
+	ErrInvalidBulk           = errors.New("invalid bulk request")
+	ErrBulkTooLarge          = errors.New("bulk request is too large")
+	ErrIdempotencyKeyReused  = errors.New("idempotency key was already used for a different request")
+	ErrIdempotencyKeyBusy    = errors.New("a request with this idempotency key is still in progress")
+	ErrIdempotencyClaimLost  = errors.New("idempotency key was taken over by another request")
+)
+
+// checkVersion returns ErrPreconditionFailed unless a record at version
+// satisfies ifMatch.
+func checkVersion(ifMatch dto.IfMatch, version int) error {
+	if !ifMatch.Matches(version) {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
+
+// versionConflict explains a save that lost a race with another writer. A
+// caller who named the version they expected sees their precondition fail;
+// anyone else is told to reload and retry.
+func versionConflict(ifMatch dto.IfMatch) error {
+	if ifMatch.Conditional {
+		return ErrPreconditionFailed
+	}
+	return ErrEditConflict
+}
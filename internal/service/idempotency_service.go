@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kanutocd/tada/internal/config"
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/repository"
+)
+
+// idempotencyPollInterval is how often a retry checks whether the request
+// holding its key has finished.
+const idempotencyPollInterval = 100 * time.Millisecond
+
+// IdempotencyService lets a request sent with an Idempotency-Key run once,
+// with retries of it getting the response it had.
+type IdempotencyService interface {
+	// Begin claims key within scope for a request with the given
+	// fingerprint. When the caller now holds the key it returns the claim,
+	// which is not Completed: the caller must run the request, keeping
+	// the claim alive with Hold, then Finish or Abandon it. When the
+	// request already ran it returns the stored, Completed record. While
+	// another request holds the key it waits for that request to finish,
+	// up to the lock timeout, and then returns ErrIdempotencyKeyBusy. A
+	// key used for a request with another fingerprint gives
+	// ErrIdempotencyKeyReused.
+	Begin(ctx context.Context, scope, key, fingerprint string) (*domain.IdempotencyKey, error)
+
+	// Hold keeps the claim's lock from lapsing until ctx is done, so that
+	// no retry takes the key over while the request is still running. It
+	// returns ErrIdempotencyClaimLost if the key was taken over anyway.
+	Hold(ctx context.Context, claim *domain.IdempotencyKey) error
+
+	// Finish stores the response to the request holding the claim. It
+	// returns ErrIdempotencyClaimLost if the key was taken over.
+	Finish(ctx context.Context, claim *domain.IdempotencyKey, status int, contentType string, body []byte) error
+
+	// Abandon releases the claim's key without storing a response, so
+	// that a retry runs the request again. A key taken over by another
+	// request is left alone.
+	Abandon(ctx context.Context, claim *domain.IdempotencyKey) error
+}
+
+type idempotencyService struct {
+	idempotencyRepo repository.IdempotencyKeyRepository
+	config          config.IdempotencyConfig
+}
+
+func NewIdempotencyService(idempotencyRepo repository.IdempotencyKeyRepository, cfg config.IdempotencyConfig) IdempotencyService {
+	return &idempotencyService{
+		idempotencyRepo: idempotencyRepo,
+		config:          cfg,
+	}
+}
+
+func (s *idempotencyService) Begin(ctx context.Context, scope, key, fingerprint string) (*domain.IdempotencyKey, error) {
+	deadline := time.Now().Add(s.config.LockTimeout)
+	for {
+		now := time.Now()
+		claim := &domain.IdempotencyKey{
+			Scope:       scope,
+			Key:         key,
+			Fingerprint: fingerprint,
+			Token:       uuid.New(),
+			LockedUntil: now.Add(s.config.LockTimeout),
+			ExpiresAt:   now.Add(s.config.TTL),
+		}
+		claimed, err := s.idempotencyRepo.Claim(ctx, claim, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim idempotency key: %w", err)
+		}
+		if claimed {
+			// Expired keys are only taken over when reused, so clear out
+			// the rest while here. The claim stands if this fails.
+			if _, err := s.idempotencyRepo.DeleteExpired(ctx, now); err != nil {
+				log.Printf("Failed to delete expired idempotency keys: %v", err)
+			}
+			return claim, nil
+		}
+
+		// A record released since the claim failed is claimed next time.
+		record, err := s.idempotencyRepo.Get(ctx, scope, key)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+		}
+		if record != nil {
+			if record.Fingerprint != fingerprint {
+				return nil, ErrIdempotencyKeyReused
+			}
+			if record.Completed() {
+				return record, nil
+			}
+		}
+
+		if !now.Before(deadline) {
+			return nil, ErrIdempotencyKeyBusy
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(idempotencyPollInterval):
+		}
+	}
+}
+
+// Hold pushes the claim's lock back by the lock timeout a few times within
+// each lock timeout, so that a slow database does not let it lapse.
+func (s *idempotencyService) Hold(ctx context.Context, claim *domain.IdempotencyKey) error {
+	ticker := time.NewTicker(s.config.LockTimeout / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		held, err := s.idempotencyRepo.Extend(ctx, &domain.IdempotencyKey{
+			Scope:       claim.Scope,
+			Key:         claim.Key,
+			Token:       claim.Token,
+			LockedUntil: time.Now().Add(s.config.LockTimeout),
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			// The lock holds until LockedUntil, so a later try may
+			// still be in time.
+			log.Printf("Failed to extend idempotency key lock: %v", err)
+			continue
+		}
+		if !held {
+			return ErrIdempotencyClaimLost
+		}
+	}
+}
+
+func (s *idempotencyService) Finish(
+	ctx context.Context,
+	claim *domain.IdempotencyKey,
+	status int,
+	contentType string,
+	body []byte,
+) error {
+	held, err := s.idempotencyRepo.Complete(ctx, &domain.IdempotencyKey{
+		Scope:       claim.Scope,
+		Key:         claim.Key,
+		Token:       claim.Token,
+		Status:      status,
+		ContentType: contentType,
+		Body:        body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	if !held {
+		return ErrIdempotencyClaimLost
+	}
+	return nil
+}
+
+func (s *idempotencyService) Abandon(ctx context.Context, claim *domain.IdempotencyKey) error {
+	if err := s.idempotencyRepo.Delete(ctx, claim); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
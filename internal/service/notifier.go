@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kanutocd/tada/internal/config"
+	"github.com/kanutocd/tada/internal/domain"
+)
+
+// Notification is a message for a single user.
+type Notification struct {
+	Recipient domain.User
+	Subject   string
+	Body      string
+}
+
+// Notifier delivers notifications to users.
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// NewNotifier returns the notifier selected by cfg.Driver.
+func NewNotifier(cfg config.NotificationConfig) (Notifier, error) {
+	switch cfg.Driver {
+	case "", "log":
+		return LogNotifier{}, nil
+	case "smtp":
+		if cfg.SMTP.Host == "" || cfg.SMTP.From == "" {
+			return nil, fmt.Errorf("notifications.smtp.host and notifications.smtp.from must be set")
+		}
+		return NewSMTPNotifier(cfg.SMTP), nil
+	default:
+		return nil, fmt.Errorf("unknown notification driver %q", cfg.Driver)
+	}
+}
+
+// LogNotifier writes notifications to the server log. It suits development
+// and deployments without email.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, notification Notification) error {
+	log.Printf("Notification to %s <%s>: %s", notification.Recipient.Name, notification.Recipient.Email, notification.Subject)
+	return nil
+}
+
+// SMTPNotifier emails notifications as plain text.
+type SMTPNotifier struct {
+	config config.SMTPConfig
+}
+
+func NewSMTPNotifier(cfg config.SMTPConfig) *SMTPNotifier {
+	return &SMTPNotifier{config: cfg}
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, notification Notification) error {
+	addr := net.JoinHostPort(n.config.Host, strconv.Itoa(n.config.Port))
+
+	var auth smtp.Auth
+	if n.config.Username != "" {
+		auth = smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host)
+	}
+
+	msg := buildEmail(n.config.From, notification)
+
+	// smtp.SendMail takes no context, so honour cancellation by not
+	// waiting for it.
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, n.config.From, []string{notification.Recipient.Email}, msg)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to send email to %s: %w", notification.Recipient.Email, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func buildEmail(from string, notification Notification) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + notification.Recipient.Email + "\r\n")
+	b.WriteString("Subject: " + sanitizeHeader(notification.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(notification.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// sanitizeHeader keeps user-provided text, such as a tada name, from
+// injecting extra headers.
+func sanitizeHeader(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
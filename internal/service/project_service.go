@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/dto"
+	"github.com/kanutocd/tada/internal/repository"
+)
+
+// ReasonNotOwner is reported when someone other than a project's owner
+// tries to change it.
+const ReasonNotOwner = "not_owner"
+
+type ProjectService interface {
+	CreateProject(ctx context.Context, actor *domain.User, req dto.CreateProjectRequest) (*dto.ProjectResponse, error)
+	GetProjectByID(ctx context.Context, id uuid.UUID) (*dto.ProjectResponse, error)
+	GetProjects(ctx context.Context, filter dto.ProjectFilter, pagination dto.PaginationQuery) (*dto.PaginationResponse, error)
+	UpdateProject(ctx context.Context, actor *domain.User, id uuid.UUID, req dto.UpdateProjectRequest) (*dto.ProjectResponse, error)
+	DeleteProject(ctx context.Context, actor *domain.User, id uuid.UUID) error
+	GetProjectStats(ctx context.Context, id uuid.UUID) (*dto.ProjectStatsResponse, error)
+
+	// InWorkspace returns the service confined to a workspace's projects.
+	InWorkspace(workspaceID uuid.UUID) ProjectService
+}
+
+type projectService struct {
+	projectRepo repository.ProjectRepository
+	txManager   repository.TxManager
+}
+
+func NewProjectService(projectRepo repository.ProjectRepository, txManager repository.TxManager) ProjectService {
+	return &projectService{
+		projectRepo: projectRepo,
+		txManager:   txManager,
+	}
+}
+
+func (s *projectService) InWorkspace(workspaceID uuid.UUID) ProjectService {
+	scoped := *s
+	scoped.projectRepo = s.projectRepo.InWorkspace(workspaceID)
+	return &scoped
+}
+
+func (s *projectService) CreateProject(
+	ctx context.Context, actor *domain.User, req dto.CreateProjectRequest,
+) (*dto.ProjectResponse, error) {
+	var response *dto.ProjectResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		response, err = s.createProject(ctx, actor, req)
+		return err
+	})
+	return response, err
+}
+
+func (s *projectService) createProject(
+	ctx context.Context, actor *domain.User, req dto.CreateProjectRequest,
+) (*dto.ProjectResponse, error) {
+	project := &domain.Project{
+		Name:        req.Name,
+		Description: req.Description,
+		OwnerID:     actor.ID,
+		Owner:       *actor,
+	}
+
+	if req.Workflow != nil {
+		if err := req.Workflow.Validate(); err != nil {
+			return nil, err
+		}
+		project.Workflow = *req.Workflow
+	}
+
+	if err := s.projectRepo.Create(ctx, project); err != nil {
+		return nil, fmt.Errorf("failed to create project: %w", err)
+	}
+
+	return dto.ToProjectResponse(project), nil
+}
+
+func (s *projectService) GetProjectByID(ctx context.Context, id uuid.UUID) (*dto.ProjectResponse, error) {
+	project, err := s.getProject(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.ToProjectResponse(project), nil
+}
+
+func (s *projectService) GetProjects(
+	ctx context.Context, filter dto.ProjectFilter, pagination dto.PaginationQuery,
+) (*dto.PaginationResponse, error) {
+	projects, nextCursor, err := s.projectRepo.GetAll(ctx, filter, pagination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get projects: %w", err)
+	}
+
+	projectResponses := make([]dto.ProjectResponse, len(projects))
+	for i, project := range projects {
+		projectResponses[i] = *dto.ToProjectResponse(&project)
+	}
+
+	return &dto.PaginationResponse{
+		Data: projectResponses,
+		Pagination: dto.PaginationMeta{
+			Limit:      pagination.Limit,
+			Count:      len(projectResponses),
+			NextCursor: nextCursor,
+		},
+	}, nil
+}
+
+// UpdateProject changes a project, including archiving and restoring it.
+// Only its owner may update it.
+func (s *projectService) UpdateProject(
+	ctx context.Context, actor *domain.User, id uuid.UUID, req dto.UpdateProjectRequest,
+) (*dto.ProjectResponse, error) {
+	var response *dto.ProjectResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		response, err = s.updateProject(ctx, actor, id, req)
+		return err
+	})
+	return response, err
+}
+
+func (s *projectService) updateProject(
+	ctx context.Context, actor *domain.User, id uuid.UUID, req dto.UpdateProjectRequest,
+) (*dto.ProjectResponse, error) {
+	project, err := s.getOwnedProject(ctx, actor, id, TadaActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		project.Name = *req.Name
+	}
+	if req.Description != nil {
+		project.Description = *req.Description
+	}
+	if req.Archived != nil {
+		project.Archived = *req.Archived
+	}
+	if req.Workflow != nil {
+		if err := s.checkWorkflow(ctx, id, *req.Workflow); err != nil {
+			return nil, err
+		}
+		project.Workflow = *req.Workflow
+	}
+
+	if err := s.projectRepo.Update(ctx, project); err != nil {
+		return nil, fmt.Errorf("failed to update project: %w", err)
+	}
+
+	return dto.ToProjectResponse(project), nil
+}
+
+// checkWorkflow checks that a workflow is valid and has every status the
+// project's tadas are in.
+func (s *projectService) checkWorkflow(ctx context.Context, id uuid.UUID, workflow domain.Workflow) error {
+	if err := workflow.Validate(); err != nil {
+		return err
+	}
+
+	statuses, err := s.projectRepo.GetStatuses(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	workflow = workflow.Effective()
+	for _, status := range statuses {
+		if _, ok := workflow.State(status); !ok {
+			return fmt.Errorf("%w: %s", ErrWorkflowInUse, status)
+		}
+	}
+
+	return nil
+}
+
+// DeleteProject removes an empty project. Only its owner may delete it;
+// projects that still hold tadas can be archived instead.
+func (s *projectService) DeleteProject(ctx context.Context, actor *domain.User, id uuid.UUID) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		return s.deleteProject(ctx, actor, id)
+	})
+}
+
+func (s *projectService) deleteProject(ctx context.Context, actor *domain.User, id uuid.UUID) error {
+	if _, err := s.getOwnedProject(ctx, actor, id, TadaActionDelete); err != nil {
+		return err
+	}
+
+	count, err := s.projectRepo.CountTadas(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to count project tadas: %w", err)
+	}
+	if count > 0 {
+		return ErrProjectNotEmpty
+	}
+
+	if err := s.projectRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete project: %w", err)
+	}
+
+	return nil
+}
+
+func (s *projectService) GetProjectStats(ctx context.Context, id uuid.UUID) (*dto.ProjectStatsResponse, error) {
+	if _, err := s.getProject(ctx, id); err != nil {
+		return nil, err
+	}
+
+	stats, err := s.projectRepo.GetStats(ctx, id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.ToProjectStatsResponse(id, stats), nil
+}
+
+func (s *projectService) getProject(ctx context.Context, id uuid.UUID) (*domain.Project, error) {
+	project, err := s.projectRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	return project, nil
+}
+
+// getOwnedProject loads a project and checks that actor owns it.
+func (s *projectService) getOwnedProject(
+	ctx context.Context, actor *domain.User, id uuid.UUID, action TadaAction,
+) (*domain.Project, error) {
+	project, err := s.getProject(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if project.OwnerID != actor.ID {
+		return nil, &ForbiddenError{
+			Action:   action,
+			Resource: "project",
+			Reason:   ReasonNotOwner,
+			Message:  "only the owner may " + string(action) + " this project",
+		}
+	}
+
+	return project, nil
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/kanutocd/tada/internal/config"
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/repository"
+)
+
+// reminderLockKey is the advisory lock that keeps replicas from sending
+// reminders concurrently.
+const reminderLockKey int64 = 0x7461_6461_0001
+
+// ReminderScheduler notifies users about tadas that are about to fall due
+// or have passed their due time. Sent reminders are recorded, so each is
+// sent once even across restarts and replicas.
+type ReminderScheduler struct {
+	reminderRepo repository.ReminderRepository
+	locker       repository.AdvisoryLocker
+	notifier     Notifier
+	config       config.ReminderConfig
+}
+
+func NewReminderScheduler(
+	reminderRepo repository.ReminderRepository,
+	locker repository.AdvisoryLocker,
+	notifier Notifier,
+	cfg config.ReminderConfig,
+) *ReminderScheduler {
+	return &ReminderScheduler{
+		reminderRepo: reminderRepo,
+		locker:       locker,
+		notifier:     notifier,
+		config:       cfg,
+	}
+}
+
+// Run sends due reminders every poll interval until ctx is cancelled.
+func (s *ReminderScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.SendDue(ctx); err != nil {
+			log.Printf("Reminder scheduler: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendDue sends the reminders that are due now and returns how many were
+// sent. It does nothing if another replica is already sending.
+func (s *ReminderScheduler) SendDue(ctx context.Context) (int, error) {
+	sent := 0
+	_, err := s.locker.TryWithLock(ctx, reminderLockKey, func() error {
+		now := time.Now()
+
+		n, err := s.send(ctx, domain.ReminderOverdue, now.Add(-s.config.OverdueWindow), now, now)
+		sent += n
+		if err != nil {
+			return err
+		}
+
+		n, err = s.send(ctx, domain.ReminderDueSoon, now, now.Add(s.config.LeadTime), now)
+		sent += n
+		return err
+	})
+	return sent, err
+}
+
+// send reminds about one batch of tadas due in [from, to).
+func (s *ReminderScheduler) send(ctx context.Context, kind domain.ReminderKind, from, to, now time.Time) (int, error) {
+	tadas, err := s.reminderRepo.GetUnreminded(ctx, kind, from, to, now, s.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range tadas {
+		if ctx.Err() != nil {
+			return sent, ctx.Err()
+		}
+
+		tada := &tadas[i]
+		recipient := tada.Creator
+		if tada.Assignee != nil {
+			recipient = *tada.Assignee
+		}
+
+		reminder := &domain.Reminder{
+			TadaID:      tada.ID,
+			Kind:        kind,
+			DueAt:       *tada.DueAt,
+			RecipientID: recipient.ID,
+			Attempts:    1,
+		}
+
+		if err := s.notifier.Notify(ctx, reminderNotification(kind, tada, recipient)); err != nil {
+			if ctx.Err() != nil {
+				return sent, ctx.Err()
+			}
+			log.Printf("Failed to send %s reminder for tada %s: %v", kind, tada.ID, err)
+
+			// Hold the tada back until its next attempt, so that reminders
+			// that keep failing do not fill every batch ahead of the rest.
+			next := time.Now().Add(s.config.RetryInterval)
+			reminder.NextAttemptAt = &next
+			reminder.LastError = err.Error()
+			if err := s.reminderRepo.RecordFailure(ctx, reminder, s.config.MaxAttempts); err != nil {
+				return sent, fmt.Errorf("failed to record failed reminder for tada %s: %w", tada.ID, err)
+			}
+			continue
+		}
+
+		sentAt := time.Now()
+		reminder.SentAt = &sentAt
+		// The reminder is out, so record it even when shutdown has begun.
+		if err := s.reminderRepo.Create(context.WithoutCancel(ctx), reminder); err != nil {
+			return sent, fmt.Errorf("failed to record reminder for tada %s: %w", tada.ID, err)
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+func reminderNotification(kind domain.ReminderKind, tada *domain.Tada, recipient domain.User) Notification {
+	due := tada.DueAt.UTC().Format("Mon, 02 Jan 2006 15:04 MST")
+
+	subject := fmt.Sprintf("Reminder: %q is due %s", tada.Name, due)
+	if kind == domain.ReminderOverdue {
+		subject = fmt.Sprintf("Overdue: %q was due %s", tada.Name, due)
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\n%s\n", recipient.Name, subject)
+	if tada.Description != "" {
+		body += "\n" + tada.Description + "\n"
+	}
+
+	return Notification{
+		Recipient: recipient,
+		Subject:   subject,
+		Body:      body,
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/kanutocd/tada/internal/domain"
+)
+
+// TadaAction is an operation on an existing tada that is subject to a
+// TadaPolicy.
+type TadaAction string
+
+const (
+	TadaActionUpdate   TadaAction = "update"
+	TadaActionDelete   TadaAction = "delete"
+	TadaActionComplete TadaAction = "complete"
+)
+
+// Machine-readable reasons reported by DefaultTadaPolicy.
+const (
+	ReasonNotCreatorOrAssignee = "not_creator_or_assignee"
+	ReasonNotCreator           = "not_creator"
+)
+
+// ForbiddenError is returned when a policy denies an action. Reason is a
+// stable code clients can branch on; Message is for humans. Resource names
+// what the action was on, and is a tada unless set.
+type ForbiddenError struct {
+	Action   TadaAction
+	Resource string
+	Reason   string
+	Message  string
+}
+
+func (e *ForbiddenError) Error() string {
+	resource := e.Resource
+	if resource == "" {
+		resource = "tada"
+	}
+	return fmt.Sprintf("forbidden to %s %s: %s", e.Action, resource, e.Message)
+}
+
+// TadaPolicy decides whether actor may perform action on tada. It returns
+// nil to allow the action and a *ForbiddenError to deny it. Implementations
+// can wrap DefaultTadaPolicy to add roles or extra rules.
+type TadaPolicy interface {
+	Authorize(actor *domain.User, action TadaAction, tada *domain.Tada) error
+}
+
+// DefaultTadaPolicy lets the creator or assignee update and complete a tada,
+// and only the creator delete it.
+type DefaultTadaPolicy struct{}
+
+func (DefaultTadaPolicy) Authorize(actor *domain.User, action TadaAction, tada *domain.Tada) error {
+	isCreator := tada.CreatedBy == actor.ID
+	isAssignee := tada.AssignedTo != nil && *tada.AssignedTo == actor.ID
+
+	switch action {
+	case TadaActionUpdate, TadaActionComplete:
+		if isCreator || isAssignee {
+			return nil
+		}
+		return &ForbiddenError{
+			Action:  action,
+			Reason:  ReasonNotCreatorOrAssignee,
+			Message: "only the creator or assignee may " + string(action) + " this tada",
+		}
+	case TadaActionDelete:
+		if isCreator {
+			return nil
+		}
+		return &ForbiddenError{
+			Action:  action,
+			Reason:  ReasonNotCreator,
+			Message: "only the creator may delete this tada",
+		}
+	default:
+		return &ForbiddenError{
+			Action:  action,
+			Reason:  "unknown_action",
+			Message: "unknown action",
+		}
+	}
+}
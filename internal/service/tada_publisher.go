@@ -0,0 +1,65 @@
+package service
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/dto"
+)
+
+// TadaChange is a committed change to a tada, as handed to publishers.
+// Tada is the state after the change, or the last state before a delete.
+type TadaChange struct {
+	ID         uuid.UUID
+	Type       domain.TadaChangeType
+	Tada       *dto.TadaResponse
+	ActorID    uuid.UUID
+	OccurredAt time.Time
+}
+
+// Event returns the change in the form sent to clients.
+func (c TadaChange) Event() dto.TadaChangeEvent {
+	return dto.TadaChangeEvent{
+		ID:         c.ID,
+		Type:       c.Type,
+		OccurredAt: c.OccurredAt,
+		ActorID:    c.ActorID,
+		Data:       c.Tada,
+	}
+}
+
+// TadaPublisher is notified by TadaService after a tada is created, updated
+// or deleted. Publish must not block for long; slow work belongs in a
+// background worker.
+type TadaPublisher interface {
+	Publish(changes ...TadaChange)
+}
+
+// TadaPublishers fans changes out to each publisher in turn.
+type TadaPublishers []TadaPublisher
+
+func (p TadaPublishers) Publish(changes ...TadaChange) {
+	for _, publisher := range p {
+		publisher.Publish(changes...)
+	}
+}
+
+// tadaChangeBuffer holds back changes made in a transaction until it is
+// known to have committed.
+type tadaChangeBuffer []TadaChange
+
+func (b *tadaChangeBuffer) Publish(changes ...TadaChange) {
+	*b = append(*b, changes...)
+}
+
+func newTadaChange(changeType domain.TadaChangeType, tada *dto.TadaResponse, actorID uuid.UUID) TadaChange {
+	return TadaChange{
+		ID:         uuid.New(),
+		Type:       changeType,
+		Tada:       tada,
+		ActorID:    actorID,
+		OccurredAt: time.Now(),
+	}
+}
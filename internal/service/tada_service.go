@@ -1,103 +1,393 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
+	"github.com/kanutocd/tada/internal/config"
 	"github.com/kanutocd/tada/internal/domain"
 	"github.com/kanutocd/tada/internal/dto"
 	"github.com/kanutocd/tada/internal/repository"
 )
 
 type TadaService interface {
-	CreateTada(req dto.CreateTadaRequest) (*dto.TadaResponse, error)
-	GetTadaByID(id uuid.UUID) (*dto.TadaResponse, error)
-	GetTadas(pagination dto.PaginationQuery) (*dto.PaginationResponse, error)
-	UpdateTada(id uuid.UUID, req dto.UpdateTadaRequest) (*dto.TadaResponse, error)
-	DeleteTada(id uuid.UUID) error
+	CreateTada(ctx context.Context, creatorID uuid.UUID, req dto.CreateTadaRequest) (*dto.TadaResponse, error)
+	GetTadaByID(ctx context.Context, id uuid.UUID) (*dto.TadaResponse, error)
+	GetTadas(ctx context.Context, filter dto.TadaFilter, pagination dto.PaginationQuery) (*dto.PaginationResponse, error)
+	GetUserTadas(ctx context.Context, userID uuid.UUID, role string, pagination dto.PaginationQuery) (*dto.PaginationResponse, error)
+	GetProjectTadas(
+		ctx context.Context, projectID uuid.UUID, filter dto.TadaFilter, pagination dto.PaginationQuery,
+	) (*dto.PaginationResponse, error)
+	UpdateTada(
+		ctx context.Context, actor *domain.User, id uuid.UUID, req dto.UpdateTadaRequest, ifMatch dto.IfMatch,
+	) (*dto.TadaResponse, error)
+	PatchTada(
+		ctx context.Context, actor *domain.User, id uuid.UUID, patch dto.Patch, cascade bool, ifMatch dto.IfMatch,
+	) (*dto.TadaResponse, error)
+	DeleteTada(ctx context.Context, actor *domain.User, id uuid.UUID, ifMatch dto.IfMatch) error
+	BulkTadas(ctx context.Context, actor *domain.User, req dto.BulkTadaRequest) (*dto.BulkTadaResponse, error)
+	GetTadaHistory(ctx context.Context, id uuid.UUID, pagination dto.PaginationQuery) (*dto.PaginationResponse, error)
+	GetTadaChildren(ctx context.Context, id uuid.UUID, pagination dto.PaginationQuery) (*dto.PaginationResponse, error)
+	GetTadaTree(ctx context.Context, id uuid.UUID, depth int) (*dto.TadaTreeResponse, error)
+	AddDependency(ctx context.Context, actor *domain.User, blockerID, blockedID uuid.UUID) (*dto.TadaDependencyResponse, error)
+	RemoveDependency(ctx context.Context, actor *domain.User, blockerID, blockedID uuid.UUID) error
+	GetTadaOccurrences(ctx context.Context, id uuid.UUID, count int) (*dto.TadaOccurrencesResponse, error)
+	Search(
+		ctx context.Context, query dto.SearchQuery, filter dto.TadaFilter, pagination dto.PaginationQuery,
+	) (*dto.PaginationResponse, error)
+
+	// InWorkspace returns the service confined to a workspace. The service
+	// sees no tadas until it is confined.
+	InWorkspace(workspaceID uuid.UUID) TadaService
 }
 
+// defaultTreeDepth is how many levels of subtasks a tree includes when the
+// caller does not say.
+const defaultTreeDepth = 3
+
+// defaultOccurrencePreview is how many upcoming occurrences a preview lists
+// when the caller does not say.
+const defaultOccurrencePreview = 5
+
 type tadaService struct {
-	tadaRepo repository.TadaRepository
-	userRepo repository.UserRepository
+	tadaRepo       repository.TadaRepository
+	userRepo       repository.UserRepository
+	tagRepo        repository.TagRepository
+	dependencyRepo repository.TadaDependencyRepository
+	commentRepo    repository.CommentRepository
+	checklistRepo  repository.ChecklistRepository
+	projectRepo    repository.ProjectRepository
+	workspaceRepo  repository.WorkspaceRepository
+	searcher       repository.Searcher
+	txManager      repository.TxManager
+	workspaceID    uuid.UUID
+	policy         TadaPolicy
+	publisher      TadaPublisher
+	config         config.TadaConfig
 }
 
-func NewTadaService(tadaRepo repository.TadaRepository, userRepo repository.UserRepository) TadaService {
+func NewTadaService(
+	tadaRepo repository.TadaRepository,
+	userRepo repository.UserRepository,
+	tagRepo repository.TagRepository,
+	dependencyRepo repository.TadaDependencyRepository,
+	commentRepo repository.CommentRepository,
+	checklistRepo repository.ChecklistRepository,
+	projectRepo repository.ProjectRepository,
+	workspaceRepo repository.WorkspaceRepository,
+	searcher repository.Searcher,
+	txManager repository.TxManager,
+	policy TadaPolicy,
+	publisher TadaPublisher,
+	cfg config.TadaConfig,
+) TadaService {
 	return &tadaService{
-		tadaRepo: tadaRepo,
-		userRepo: userRepo,
+		tadaRepo:       tadaRepo,
+		userRepo:       userRepo,
+		tagRepo:        tagRepo,
+		dependencyRepo: dependencyRepo,
+		commentRepo:    commentRepo,
+		checklistRepo:  checklistRepo,
+		projectRepo:    projectRepo,
+		workspaceRepo:  workspaceRepo,
+		searcher:       searcher,
+		txManager:      txManager,
+		policy:         policy,
+		publisher:      publisher,
+		config:         cfg,
 	}
 }
 
-func (s *tadaService) CreateTada(req dto.CreateTadaRequest) (*dto.TadaResponse, error) {
+func (s *tadaService) InWorkspace(workspaceID uuid.UUID) TadaService {
+	scoped := *s
+	scoped.workspaceID = workspaceID
+	scoped.tadaRepo = s.tadaRepo.InWorkspace(workspaceID)
+	scoped.projectRepo = s.projectRepo.InWorkspace(workspaceID)
+	scoped.tagRepo = s.tagRepo.InWorkspace(workspaceID)
+	scoped.searcher = s.searcher.InWorkspace(workspaceID)
+	return &scoped
+}
+
+// CreateTada creates a tada and publishes the change once it is saved.
+func (s *tadaService) CreateTada(ctx context.Context, creatorID uuid.UUID, req dto.CreateTadaRequest) (*dto.TadaResponse, error) {
+	var response *dto.TadaResponse
+	err := s.transaction(ctx, func(ctx context.Context, tx *tadaService) error {
+		var err error
+		response, err = tx.createTada(ctx, creatorID, req)
+		return err
+	})
+	return response, err
+}
+
+func (s *tadaService) createTada(ctx context.Context, creatorID uuid.UUID, req dto.CreateTadaRequest) (*dto.TadaResponse, error) {
 	// Validate creator exists
-	_, err := s.userRepo.GetByID(req.CreatedBy)
+	_, err := s.userRepo.GetByID(ctx, creatorID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("creator user not found")
+			return nil, fmt.Errorf("creator %w", ErrUserNotFound)
 		}
 		return nil, fmt.Errorf("failed to validate creator: %w", err)
 	}
 
 	// Validate assignee exists if provided
 	if req.AssignedTo != nil {
-		_, err := s.userRepo.GetByID(*req.AssignedTo)
+		if err := s.validateAssignee(ctx, *req.AssignedTo); err != nil {
+			return nil, err
+		}
+	}
+
+	var parentID *uuid.UUID
+	if req.ParentID != nil {
+		parentID, err = s.validateParent(ctx, uuid.Nil, *req.ParentID)
 		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return nil, errors.New("assignee user not found")
-			}
-			return nil, fmt.Errorf("failed to validate assignee: %w", err)
+			return nil, err
+		}
+	}
+
+	var projectID *uuid.UUID
+	if req.ProjectID != nil {
+		projectID, err = s.validateProject(ctx, *req.ProjectID)
+		if err != nil {
+			return nil, err
 		}
 	}
 
+	tags, err := s.resolveTags(ctx, req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
 	tada := &domain.Tada{
 		Name:        req.Name,
 		Description: req.Description,
-		CreatedBy:   req.CreatedBy,
+		CreatedBy:   creatorID,
 		AssignedTo:  req.AssignedTo,
+		ParentID:    parentID,
+		ProjectID:   projectID,
+		Estimate:    req.EstimateMinutes,
 		DueAt:       req.DueAt,
+		Tags:        tags,
+	}
+
+	if req.Priority != nil {
+		tada.Priority = *req.Priority
+	}
+
+	if req.Recurrence != nil {
+		tada.Recurrence, err = normalizeRecurrence(*req.Recurrence, tada.DueAt)
+		if err != nil {
+			return nil, err
+		}
 	}
 
+	workflow, err := s.workflowFor(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	status := workflow.Initial
 	if req.Status != nil {
-		tada.Status = *req.Status
+		status = *req.Status
 	}
+	state, ok := workflow.State(status)
+	if !ok {
+		return nil, ErrInvalidStatus
+	}
+	tada.SetState(state, time.Now())
 
-	if err := s.tadaRepo.Create(tada); err != nil {
+	if err := s.tadaRepo.Create(ctx, tada, creatorID); err != nil {
 		return nil, fmt.Errorf("failed to create tada: %w", err)
 	}
 
 	// Reload with relationships
-	tada, _ = s.tadaRepo.GetByID(tada.ID)
+	tada, err = s.tadaRepo.GetByID(ctx, tada.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload tada: %w", err)
+	}
 
-	return dto.ToTadaResponse(tada), nil
+	response := dto.ToTadaResponse(tada)
+
+	changes := []TadaChange{newTadaChange(domain.TadaChangeCreated, response, creatorID)}
+	if tada.AssignedTo != nil {
+		changes = append(changes, newTadaChange(domain.TadaChangeAssigned, response, creatorID))
+	}
+	s.publisher.Publish(changes...)
+
+	return response, nil
 }
 
-func (s *tadaService) GetTadaByID(id uuid.UUID) (*dto.TadaResponse, error) {
-	tada, err := s.tadaRepo.GetByID(id)
+func (s *tadaService) GetTadaByID(ctx context.Context, id uuid.UUID) (*dto.TadaResponse, error) {
+	tada, err := s.tadaRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("tada not found")
+			return nil, ErrTadaNotFound
 		}
 		return nil, fmt.Errorf("failed to get tada: %w", err)
 	}
 
-	return dto.ToTadaResponse(tada), nil
+	response := dto.ToTadaResponse(tada)
+	if err := s.annotate(ctx, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
 }
 
-func (s *tadaService) GetTadas(pagination dto.PaginationQuery) (*dto.PaginationResponse, error) {
-	tadas, nextCursor, err := s.tadaRepo.GetAll(pagination)
+func (s *tadaService) GetTadas(
+	ctx context.Context, filter dto.TadaFilter, pagination dto.PaginationQuery,
+) (*dto.PaginationResponse, error) {
+	tagNames, err := normalizeTagNames(filter.Tag)
+	if err != nil {
+		return nil, err
+	}
+	filter.Tag = tagNames
+
+	tadas, nextCursor, err := s.tadaRepo.GetAll(ctx, filter, pagination)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tadas: %w", err)
 	}
 
+	return s.toTadaPage(ctx, tadas, nextCursor, pagination)
+}
+
+// Search returns the tadas matching a full-text query, best match first. A
+// tada appears once for itself and once for each matching comment.
+func (s *tadaService) Search(
+	ctx context.Context, query dto.SearchQuery, filter dto.TadaFilter, pagination dto.PaginationQuery,
+) (*dto.PaginationResponse, error) {
+	tagNames, err := normalizeTagNames(filter.Tag)
+	if err != nil {
+		return nil, err
+	}
+	filter.Tag = tagNames
+
+	hits, nextCursor, err := s.searcher.Search(ctx, query.Q, filter, pagination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search tadas: %w", err)
+	}
+
+	ids := make([]uuid.UUID, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.TadaID)
+	}
+	tadas, err := s.tadaRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tadas: %w", err)
+	}
+
+	byID := make(map[uuid.UUID]*dto.TadaResponse, len(tadas))
+	responses := make([]*dto.TadaResponse, 0, len(tadas))
+	for i := range tadas {
+		response := dto.ToTadaResponse(&tadas[i])
+		byID[response.ID] = response
+		responses = append(responses, response)
+	}
+	if err := s.annotate(ctx, responses...); err != nil {
+		return nil, err
+	}
+
+	// A tada deleted since the search ran has no response and is skipped.
+	results := make([]dto.SearchResultResponse, 0, len(hits))
+	for i := range hits {
+		if tada, ok := byID[hits[i].TadaID]; ok {
+			results = append(results, *dto.ToSearchResultResponse(&hits[i], tada))
+		}
+	}
+
+	return &dto.PaginationResponse{
+		Data: results,
+		Pagination: dto.PaginationMeta{
+			Limit:      pagination.Limit,
+			Count:      len(results),
+			NextCursor: nextCursor,
+		},
+	}, nil
+}
+
+func (s *tadaService) GetUserTadas(
+	ctx context.Context, userID uuid.UUID, role string, pagination dto.PaginationQuery,
+) (*dto.PaginationResponse, error) {
+	// Users outside the workspace are reported as not found, so that the
+	// listing does not reveal who has an account elsewhere.
+	if _, err := s.userRepo.GetMember(ctx, s.workspaceID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	var (
+		tadas      []domain.Tada
+		nextCursor string
+		err        error
+	)
+	switch role {
+	case "creator":
+		tadas, nextCursor, err = s.tadaRepo.GetByUserID(ctx, userID, pagination)
+	case "assignee":
+		tadas, nextCursor, err = s.tadaRepo.GetByAssigneeID(ctx, userID, pagination)
+	default:
+		tadas, nextCursor, err = s.tadaRepo.GetByParticipantID(ctx, userID, pagination)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tadas: %w", err)
+	}
+
+	return s.toTadaPage(ctx, tadas, nextCursor, pagination)
+}
+
+// GetProjectTadas lists a project's tadas, archived or not, narrowed by the
+// rest of filter.
+func (s *tadaService) GetProjectTadas(
+	ctx context.Context, projectID uuid.UUID, filter dto.TadaFilter, pagination dto.PaginationQuery,
+) (*dto.PaginationResponse, error) {
+	if _, err := s.projectRepo.GetByID(ctx, projectID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	filter.ProjectID = projectID.String()
+	return s.GetTadas(ctx, filter, pagination)
+}
+
+// resolveTags returns the tags with the given names, creating missing ones.
+func (s *tadaService) resolveTags(ctx context.Context, names []string) ([]domain.Tag, error) {
+	names, err := normalizeTagNames(names)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.tagRepo.FindOrCreate(ctx, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+func (s *tadaService) toTadaPage(
+	ctx context.Context, tadas []domain.Tada, nextCursor string, pagination dto.PaginationQuery,
+) (*dto.PaginationResponse, error) {
 	tadaResponses := make([]dto.TadaResponse, len(tadas))
 	for i, tada := range tadas {
 		tadaResponses[i] = *dto.ToTadaResponse(&tada)
 	}
 
+	responses := make([]*dto.TadaResponse, len(tadaResponses))
+	for i := range tadaResponses {
+		responses[i] = &tadaResponses[i]
+	}
+	if err := s.annotate(ctx, responses...); err != nil {
+		return nil, err
+	}
+
 	return &dto.PaginationResponse{
 		Data: tadaResponses,
 		Pagination: dto.PaginationMeta{
@@ -108,62 +398,965 @@ func (s *tadaService) GetTadas(pagination dto.PaginationQuery) (*dto.PaginationR
 	}, nil
 }
 
-func (s *tadaService) UpdateTada(id uuid.UUID, req dto.UpdateTadaRequest) (*dto.TadaResponse, error) {
-	tada, err := s.tadaRepo.GetByID(id)
+// annotate fills in the fields of each response that are computed from
+// other records: subtask progress, blockers, the comment count and the
+// checklist totals.
+func (s *tadaService) annotate(ctx context.Context, responses ...*dto.TadaResponse) error {
+	ids := make([]uuid.UUID, len(responses))
+	byID := make(map[uuid.UUID]*dto.TadaResponse, len(responses))
+	for i, response := range responses {
+		ids[i] = response.ID
+		byID[response.ID] = response
+	}
+
+	progress, err := s.tadaRepo.GetProgress(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to get subtask progress: %w", err)
+	}
+
+	for _, response := range responses {
+		if p, ok := progress[response.ID]; ok {
+			response.Progress = &p
+		}
+	}
+
+	blockers, err := s.dependencyRepo.GetBlockers(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, blocker := range blockers {
+		response := byID[blocker.BlockedID]
+		response.BlockedBy = append(response.BlockedBy, blocker.BlockerID)
+		if blocker.Category == domain.CategoryOpen {
+			response.Blocked = true
+		}
+	}
+
+	commentCounts, err := s.commentRepo.CountByTadaIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	checklists, err := s.checklistRepo.GetSummaries(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, response := range responses {
+		response.CommentCount = commentCounts[response.ID]
+		response.ChecklistTotal = checklists[response.ID].Total
+		response.ChecklistDone = checklists[response.ID].Done
+	}
+
+	return nil
+}
+
+// validateParent checks that parentID can become the parent of the tada
+// with the given ID, which is uuid.Nil for a tada not yet created. It
+// returns nil for uuid.Nil, which means no parent.
+func (s *tadaService) validateParent(ctx context.Context, id, parentID uuid.UUID) (*uuid.UUID, error) {
+	if parentID == uuid.Nil {
+		return nil, nil
+	}
+
+	if _, err := s.tadaRepo.GetByID(ctx, parentID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrParentNotFound
+		}
+		return nil, fmt.Errorf("failed to get parent tada: %w", err)
+	}
+
+	if id != uuid.Nil {
+		ancestorIDs, err := s.tadaRepo.GetAncestorIDs(ctx, parentID)
+		if err != nil {
+			return nil, err
+		}
+		for _, ancestorID := range ancestorIDs {
+			if ancestorID == id {
+				return nil, ErrTadaCycle
+			}
+		}
+	}
+
+	return &parentID, nil
+}
+
+// validateAssignee checks that a user exists and is a member of the
+// service's workspace.
+func (s *tadaService) validateAssignee(ctx context.Context, userID uuid.UUID) error {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("assignee %w", ErrUserNotFound)
+		}
+		return fmt.Errorf("failed to validate assignee: %w", err)
+	}
+
+	if _, err := s.workspaceRepo.GetMember(ctx, s.workspaceID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotMember
+		}
+		return fmt.Errorf("failed to validate assignee: %w", err)
+	}
+
+	return nil
+}
+
+// validateProject checks that a tada can be put in the project. It returns
+// nil for uuid.Nil, which means no project.
+func (s *tadaService) validateProject(ctx context.Context, projectID uuid.UUID) (*uuid.UUID, error) {
+	if projectID == uuid.Nil {
+		return nil, nil
+	}
+
+	project, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	if project.Archived {
+		return nil, ErrProjectArchived
+	}
+
+	return &projectID, nil
+}
+
+// workflowFor returns the workflow of the tadas in a project, or the
+// default workflow for tadas outside one.
+func (s *tadaService) workflowFor(ctx context.Context, projectID *uuid.UUID) (domain.Workflow, error) {
+	if projectID == nil {
+		return domain.DefaultWorkflow, nil
+	}
+
+	project, err := s.projectRepo.GetByID(ctx, *projectID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.Workflow{}, ErrProjectNotFound
+		}
+		return domain.Workflow{}, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	return project.Workflow.Effective(), nil
+}
+
+// sameProject reports whether two project IDs, either of which may be nil
+// for no project, are the same.
+func sameProject(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// UpdateTada applies req to a tada, which must be at a version ifMatch
+// allows. Completing it saves any subtasks completed by cascade in the
+// same transaction; if one of them changes meanwhile, nothing is saved.
+func (s *tadaService) UpdateTada(
+	ctx context.Context, actor *domain.User, id uuid.UUID, req dto.UpdateTadaRequest, ifMatch dto.IfMatch,
+) (*dto.TadaResponse, error) {
+	var response *dto.TadaResponse
+	err := s.transaction(ctx, func(ctx context.Context, tx *tadaService) error {
+		tada, err := tx.getTadaForUpdate(ctx, actor, id, ifMatch)
+		if err != nil {
+			return err
+		}
+
+		response, err = tx.applyTadaPatch(ctx, actor, tada, req.Patch(), req.Cascade, ifMatch)
+		return err
+	})
+	return response, err
+}
+
+// PatchTada applies a merge patch or JSON Patch to a tada, which must be
+// at a version ifMatch allows. A JSON Patch applies to the tada as it is
+// at that version. Otherwise it works as UpdateTada does.
+func (s *tadaService) PatchTada(
+	ctx context.Context, actor *domain.User, id uuid.UUID, patch dto.Patch, cascade bool, ifMatch dto.IfMatch,
+) (*dto.TadaResponse, error) {
+	var response *dto.TadaResponse
+	err := s.transaction(ctx, func(ctx context.Context, tx *tadaService) error {
+		tada, err := tx.getTadaForUpdate(ctx, actor, id, ifMatch)
+		if err != nil {
+			return err
+		}
+
+		var req dto.PatchTadaRequest
+		if err := patch.Decode(dto.TadaPatchDocument(tada), &req); err != nil {
+			return err
+		}
+		if err := req.Validate(); err != nil {
+			return err
+		}
+
+		response, err = tx.applyTadaPatch(ctx, actor, tada, req, cascade, ifMatch)
+		return err
+	})
+	return response, err
+}
+
+// getTadaForUpdate loads a tada the actor may update and that is at a
+// version ifMatch allows.
+func (s *tadaService) getTadaForUpdate(ctx context.Context, actor *domain.User, id uuid.UUID, ifMatch dto.IfMatch) (*domain.Tada, error) {
+	tada, err := s.tadaRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("tada not found")
+			return nil, ErrTadaNotFound
 		}
 		return nil, fmt.Errorf("failed to get tada: %w", err)
 	}
 
+	if err := s.policy.Authorize(actor, TadaActionUpdate, tada); err != nil {
+		return nil, err
+	}
+
+	if err := checkVersion(ifMatch, tada.Version); err != nil {
+		return nil, err
+	}
+
+	return tada, nil
+}
+
+// applyTadaPatch makes the changes req describes to tada, saves it and
+// publishes the changes.
+func (s *tadaService) applyTadaPatch(
+	ctx context.Context, actor *domain.User, tada *domain.Tada, req dto.PatchTadaRequest, cascade bool, ifMatch dto.IfMatch,
+) (*dto.TadaResponse, error) {
+	var err error
+	previousCategory := tada.Category
+	previousAssignee := tada.AssignedTo
+	previousProject := tada.ProjectID
+
 	// Update fields
-	if req.Name != nil {
-		tada.Name = *req.Name
+	if req.Name.IsSet() {
+		tada.Name = req.Name.Value
 	}
-	if req.Description != nil {
-		tada.Description = *req.Description
+	if req.Description.Present {
+		tada.Description = req.Description.Value
 	}
-	if req.AssignedTo != nil {
-		// Validate assignee exists
-		_, err := s.userRepo.GetByID(*req.AssignedTo)
+	if req.Priority.IsSet() {
+		tada.Priority = req.Priority.Value
+	}
+	if req.EstimateMinutes.Present {
+		tada.Estimate = req.EstimateMinutes.Ptr()
+	}
+	if req.AssignedTo.IsSet() {
+		if err := s.validateAssignee(ctx, req.AssignedTo.Value); err != nil {
+			return nil, err
+		}
+	}
+	if req.AssignedTo.Present {
+		tada.AssignedTo = req.AssignedTo.Ptr()
+	}
+	if req.ParentID.Present {
+		parentID, err := s.validateParent(ctx, tada.ID, req.ParentID.Value)
 		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return nil, errors.New("assignee user not found")
-			}
-			return nil, fmt.Errorf("failed to validate assignee: %w", err)
+			return nil, err
 		}
-		tada.AssignedTo = req.AssignedTo
+		tada.ParentID = parentID
 	}
-	if req.Status != nil {
-		tada.Status = *req.Status
+	if req.ProjectID.Present {
+		projectID, err := s.validateProject(ctx, req.ProjectID.Value)
+		if err != nil {
+			return nil, err
+		}
+		tada.ProjectID = projectID
 	}
-	if req.DueAt != nil {
-		tada.DueAt = req.DueAt
+	if movedProject := !sameProject(previousProject, tada.ProjectID); req.Status.IsSet() || movedProject {
+		workflow, err := s.workflowFor(ctx, tada.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		status := tada.Status
+		if req.Status.IsSet() {
+			status = req.Status.Value
+		}
+		state, ok := workflow.State(status)
+		if !ok {
+			return nil, ErrInvalidStatus
+		}
+		if !movedProject && !workflow.CanTransition(tada.Status, status) {
+			return nil, ErrStatusTransition
+		}
+		tada.SetState(state, time.Now())
+	}
+	completed := tada.Category == domain.CategoryCompleted && previousCategory != domain.CategoryCompleted
+	if completed {
+		if err := s.policy.Authorize(actor, TadaActionComplete, tada); err != nil {
+			return nil, err
+		}
+	}
+	if req.DueAt.Present {
+		tada.DueAt = req.DueAt.Ptr()
+	}
+	if req.Recurrence.Present {
+		tada.Recurrence, err = normalizeRecurrence(req.Recurrence.Value, tada.DueAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if tada.Recurrence != "" && tada.DueAt == nil {
+		return nil, ErrRecurrenceNoDueAt
+	}
+	if req.Tags.Present {
+		tags, err := s.resolveTags(ctx, req.Tags.Value)
+		if err != nil {
+			return nil, err
+		}
+		tada.Tags = tags
+	}
+
+	var cascaded []*domain.Tada
+	if completed {
+		if s.config.EnforceDependencies {
+			if err := s.checkNotBlocked(ctx, tada.ID); err != nil {
+				return nil, err
+			}
+		}
+		cascaded, err = s.completeSubtasks(ctx, actor, tada, cascade)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	if err := s.tadaRepo.Update(tada); err != nil {
+	if err := s.tadaRepo.UpdateAll(ctx, append([]*domain.Tada{tada}, cascaded...), actor.ID); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, versionConflict(ifMatch)
+		}
 		return nil, fmt.Errorf("failed to update tada: %w", err)
 	}
 
 	// Reload with relationships
-	tada, _ = s.tadaRepo.GetByID(tada.ID)
+	tada, err = s.tadaRepo.GetByID(ctx, tada.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload tada: %w", err)
+	}
+
+	response := dto.ToTadaResponse(tada)
+	if err := s.annotate(ctx, response); err != nil {
+		return nil, err
+	}
+
+	changes := []TadaChange{newTadaChange(domain.TadaChangeUpdated, response, actor.ID)}
+	if completed {
+		changes = append(changes, newTadaChange(domain.TadaChangeCompleted, response, actor.ID))
+	}
+	for _, subtask := range cascaded {
+		subtaskResponse := dto.ToTadaResponse(subtask)
+		changes = append(changes,
+			newTadaChange(domain.TadaChangeUpdated, subtaskResponse, actor.ID),
+			newTadaChange(domain.TadaChangeCompleted, subtaskResponse, actor.ID))
+	}
+
+	if completed {
+		for _, completed := range append([]*domain.Tada{tada}, cascaded...) {
+			// A failure here must not fail the completion, so the next
+			// occurrence gets a savepoint of its own.
+			var next *domain.Tada
+			err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+				var err error
+				next, err = s.scheduleNextOccurrence(ctx, completed, actor.ID)
+				return err
+			})
+			if err != nil {
+				log.Printf("Failed to schedule next occurrence of tada %s: %v", completed.ID, err)
+				continue
+			}
+			if next == nil {
+				continue
+			}
 
-	return dto.ToTadaResponse(tada), nil
+			nextResponse := dto.ToTadaResponse(next)
+			changes = append(changes, newTadaChange(domain.TadaChangeCreated, nextResponse, actor.ID))
+			if next.AssignedTo != nil {
+				changes = append(changes, newTadaChange(domain.TadaChangeAssigned, nextResponse, actor.ID))
+			}
+		}
+	}
+	if tada.AssignedTo != nil && (previousAssignee == nil || *previousAssignee != *tada.AssignedTo) {
+		changes = append(changes, newTadaChange(domain.TadaChangeAssigned, response, actor.ID))
+	}
+	s.publisher.Publish(changes...)
+
+	return response, nil
+}
+
+// completeSubtasks moves the open subtasks at every level below tada into
+// the first completed state their workflow allows, and returns them for
+// saving. Without cascade, it rejects the completion instead if there are
+// any.
+func (s *tadaService) completeSubtasks(ctx context.Context, actor *domain.User, tada *domain.Tada, cascade bool) ([]*domain.Tada, error) {
+	descendants, err := s.tadaRepo.GetDescendants(ctx, tada.ID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []*domain.Tada
+	for i := range descendants {
+		if descendants[i].Category == domain.CategoryOpen {
+			pending = append(pending, &descendants[i])
+		}
+	}
+
+	if len(pending) > 0 && !cascade {
+		return nil, ErrIncompleteSubtasks
+	}
+
+	now := time.Now()
+	workflows := make(map[uuid.UUID]domain.Workflow)
+	for _, subtask := range pending {
+		if err := s.policy.Authorize(actor, TadaActionComplete, subtask); err != nil {
+			return nil, err
+		}
+
+		var projectID uuid.UUID
+		if subtask.ProjectID != nil {
+			projectID = *subtask.ProjectID
+		}
+		workflow, ok := workflows[projectID]
+		if !ok {
+			workflow, err = s.workflowFor(ctx, subtask.ProjectID)
+			if err != nil {
+				return nil, err
+			}
+			workflows[projectID] = workflow
+		}
+
+		state, ok := workflow.CompletionFrom(subtask.Status)
+		if !ok {
+			return nil, fmt.Errorf("subtask %s: %w", subtask.ID, ErrStatusTransition)
+		}
+		subtask.SetState(state, now)
+	}
+
+	return pending, nil
+}
+
+// scheduleNextOccurrence creates the occurrence that follows a completed
+// recurring tada. It returns nil if the tada does not recur, its series has
+// ended, or the next occurrence already exists because the tada was
+// completed before.
+func (s *tadaService) scheduleNextOccurrence(ctx context.Context, tada *domain.Tada, actorID uuid.UUID) (*domain.Tada, error) {
+	if tada.Recurrence == "" || tada.DueAt == nil {
+		return nil, nil
+	}
+
+	rule, err := domain.ParseRecurrenceRule(tada.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	nextDueAt, ok := rule.Next(*tada.DueAt, tada.Occurrence)
+	if !ok {
+		return nil, nil
+	}
+
+	seriesID := tada.SeriesRootID()
+	if _, err := s.tadaRepo.GetOccurrence(ctx, seriesID, tada.Occurrence+1); err == nil {
+		return nil, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check next occurrence: %w", err)
+	}
+
+	workflow, err := s.workflowFor(ctx, tada.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	next := &domain.Tada{
+		Name:        tada.Name,
+		Description: tada.Description,
+		CreatedBy:   tada.CreatedBy,
+		AssignedTo:  tada.AssignedTo,
+		ParentID:    tada.ParentID,
+		ProjectID:   tada.ProjectID,
+		Status:      workflow.Initial,
+		Category:    domain.CategoryOpen,
+		Priority:    tada.Priority,
+		Estimate:    tada.Estimate,
+		DueAt:       &nextDueAt,
+		Recurrence:  tada.Recurrence,
+		SeriesID:    &seriesID,
+		Occurrence:  tada.Occurrence + 1,
+		Tags:        tada.Tags,
+	}
+
+	if err := s.tadaRepo.Create(ctx, next, actorID); err != nil {
+		return nil, fmt.Errorf("failed to create next occurrence: %w", err)
+	}
+
+	return s.tadaRepo.GetByID(ctx, next.ID)
+}
+
+// normalizeRecurrence validates a recurrence rule for a tada due at dueAt
+// and returns it in canonical form. An empty rule means no recurrence.
+func normalizeRecurrence(recurrence string, dueAt *time.Time) (string, error) {
+	if recurrence == "" {
+		return "", nil
+	}
+
+	rule, err := domain.ParseRecurrenceRule(recurrence)
+	if err != nil {
+		return "", err
+	}
+	if dueAt == nil {
+		return "", ErrRecurrenceNoDueAt
+	}
+
+	return rule.String(), nil
+}
+
+// checkNotBlocked returns ErrTadaBlocked if a tada blocking id is still
+// open.
+func (s *tadaService) checkNotBlocked(ctx context.Context, id uuid.UUID) error {
+	blockers, err := s.dependencyRepo.GetBlockers(ctx, []uuid.UUID{id})
+	if err != nil {
+		return err
+	}
+
+	for _, blocker := range blockers {
+		if blocker.Category == domain.CategoryOpen {
+			return ErrTadaBlocked
+		}
+	}
+
+	return nil
+}
+
+// DeleteTada deletes a tada, which must be at a version ifMatch allows.
+func (s *tadaService) DeleteTada(ctx context.Context, actor *domain.User, id uuid.UUID, ifMatch dto.IfMatch) error {
+	return s.transaction(ctx, func(ctx context.Context, tx *tadaService) error {
+		return tx.deleteTada(ctx, actor, id, ifMatch)
+	})
 }
 
-func (s *tadaService) DeleteTada(id uuid.UUID) error {
-	_, err := s.tadaRepo.GetByID(id)
+func (s *tadaService) deleteTada(ctx context.Context, actor *domain.User, id uuid.UUID, ifMatch dto.IfMatch) error {
+	tada, err := s.tadaRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("tada not found")
+			return ErrTadaNotFound
 		}
 		return fmt.Errorf("failed to get tada: %w", err)
 	}
 
-	if err := s.tadaRepo.Delete(id); err != nil {
+	if err := s.policy.Authorize(actor, TadaActionDelete, tada); err != nil {
+		return err
+	}
+
+	if err := checkVersion(ifMatch, tada.Version); err != nil {
+		return err
+	}
+
+	progress, err := s.tadaRepo.GetProgress(ctx, []uuid.UUID{id})
+	if err != nil {
+		return fmt.Errorf("failed to check subtasks: %w", err)
+	}
+	if progress[id].Total > 0 {
+		return ErrHasSubtasks
+	}
+
+	if err := s.tadaRepo.Delete(ctx, id, tada.Version, actor.ID); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return versionConflict(ifMatch)
+		}
 		return fmt.Errorf("failed to delete tada: %w", err)
 	}
 
+	s.publisher.Publish(newTadaChange(domain.TadaChangeDeleted, dto.ToTadaResponse(tada), actor.ID))
+
+	return nil
+}
+
+// BulkTadas applies the operations of req, or its patch to every tada its
+// filter matches, in one transaction. Each operation follows the rules of
+// its single-tada counterpart. In all-or-nothing mode the first failure
+// rolls everything back; in per-item mode each operation has a savepoint
+// of its own, so a failure undoes only that operation. Changes are
+// published once the transaction commits.
+func (s *tadaService) BulkTadas(ctx context.Context, actor *domain.User, req dto.BulkTadaRequest) (*dto.BulkTadaResponse, error) {
+	response := &dto.BulkTadaResponse{Mode: req.Mode}
+	if response.Mode == "" {
+		response.Mode = dto.BulkAllOrNothing
+	}
+
+	byFilter := req.Filter != nil || req.Patch != nil
+	switch {
+	case byFilter && len(req.Operations) > 0:
+		return nil, fmt.Errorf("%w: give either operations or a filter and patch", ErrInvalidBulk)
+	case byFilter && (req.Filter == nil || req.Patch == nil):
+		return nil, fmt.Errorf("%w: a filter needs a patch and a patch needs a filter", ErrInvalidBulk)
+	case !byFilter && len(req.Operations) == 0:
+		return nil, fmt.Errorf("%w: no operations", ErrInvalidBulk)
+	case len(req.Operations) > s.config.Bulk.MaxOperations:
+		return nil, fmt.Errorf("%w: at most %d operations are allowed", ErrBulkTooLarge, s.config.Bulk.MaxOperations)
+	}
+	if byFilter {
+		if err := req.Patch.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	err := s.transaction(ctx, func(ctx context.Context, tx *tadaService) error {
+		operations := req.Operations
+		if byFilter {
+			var err error
+			operations, err = tx.bulkFilterOperations(ctx, *req.Filter, *req.Patch, req.Cascade)
+			if err != nil {
+				return err
+			}
+		}
+
+		for i, operation := range operations {
+			result := dto.BulkTadaResult{Index: i, Op: operation.Op, ID: operation.ID}
+			if response.Mode == dto.BulkPerItem {
+				result.Err = tx.transaction(ctx, func(ctx context.Context, item *tadaService) error {
+					var err error
+					result.Tada, err = item.applyBulkOperation(ctx, actor, operation)
+					return err
+				})
+			} else {
+				result.Tada, result.Err = tx.applyBulkOperation(ctx, actor, operation)
+			}
+
+			if result.Tada != nil {
+				result.ID = &result.Tada.ID
+			}
+			if result.Err != nil {
+				response.Failed++
+				if response.Mode == dto.BulkAllOrNothing {
+					response.Results = []dto.BulkTadaResult{result}
+					return result.Err
+				}
+			} else {
+				response.Succeeded++
+			}
+			response.Results = append(response.Results, result)
+		}
+		return nil
+	})
+	if err != nil {
+		if response.Mode == dto.BulkAllOrNothing && response.Failed > 0 {
+			response.Succeeded = 0
+			return response, nil
+		}
+		return nil, err
+	}
+
+	response.Committed = true
+
+	return response, nil
+}
+
+// bulkFilterOperations turns a filter and patch into an update of each
+// tada the filter matches, refusing filters that match too many.
+func (s *tadaService) bulkFilterOperations(
+	ctx context.Context, filter dto.TadaFilter, patch dto.PatchTadaRequest, cascade bool,
+) ([]dto.BulkTadaOperation, error) {
+	tagNames, err := normalizeTagNames(filter.Tag)
+	if err != nil {
+		return nil, err
+	}
+	filter.Tag = tagNames
+
+	tadas, nextCursor, err := s.tadaRepo.GetAll(ctx, filter, dto.PaginationQuery{Limit: s.config.Bulk.MaxMatches})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tadas: %w", err)
+	}
+	if nextCursor != "" {
+		return nil, fmt.Errorf("%w: the filter matches more than %d tadas", ErrBulkTooLarge, s.config.Bulk.MaxMatches)
+	}
+
+	operations := make([]dto.BulkTadaOperation, len(tadas))
+	for i := range tadas {
+		operations[i] = dto.BulkTadaOperation{
+			Op:      dto.BulkUpdate,
+			ID:      &tadas[i].ID,
+			Patch:   &patch,
+			Cascade: cascade,
+		}
+	}
+	return operations, nil
+}
+
+// applyBulkOperation carries out one operation of a bulk request and
+// returns the tada it created or updated.
+func (s *tadaService) applyBulkOperation(
+	ctx context.Context, actor *domain.User, operation dto.BulkTadaOperation,
+) (*dto.TadaResponse, error) {
+	ifMatch := operation.IfMatch()
+	switch operation.Op {
+	case dto.BulkCreate:
+		if operation.Tada == nil {
+			return nil, fmt.Errorf("%w: create needs a tada", ErrInvalidBulk)
+		}
+		return s.createTada(ctx, actor.ID, *operation.Tada)
+	case dto.BulkUpdate:
+		if operation.ID == nil || operation.Patch == nil {
+			return nil, fmt.Errorf("%w: update needs an id and a patch", ErrInvalidBulk)
+		}
+		if err := operation.Patch.Validate(); err != nil {
+			return nil, err
+		}
+		tada, err := s.getTadaForUpdate(ctx, actor, *operation.ID, ifMatch)
+		if err != nil {
+			return nil, err
+		}
+		return s.applyTadaPatch(ctx, actor, tada, *operation.Patch, operation.Cascade, ifMatch)
+	case dto.BulkDelete:
+		if operation.ID == nil {
+			return nil, fmt.Errorf("%w: delete needs an id", ErrInvalidBulk)
+		}
+		return nil, s.deleteTada(ctx, actor, *operation.ID, ifMatch)
+	}
+	return nil, fmt.Errorf("%w: unknown operation %q", ErrInvalidBulk, operation.Op)
+}
+
+// transaction runs fn in a transaction, or under a savepoint when ctx
+// already carries one. fn gets a copy of the service that holds back the
+// changes it publishes until the work commits, so a rollback publishes
+// nothing.
+func (s *tadaService) transaction(ctx context.Context, fn func(ctx context.Context, tx *tadaService) error) error {
+	var changes tadaChangeBuffer
+	tx := *s
+	tx.publisher = &changes
+	if err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		return fn(ctx, &tx)
+	}); err != nil {
+		return err
+	}
+
+	s.publisher.Publish(changes...)
+	return nil
+}
+
+func (s *tadaService) GetTadaHistory(ctx context.Context, id uuid.UUID, pagination dto.PaginationQuery) (*dto.PaginationResponse, error) {
+	if _, err := s.tadaRepo.GetByID(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTadaNotFound
+		}
+		return nil, fmt.Errorf("failed to get tada: %w", err)
+	}
+
+	events, nextCursor, err := s.tadaRepo.GetHistory(ctx, id, pagination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tada history: %w", err)
+	}
+
+	eventResponses := make([]dto.TadaEventResponse, len(events))
+	for i, event := range events {
+		eventResponses[i] = *dto.ToTadaEventResponse(&event)
+	}
+
+	return &dto.PaginationResponse{
+		Data: eventResponses,
+		Pagination: dto.PaginationMeta{
+			Limit:      pagination.Limit,
+			Count:      len(eventResponses),
+			NextCursor: nextCursor,
+		},
+	}, nil
+}
+
+func (s *tadaService) GetTadaChildren(ctx context.Context, id uuid.UUID, pagination dto.PaginationQuery) (*dto.PaginationResponse, error) {
+	if _, err := s.tadaRepo.GetByID(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTadaNotFound
+		}
+		return nil, fmt.Errorf("failed to get tada: %w", err)
+	}
+
+	tadas, nextCursor, err := s.tadaRepo.GetByParentID(ctx, id, pagination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subtasks: %w", err)
+	}
+
+	return s.toTadaPage(ctx, tadas, nextCursor, pagination)
+}
+
+// GetTadaTree returns a tada with its subtasks nested depth levels deep,
+// or defaultTreeDepth levels if depth is 0.
+func (s *tadaService) GetTadaTree(ctx context.Context, id uuid.UUID, depth int) (*dto.TadaTreeResponse, error) {
+	if depth == 0 {
+		depth = defaultTreeDepth
+	}
+
+	root, err := s.tadaRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTadaNotFound
+		}
+		return nil, fmt.Errorf("failed to get tada: %w", err)
+	}
+
+	descendants, err := s.tadaRepo.GetDescendants(ctx, id, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]dto.TadaTreeResponse, len(descendants)+1)
+	nodes[0].TadaResponse = *dto.ToTadaResponse(root)
+	for i := range descendants {
+		nodes[i+1].TadaResponse = *dto.ToTadaResponse(&descendants[i])
+	}
+
+	responses := make([]*dto.TadaResponse, len(nodes))
+	children := make(map[uuid.UUID][]int)
+	for i := range nodes {
+		responses[i] = &nodes[i].TadaResponse
+		if i > 0 {
+			parentID := *nodes[i].ParentID
+			children[parentID] = append(children[parentID], i)
+		}
+	}
+	if err := s.annotate(ctx, responses...); err != nil {
+		return nil, err
+	}
+
+	tree := buildTadaTree(nodes, children, 0)
+	return &tree, nil
+}
+
+// buildTadaTree nests the nodes below nodes[i], given the indexes of each
+// tada's children.
+func buildTadaTree(nodes []dto.TadaTreeResponse, children map[uuid.UUID][]int, i int) dto.TadaTreeResponse {
+	node := nodes[i]
+	node.Children = make([]dto.TadaTreeResponse, 0, len(children[node.ID]))
+	for _, child := range children[node.ID] {
+		node.Children = append(node.Children, buildTadaTree(nodes, children, child))
+	}
+	return node
+}
+
+// AddDependency records that blockerID blocks blockedID. Adding the link
+// requires permission to update the blocked tada.
+func (s *tadaService) AddDependency(
+	ctx context.Context, actor *domain.User, blockerID, blockedID uuid.UUID,
+) (*dto.TadaDependencyResponse, error) {
+	var response *dto.TadaDependencyResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		response, err = s.addDependency(ctx, actor, blockerID, blockedID)
+		return err
+	})
+	return response, err
+}
+
+func (s *tadaService) addDependency(
+	ctx context.Context, actor *domain.User, blockerID, blockedID uuid.UUID,
+) (*dto.TadaDependencyResponse, error) {
+	if blockerID == blockedID {
+		return nil, ErrDependencyCycle
+	}
+
+	if _, err := s.tadaRepo.GetByID(ctx, blockerID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTadaNotFound
+		}
+		return nil, fmt.Errorf("failed to get tada: %w", err)
+	}
+
+	blocked, err := s.tadaRepo.GetByID(ctx, blockedID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTadaNotFound
+		}
+		return nil, fmt.Errorf("failed to get tada: %w", err)
+	}
+
+	if err := s.policy.Authorize(actor, TadaActionUpdate, blocked); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.dependencyRepo.Get(ctx, blockerID, blockedID); err == nil {
+		return nil, ErrDependencyExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check dependency: %w", err)
+	}
+
+	// The new link closes a cycle if the blocked tada already blocks the
+	// blocker, directly or not.
+	cycle, err := s.dependencyRepo.HasPath(ctx, blockedID, blockerID)
+	if err != nil {
+		return nil, err
+	}
+	if cycle {
+		return nil, ErrDependencyCycle
+	}
+
+	dependency := &domain.TadaDependency{
+		BlockerID: blockerID,
+		BlockedID: blockedID,
+		CreatedBy: &actor.ID,
+	}
+	if err := s.dependencyRepo.Create(ctx, dependency); err != nil {
+		return nil, fmt.Errorf("failed to create dependency: %w", err)
+	}
+
+	return dto.ToTadaDependencyResponse(dependency), nil
+}
+
+// RemoveDependency deletes the link recording that blockerID blocks
+// blockedID.
+func (s *tadaService) RemoveDependency(ctx context.Context, actor *domain.User, blockerID, blockedID uuid.UUID) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		return s.removeDependency(ctx, actor, blockerID, blockedID)
+	})
+}
+
+func (s *tadaService) removeDependency(ctx context.Context, actor *domain.User, blockerID, blockedID uuid.UUID) error {
+	blocked, err := s.tadaRepo.GetByID(ctx, blockedID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTadaNotFound
+		}
+		return fmt.Errorf("failed to get tada: %w", err)
+	}
+
+	if err := s.policy.Authorize(actor, TadaActionUpdate, blocked); err != nil {
+		return err
+	}
+
+	if _, err := s.dependencyRepo.Get(ctx, blockerID, blockedID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrDependencyNotFound
+		}
+		return fmt.Errorf("failed to get dependency: %w", err)
+	}
+
+	if err := s.dependencyRepo.Delete(ctx, blockerID, blockedID); err != nil {
+		return fmt.Errorf("failed to delete dependency: %w", err)
+	}
+
 	return nil
 }
+
+// GetTadaOccurrences previews the due dates of the next count occurrences
+// of a recurring tada, or defaultOccurrencePreview if count is 0.
+func (s *tadaService) GetTadaOccurrences(ctx context.Context, id uuid.UUID, count int) (*dto.TadaOccurrencesResponse, error) {
+	if count == 0 {
+		count = defaultOccurrencePreview
+	}
+
+	tada, err := s.tadaRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTadaNotFound
+		}
+		return nil, fmt.Errorf("failed to get tada: %w", err)
+	}
+
+	if tada.Recurrence == "" || tada.DueAt == nil {
+		return nil, ErrNotRecurring
+	}
+
+	rule, err := domain.ParseRecurrenceRule(tada.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.TadaOccurrencesResponse{
+		Recurrence:  tada.Recurrence,
+		Occurrences: rule.Occurrences(*tada.DueAt, tada.Occurrence, count),
+	}, nil
+}
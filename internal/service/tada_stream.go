@@ -0,0 +1,174 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/kanutocd/tada/internal/dto"
+)
+
+var ErrStreamClosed = errors.New("stream closed")
+
+// StreamEvent is a tada change with its position in the stream. IDs start
+// at 1 when the process starts and increase by one per change.
+type StreamEvent struct {
+	ID     uint64
+	Change TadaChange
+}
+
+// TadaStream is an in-memory broker that fans tada changes out to live
+// subscribers. It keeps the most recent changes so that a reconnecting
+// client can resume from its last seen event ID.
+type TadaStream struct {
+	mu          sync.Mutex
+	lastID      uint64
+	replay      []StreamEvent
+	replaySize  int
+	bufferSize  int
+	subscribers map[*StreamSubscription]struct{}
+	closed      bool
+}
+
+// NewTadaStream creates a stream that remembers the last replaySize changes
+// and lets each subscriber fall bufferSize changes behind before it is
+// disconnected.
+func NewTadaStream(replaySize, bufferSize int) *TadaStream {
+	return &TadaStream{
+		replaySize:  replaySize,
+		bufferSize:  bufferSize,
+		subscribers: make(map[*StreamSubscription]struct{}),
+	}
+}
+
+// StreamSubscription receives the changes matching its filter. Replay holds
+// the buffered changes after the requested event ID; Gap reports that some
+// changes after that ID are no longer buffered, so the client should reload
+// its state. Events is closed when the subscriber falls too far behind or
+// the stream closes.
+type StreamSubscription struct {
+	Replay []StreamEvent
+	Gap    bool
+	Events <-chan StreamEvent
+
+	events chan StreamEvent
+	filter dto.StreamFilter
+	stream *TadaStream
+}
+
+// Publish assigns each change the next event ID and delivers it to every
+// matching subscriber.
+func (s *TadaStream) Publish(changes ...TadaChange) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	for _, change := range changes {
+		s.lastID++
+		event := StreamEvent{ID: s.lastID, Change: change}
+
+		s.replay = append(s.replay, event)
+		if len(s.replay) > s.replaySize {
+			s.replay = s.replay[len(s.replay)-s.replaySize:]
+		}
+
+		for sub := range s.subscribers {
+			if !sub.matches(change) {
+				continue
+			}
+			select {
+			case sub.events <- event:
+			default:
+				// Too far behind; the client resumes with Last-Event-ID.
+				s.remove(sub)
+			}
+		}
+	}
+}
+
+// Subscribe registers a subscriber for changes after lastEventID, or only
+// new changes if lastEventID is 0.
+func (s *TadaStream) Subscribe(lastEventID uint64, filter dto.StreamFilter) (*StreamSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, ErrStreamClosed
+	}
+
+	events := make(chan StreamEvent, s.bufferSize)
+	sub := &StreamSubscription{
+		Events: events,
+		events: events,
+		filter: filter,
+		stream: s,
+	}
+
+	if lastEventID > 0 {
+		switch {
+		case lastEventID > s.lastID:
+			// The ID is from before a restart.
+			sub.Gap = true
+		case lastEventID < s.lastID:
+			if len(s.replay) == 0 || s.replay[0].ID > lastEventID+1 {
+				sub.Gap = true
+			}
+			for _, event := range s.replay {
+				if event.ID > lastEventID && sub.matches(event.Change) {
+					sub.Replay = append(sub.Replay, event)
+				}
+			}
+		}
+	}
+
+	s.subscribers[sub] = struct{}{}
+
+	return sub, nil
+}
+
+// Close disconnects every subscriber and rejects new ones. It is meant to
+// run when the server starts shutting down, so that open streams end and
+// do not hold up the graceful shutdown.
+func (s *TadaStream) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for sub := range s.subscribers {
+		s.remove(sub)
+	}
+}
+
+// Close unsubscribes. It is safe to call more than once.
+func (sub *StreamSubscription) Close() {
+	sub.stream.mu.Lock()
+	defer sub.stream.mu.Unlock()
+
+	sub.stream.remove(sub)
+}
+
+// remove must be called with s.mu held.
+func (s *TadaStream) remove(sub *StreamSubscription) {
+	if _, ok := s.subscribers[sub]; !ok {
+		return
+	}
+	delete(s.subscribers, sub)
+	close(sub.events)
+}
+
+func (sub *StreamSubscription) matches(change TadaChange) bool {
+	tada := change.Tada
+	if tada.WorkspaceID != sub.filter.WorkspaceID {
+		return false
+	}
+	if sub.filter.CreatedBy != "" && !strings.EqualFold(tada.CreatedBy.String(), sub.filter.CreatedBy) {
+		return false
+	}
+	if sub.filter.AssignedTo != "" && (tada.AssignedTo == nil || !strings.EqualFold(tada.AssignedTo.String(), sub.filter.AssignedTo)) {
+		return false
+	}
+	return true
+}
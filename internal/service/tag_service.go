@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/dto"
+	"github.com/kanutocd/tada/internal/repository"
+)
+
+type TagService interface {
+	CreateTag(ctx context.Context, req dto.CreateTagRequest) (*dto.TagResponse, error)
+	GetTagByID(ctx context.Context, id uuid.UUID) (*dto.TagResponse, error)
+	GetTags(ctx context.Context, pagination dto.PaginationQuery) (*dto.PaginationResponse, error)
+	UpdateTag(ctx context.Context, id uuid.UUID, req dto.UpdateTagRequest) (*dto.TagResponse, error)
+	DeleteTag(ctx context.Context, id uuid.UUID) error
+
+	// InWorkspace returns the service confined to a workspace's tags.
+	InWorkspace(workspaceID uuid.UUID) TagService
+}
+
+type tagService struct {
+	tagRepo   repository.TagRepository
+	txManager repository.TxManager
+}
+
+func NewTagService(tagRepo repository.TagRepository, txManager repository.TxManager) TagService {
+	return &tagService{
+		tagRepo:   tagRepo,
+		txManager: txManager,
+	}
+}
+
+func (s *tagService) InWorkspace(workspaceID uuid.UUID) TagService {
+	scoped := *s
+	scoped.tagRepo = s.tagRepo.InWorkspace(workspaceID)
+	return &scoped
+}
+
+func (s *tagService) CreateTag(ctx context.Context, req dto.CreateTagRequest) (*dto.TagResponse, error) {
+	var response *dto.TagResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		response, err = s.createTag(ctx, req)
+		return err
+	})
+	return response, err
+}
+
+func (s *tagService) createTag(ctx context.Context, req dto.CreateTagRequest) (*dto.TagResponse, error) {
+	name, err := normalizeTagName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkNameFree(ctx, name, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	tag := &domain.Tag{Name: name}
+	if err := s.tagRepo.Create(ctx, tag); err != nil {
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	return dto.ToTagResponse(tag), nil
+}
+
+func (s *tagService) GetTagByID(ctx context.Context, id uuid.UUID) (*dto.TagResponse, error) {
+	tag, err := s.getTag(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.ToTagResponse(tag), nil
+}
+
+func (s *tagService) GetTags(ctx context.Context, pagination dto.PaginationQuery) (*dto.PaginationResponse, error) {
+	tags, nextCursor, err := s.tagRepo.GetAll(ctx, pagination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	tagResponses := make([]dto.TagResponse, len(tags))
+	for i, tag := range tags {
+		tagResponses[i] = *dto.ToTagResponse(&tag)
+	}
+
+	return &dto.PaginationResponse{
+		Data: tagResponses,
+		Pagination: dto.PaginationMeta{
+			Limit:      pagination.Limit,
+			Count:      len(tagResponses),
+			NextCursor: nextCursor,
+		},
+	}, nil
+}
+
+// UpdateTag renames a tag. Every tada carrying it shows the new name.
+func (s *tagService) UpdateTag(ctx context.Context, id uuid.UUID, req dto.UpdateTagRequest) (*dto.TagResponse, error) {
+	var response *dto.TagResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		response, err = s.updateTag(ctx, id, req)
+		return err
+	})
+	return response, err
+}
+
+func (s *tagService) updateTag(ctx context.Context, id uuid.UUID, req dto.UpdateTagRequest) (*dto.TagResponse, error) {
+	tag, err := s.getTag(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := normalizeTagName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkNameFree(ctx, name, tag.ID); err != nil {
+		return nil, err
+	}
+
+	tag.Name = name
+	if err := s.tagRepo.Update(ctx, tag); err != nil {
+		return nil, fmt.Errorf("failed to update tag: %w", err)
+	}
+
+	return dto.ToTagResponse(tag), nil
+}
+
+// DeleteTag deletes a tag and removes it from every tada.
+func (s *tagService) DeleteTag(ctx context.Context, id uuid.UUID) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		return s.deleteTag(ctx, id)
+	})
+}
+
+func (s *tagService) deleteTag(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.getTag(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.tagRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	return nil
+}
+
+func (s *tagService) getTag(ctx context.Context, id uuid.UUID) (*domain.Tag, error) {
+	tag, err := s.tagRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTagNotFound
+		}
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+	return tag, nil
+}
+
+// checkNameFree reports ErrTagExists if a tag other than id has name.
+func (s *tagService) checkNameFree(ctx context.Context, name string, id uuid.UUID) error {
+	existing, err := s.tagRepo.GetByName(ctx, name)
+	if err == nil {
+		if existing.ID != id {
+			return ErrTagExists
+		}
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to check tag name: %w", err)
+	}
+	return nil
+}
+
+// normalizeTagName trims and lowercases a tag name, so that "Infra" and
+// " infra" name the same tag.
+func normalizeTagName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", fmt.Errorf("%w: name is blank", ErrInvalidTagName)
+	}
+	return name, nil
+}
+
+// normalizeTagNames normalizes a list of tag names, dropping duplicates.
+// The result is sorted.
+func normalizeTagNames(names []string) ([]string, error) {
+	seen := make(map[string]bool, len(names))
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name, err := normalizeTagName(name)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[name] {
+			seen[name] = true
+			normalized = append(normalized, name)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
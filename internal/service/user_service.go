@@ -1,8 +1,11 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -12,51 +15,97 @@ import (
 	"github.com/kanutocd/tada/internal/repository"
 )
 
+// Machine-readable reasons reported when a change to a user is denied.
+const (
+	ReasonNotSelf              = "not_self"
+	ReasonWrongCurrentPassword = "wrong_current_password"
+)
+
 type UserService interface {
-	CreateUser(req dto.CreateUserRequest) (*dto.UserResponse, error)
-	GetUserByID(id uuid.UUID) (*dto.UserResponse, error)
-	GetUsers(pagination dto.PaginationQuery) (*dto.PaginationResponse, error)
-	UpdateUser(id uuid.UUID, req dto.UpdateUserRequest) (*dto.UserResponse, error)
-	DeleteUser(id uuid.UUID) error
+	CreateUser(ctx context.Context, req dto.CreateUserRequest) (*dto.UserResponse, error)
+	GetUserByID(ctx context.Context, workspaceID, id uuid.UUID) (*dto.UserResponse, error)
+	GetUsers(ctx context.Context, workspaceID uuid.UUID, pagination dto.PaginationQuery) (*dto.PaginationResponse, error)
+	UpdateUser(ctx context.Context, actor *Identity, id uuid.UUID, req dto.UpdateUserRequest, ifMatch dto.IfMatch) (*dto.UserResponse, error)
+	PatchUser(ctx context.Context, actor *Identity, id uuid.UUID, patch dto.Patch, ifMatch dto.IfMatch) (*dto.UserResponse, error)
+	DeleteUser(ctx context.Context, actor *Identity, id uuid.UUID, ifMatch dto.IfMatch) error
 }
 
 type userService struct {
-	userRepo repository.UserRepository
+	userRepo      repository.UserRepository
+	workspaceRepo repository.WorkspaceRepository
+	sessionRepo   repository.SessionRepository
+	txManager     repository.TxManager
 }
 
-func NewUserService(userRepo repository.UserRepository) UserService {
+func NewUserService(
+	userRepo repository.UserRepository,
+	workspaceRepo repository.WorkspaceRepository,
+	sessionRepo repository.SessionRepository,
+	txManager repository.TxManager,
+) UserService {
 	return &userService{
-		userRepo: userRepo,
+		userRepo:      userRepo,
+		workspaceRepo: workspaceRepo,
+		sessionRepo:   sessionRepo,
+		txManager:     txManager,
 	}
 }
 
-func (s *userService) CreateUser(req dto.CreateUserRequest) (*dto.UserResponse, error) {
+func (s *userService) CreateUser(ctx context.Context, req dto.CreateUserRequest) (*dto.UserResponse, error) {
+	var response *dto.UserResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		response, err = s.createUser(ctx, req)
+		return err
+	})
+	return response, err
+}
+
+func (s *userService) createUser(ctx context.Context, req dto.CreateUserRequest) (*dto.UserResponse, error) {
 	// Check if email already exists
-	_, err := s.userRepo.GetByEmail(req.Email)
+	_, err := s.userRepo.GetByEmail(ctx, req.Email)
 	if err == nil {
-		return nil, errors.New("email already exists")
+		return nil, ErrEmailTaken
 	}
 	if !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, fmt.Errorf("failed to check email: %w", err)
 	}
 
+	passwordHash, err := hashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
 	user := &domain.User{
-		Name:  req.Name,
-		Email: req.Email,
+		Name:         req.Name,
+		Email:        req.Email,
+		PasswordHash: passwordHash,
 	}
 
-	if err := s.userRepo.Create(user); err != nil {
+	if err := s.userRepo.Create(ctx, user); err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	// Every user starts out owning a personal workspace. The account is
+	// usable without one, since they can create or be invited to another,
+	// so the workspace gets a savepoint of its own.
+	workspace := &domain.Workspace{Name: user.Name}
+	if err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		return s.workspaceRepo.Create(ctx, workspace, user.ID)
+	}); err != nil {
+		log.Printf("Failed to create personal workspace for user %s: %v", user.ID, err)
+	}
+
 	return dto.ToUserResponse(user), nil
 }
 
-func (s *userService) GetUserByID(id uuid.UUID) (*dto.UserResponse, error) {
-	user, err := s.userRepo.GetByID(id)
+// GetUserByID returns a member of the given workspace. Users outside it
+// are reported as not found.
+func (s *userService) GetUserByID(ctx context.Context, workspaceID, id uuid.UUID) (*dto.UserResponse, error) {
+	user, err := s.userRepo.GetMember(ctx, workspaceID, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -64,8 +113,13 @@ func (s *userService) GetUserByID(id uuid.UUID) (*dto.UserResponse, error) {
 	return dto.ToUserResponse(user), nil
 }
 
-func (s *userService) GetUsers(pagination dto.PaginationQuery) (*dto.PaginationResponse, error) {
-	users, nextCursor, err := s.userRepo.GetAll(pagination)
+// GetUsers lists the members of the given workspace.
+func (s *userService) GetUsers(
+	ctx context.Context,
+	workspaceID uuid.UUID,
+	pagination dto.PaginationQuery,
+) (*dto.PaginationResponse, error) {
+	users, nextCursor, err := s.userRepo.GetMembers(ctx, workspaceID, pagination)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get users: %w", err)
 	}
@@ -85,47 +139,184 @@ func (s *userService) GetUsers(pagination dto.PaginationQuery) (*dto.PaginationR
 	}, nil
 }
 
-func (s *userService) UpdateUser(id uuid.UUID, req dto.UpdateUserRequest) (*dto.UserResponse, error) {
-	user, err := s.userRepo.GetByID(id)
+// UpdateUser applies req to a user, who must be the actor and at a
+// version ifMatch allows. Changing the email or password takes the
+// current password, and a new password signs out the actor's other
+// sessions.
+func (s *userService) UpdateUser(
+	ctx context.Context, actor *Identity, id uuid.UUID, req dto.UpdateUserRequest, ifMatch dto.IfMatch,
+) (*dto.UserResponse, error) {
+	var response *dto.UserResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		response, err = s.updateUser(ctx, actor, id, req, ifMatch)
+		return err
+	})
+	return response, err
+}
+
+func (s *userService) updateUser(
+	ctx context.Context, actor *Identity, id uuid.UUID, req dto.UpdateUserRequest, ifMatch dto.IfMatch,
+) (*dto.UserResponse, error) {
+	user, err := s.getUserForUpdate(ctx, actor, id, ifMatch)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.applyUserPatch(ctx, actor, user, req.Patch(), ifMatch)
+}
+
+// PatchUser applies a merge patch or JSON Patch to a user. Otherwise it
+// works as UpdateUser does.
+func (s *userService) PatchUser(
+	ctx context.Context, actor *Identity, id uuid.UUID, patch dto.Patch, ifMatch dto.IfMatch,
+) (*dto.UserResponse, error) {
+	var response *dto.UserResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		response, err = s.patchUser(ctx, actor, id, patch, ifMatch)
+		return err
+	})
+	return response, err
+}
+
+func (s *userService) patchUser(
+	ctx context.Context, actor *Identity, id uuid.UUID, patch dto.Patch, ifMatch dto.IfMatch,
+) (*dto.UserResponse, error) {
+	user, err := s.getUserForUpdate(ctx, actor, id, ifMatch)
+	if err != nil {
+		return nil, err
+	}
+
+	var req dto.PatchUserRequest
+	if err := patch.Decode(dto.UserPatchDocument(user), &req); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.applyUserPatch(ctx, actor, user, req, ifMatch)
+}
+
+// getUserForUpdate loads the user with id, who must be the actor and at a
+// version ifMatch allows.
+func (s *userService) getUserForUpdate(ctx context.Context, actor *Identity, id uuid.UUID, ifMatch dto.IfMatch) (*domain.User, error) {
+	if err := authorizeSelf(actor, id, TadaActionUpdate); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
+	if err := checkVersion(ifMatch, user.Version); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *userService) applyUserPatch(
+	ctx context.Context, actor *Identity, user *domain.User, req dto.PatchUserRequest, ifMatch dto.IfMatch,
+) (*dto.UserResponse, error) {
+	changesEmail := req.Email.IsSet() && req.Email.Value != user.Email
+	if changesEmail || req.Password.IsSet() {
+		if !req.CurrentPassword.IsSet() || !checkPassword(user.PasswordHash, req.CurrentPassword.Value) {
+			return nil, &ForbiddenError{
+				Action:   TadaActionUpdate,
+				Resource: "user",
+				Reason:   ReasonWrongCurrentPassword,
+				Message:  "changing the email or password takes the current password",
+			}
+		}
+	}
+
 	// Update fields
-	if req.Name != nil {
-		user.Name = *req.Name
+	if req.Name.IsSet() {
+		user.Name = req.Name.Value
 	}
-	if req.Email != nil {
+	if req.Email.IsSet() {
 		// Check if new email already exists
-		existingUser, err := s.userRepo.GetByEmail(*req.Email)
-		if err == nil && existingUser.ID != id {
-			return nil, errors.New("email already exists")
+		existingUser, err := s.userRepo.GetByEmail(ctx, req.Email.Value)
+		if err == nil && existingUser.ID != user.ID {
+			return nil, ErrEmailTaken
+		}
+		user.Email = req.Email.Value
+	}
+	if req.Password.IsSet() {
+		passwordHash, err := hashPassword(req.Password.Value)
+		if err != nil {
+			return nil, err
 		}
-		user.Email = *req.Email
+		user.PasswordHash = passwordHash
 	}
 
-	if err := s.userRepo.Update(user); err != nil {
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, versionConflict(ifMatch)
+		}
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
 
+	// Whoever else holds a session may have learnt the old password.
+	if req.Password.IsSet() {
+		if err := s.sessionRepo.RevokeOthers(ctx, user.ID, actor.SessionID, time.Now()); err != nil {
+			return nil, fmt.Errorf("failed to revoke other sessions: %w", err)
+		}
+	}
+
 	return dto.ToUserResponse(user), nil
 }
 
-func (s *userService) DeleteUser(id uuid.UUID) error {
-	_, err := s.userRepo.GetByID(id)
+// DeleteUser deletes a user, who must be the actor and at a version
+// ifMatch allows.
+func (s *userService) DeleteUser(ctx context.Context, actor *Identity, id uuid.UUID, ifMatch dto.IfMatch) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		return s.deleteUser(ctx, actor, id, ifMatch)
+	})
+}
+
+func (s *userService) deleteUser(ctx context.Context, actor *Identity, id uuid.UUID, ifMatch dto.IfMatch) error {
+	if err := authorizeSelf(actor, id, TadaActionDelete); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("user not found")
+			return ErrUserNotFound
 		}
 		return fmt.Errorf("failed to get user: %w", err)
 	}
 
-	if err := s.userRepo.Delete(id); err != nil {
+	if err := checkVersion(ifMatch, user.Version); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.Delete(ctx, id, user.Version); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return versionConflict(ifMatch)
+		}
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
 
 	return nil
 }
+
+// authorizeSelf denies action on any account but the actor's own.
+func authorizeSelf(actor *Identity, id uuid.UUID, action TadaAction) error {
+	if actor.User.ID == id {
+		return nil
+	}
+	return &ForbiddenError{
+		Action:   action,
+		Resource: "user",
+		Reason:   ReasonNotSelf,
+		Message:  "users may only " + string(action) + " their own account",
+	}
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/kanutocd/tada/internal/config"
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/repository"
+)
+
+// Headers set on every webhook delivery. The signature is
+// "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)).
+const (
+	WebhookEventHeader     = "X-Tada-Event"
+	WebhookDeliveryHeader  = "X-Tada-Delivery"
+	WebhookTimestampHeader = "X-Tada-Timestamp"
+	WebhookSignatureHeader = "X-Tada-Signature"
+)
+
+// maxResponseError bounds how much of a failed response body is recorded.
+const maxResponseError = 1024
+
+// WebhookDispatcher sends queued webhook deliveries, retrying failures with
+// exponential backoff.
+type WebhookDispatcher struct {
+	webhookRepo repository.WebhookRepository
+	client      *http.Client
+	config      config.WebhookConfig
+}
+
+func NewWebhookDispatcher(webhookRepo repository.WebhookRepository, client *http.Client, cfg config.WebhookConfig) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		webhookRepo: webhookRepo,
+		client:      client,
+		config:      cfg,
+	}
+}
+
+// NewWebhookClient returns the HTTP client deliveries are sent with. It
+// connects directly, never through a proxy, and refuses to connect to an
+// address that is not public, whatever the URL's host resolved to.
+func NewWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("%w: %s is not a public address", ErrInvalidWebhookURL, host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Transport: transport}
+}
+
+// Run delivers due webhooks every poll interval until ctx is cancelled.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.DeliverDue(ctx); err != nil {
+			log.Printf("Webhook dispatcher: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue sends one batch of due deliveries and returns how many it
+// attempted.
+func (d *WebhookDispatcher) DeliverDue(ctx context.Context) (int, error) {
+	// Lease claimed deliveries for longer than a send can take, so that
+	// a crashed worker's claims become due again.
+	lease := 2 * d.config.Timeout
+
+	deliveries, err := d.webhookRepo.ClaimDueDeliveries(ctx, time.Now(), lease, d.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			return i, ctx.Err()
+		}
+		d.deliver(ctx, &deliveries[i])
+	}
+
+	return len(deliveries), nil
+}
+
+func (d *WebhookDispatcher) deliver(ctx context.Context, delivery *domain.WebhookDelivery) {
+	webhook := delivery.Webhook
+	delivery.Attempts++
+
+	switch {
+	case webhook.DeletedAt.Valid || !webhook.Active:
+		delivery.Status = domain.DeliveryFailed
+		delivery.LastError = "webhook deleted or inactive"
+	case webhook.OwnerRemoved:
+		delivery.Status = domain.DeliveryFailed
+		delivery.LastError = "webhook owner is no longer a workspace member"
+	default:
+		code, err := d.send(ctx, &webhook, delivery)
+		delivery.ResponseCode = code
+		delivery.LastError = ""
+
+		switch {
+		case err == nil:
+			now := time.Now()
+			delivery.Status = domain.DeliverySucceeded
+			delivery.DeliveredAt = &now
+		case delivery.Attempts >= d.config.MaxAttempts:
+			delivery.Status = domain.DeliveryFailed
+			delivery.LastError = err.Error()
+		default:
+			delivery.LastError = err.Error()
+			delivery.NextAttemptAt = time.Now().Add(d.backoff(delivery.Attempts))
+		}
+	}
+
+	// Record the outcome even when shutdown has begun, so a delivery that
+	// went out is not sent again.
+	if err := d.webhookRepo.UpdateDelivery(context.WithoutCancel(ctx), delivery); err != nil {
+		log.Printf("Failed to record webhook delivery %s: %v", delivery.ID, err)
+	}
+}
+
+// send POSTs the payload and returns the response code, if any, and an
+// error unless the receiver answered with a 2xx status.
+func (d *WebhookDispatcher) send(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery) (*int, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.config.Timeout)
+	defer cancel()
+
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "tada-webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, string(delivery.EventType))
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID.String())
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(webhook.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	code := resp.StatusCode
+	if code >= 200 && code < 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return &code, nil
+	}
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseError))
+	return &code, fmt.Errorf("receiver responded %d: %s", code, bytes.TrimSpace(snippet))
+}
+
+// backoff returns the delay before the next attempt after the given number
+// of failed attempts.
+func (d *WebhookDispatcher) backoff(attempts int) time.Duration {
+	delay := d.config.InitialBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.config.MaxBackoff {
+			return d.config.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// SignWebhookPayload computes the signature header value for a delivery.
+// Receivers recompute it with their secret to verify the sender.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
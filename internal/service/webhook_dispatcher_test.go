@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kanutocd/tada/internal/config"
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/repository"
+)
+
+// fakeWebhookRepository hands out the queued deliveries once and keeps the
+// outcomes the dispatcher records. Methods the dispatcher does not use
+// panic through the nil embedded interface.
+type fakeWebhookRepository struct {
+	repository.WebhookRepository
+
+	mu      sync.Mutex
+	due     []domain.WebhookDelivery
+	updated []domain.WebhookDelivery
+}
+
+func (r *fakeWebhookRepository) ClaimDueDeliveries(
+	ctx context.Context, now time.Time, lease time.Duration, limit int,
+) ([]domain.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	due := r.due
+	r.due = nil
+	return due, nil
+}
+
+func (r *fakeWebhookRepository) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updated = append(r.updated, *delivery)
+	return nil
+}
+
+var testWebhookConfig = config.WebhookConfig{
+	MaxAttempts:    3,
+	InitialBackoff: 10 * time.Second,
+	MaxBackoff:     time.Minute,
+	Timeout:        5 * time.Second,
+	BatchSize:      10,
+}
+
+func newTestDelivery(url string, attempts int) domain.WebhookDelivery {
+	webhookID := uuid.New()
+	return domain.WebhookDelivery{
+		ID:        uuid.New(),
+		WebhookID: webhookID,
+		EventType: domain.TadaChangeCreated,
+		Payload:   `{"type":"tada.created"}`,
+		Status:    domain.DeliveryPending,
+		Attempts:  attempts,
+		Webhook: domain.Webhook{
+			ID:     webhookID,
+			URL:    url,
+			Secret: "s3cret",
+			Active: true,
+		},
+	}
+}
+
+// deliverOne runs the dispatcher over a single delivery and returns the
+// outcome it recorded.
+func deliverOne(t *testing.T, delivery domain.WebhookDelivery) domain.WebhookDelivery {
+	t.Helper()
+
+	repo := &fakeWebhookRepository{due: []domain.WebhookDelivery{delivery}}
+	dispatcher := NewWebhookDispatcher(repo, &http.Client{}, testWebhookConfig)
+
+	sent, err := dispatcher.DeliverDue(context.Background())
+	if err != nil {
+		t.Fatalf("DeliverDue() error = %v", err)
+	}
+	if sent != 1 {
+		t.Fatalf("DeliverDue() = %d, want 1", sent)
+	}
+	if len(repo.updated) != 1 {
+		t.Fatalf("recorded %d outcomes, want 1", len(repo.updated))
+	}
+	return repo.updated[0]
+}
+
+func TestWebhookDispatcherSignsDeliveries(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		header http.Header
+		body   []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	delivery := newTestDelivery(server.URL, 0)
+	outcome := deliverOne(t, delivery)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if string(body) != delivery.Payload {
+		t.Errorf("body = %q, want %q", body, delivery.Payload)
+	}
+	if got := header.Get(WebhookEventHeader); got != string(domain.TadaChangeCreated) {
+		t.Errorf("%s = %q, want %q", WebhookEventHeader, got, domain.TadaChangeCreated)
+	}
+	if got := header.Get(WebhookDeliveryHeader); got != delivery.ID.String() {
+		t.Errorf("%s = %q, want %q", WebhookDeliveryHeader, got, delivery.ID)
+	}
+
+	timestamp, err := strconv.ParseInt(header.Get(WebhookTimestampHeader), 10, 64)
+	if err != nil {
+		t.Fatalf("%s is not a Unix time: %v", WebhookTimestampHeader, err)
+	}
+	want := SignWebhookPayload(delivery.Webhook.Secret, timestamp, []byte(delivery.Payload))
+	if got := header.Get(WebhookSignatureHeader); got != want {
+		t.Errorf("%s = %q, want %q", WebhookSignatureHeader, got, want)
+	}
+	if got := SignWebhookPayload("other", timestamp, []byte(delivery.Payload)); got == want {
+		t.Errorf("signature does not depend on the secret")
+	}
+
+	if outcome.Status != domain.DeliverySucceeded {
+		t.Errorf("Status = %q, want %q", outcome.Status, domain.DeliverySucceeded)
+	}
+	if outcome.Attempts != 1 {
+		t.Errorf("Attempts = %d, want 1", outcome.Attempts)
+	}
+	if outcome.ResponseCode == nil || *outcome.ResponseCode != http.StatusNoContent {
+		t.Errorf("ResponseCode = %v, want %d", outcome.ResponseCode, http.StatusNoContent)
+	}
+	if outcome.DeliveredAt == nil {
+		t.Errorf("DeliveredAt is not set")
+	}
+}
+
+func TestWebhookDispatcherRetriesFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "receiver is down", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		attempts    int
+		wantStatus  domain.WebhookDeliveryStatus
+		wantBackoff time.Duration
+	}{
+		{"first failure", 0, domain.DeliveryPending, 10 * time.Second},
+		{"second failure", 1, domain.DeliveryPending, 20 * time.Second},
+		{"last attempt", 2, domain.DeliveryFailed, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := time.Now()
+			outcome := deliverOne(t, newTestDelivery(server.URL, tt.attempts))
+
+			if outcome.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", outcome.Status, tt.wantStatus)
+			}
+			if outcome.Attempts != tt.attempts+1 {
+				t.Errorf("Attempts = %d, want %d", outcome.Attempts, tt.attempts+1)
+			}
+			if outcome.ResponseCode == nil || *outcome.ResponseCode != http.StatusServiceUnavailable {
+				t.Errorf("ResponseCode = %v, want %d", outcome.ResponseCode, http.StatusServiceUnavailable)
+			}
+			if !strings.Contains(outcome.LastError, "503: receiver is down") {
+				t.Errorf("LastError = %q, want the status and response body", outcome.LastError)
+			}
+			if outcome.DeliveredAt != nil {
+				t.Errorf("DeliveredAt = %v, want nil", outcome.DeliveredAt)
+			}
+
+			if tt.wantBackoff == 0 {
+				return
+			}
+			earliest := before.Add(tt.wantBackoff)
+			if outcome.NextAttemptAt.Before(earliest) || outcome.NextAttemptAt.After(time.Now().Add(tt.wantBackoff)) {
+				t.Errorf("NextAttemptAt = %v, want %v after the attempt", outcome.NextAttemptAt, tt.wantBackoff)
+			}
+		})
+	}
+}
+
+func TestWebhookDispatcherSkipsInactiveWebhooks(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	delivery := newTestDelivery(server.URL, 0)
+	delivery.Webhook.Active = false
+	outcome := deliverOne(t, delivery)
+
+	if n := requests.Load(); n != 0 {
+		t.Errorf("receiver got %d requests, want 0", n)
+	}
+	if outcome.Status != domain.DeliveryFailed {
+		t.Errorf("Status = %q, want %q", outcome.Status, domain.DeliveryFailed)
+	}
+}
+
+func TestWebhookDispatcherSkipsRemovedOwners(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	delivery := newTestDelivery(server.URL, 0)
+	delivery.Webhook.OwnerRemoved = true
+	outcome := deliverOne(t, delivery)
+
+	if n := requests.Load(); n != 0 {
+		t.Errorf("receiver got %d requests, want 0", n)
+	}
+	if outcome.Status != domain.DeliveryFailed {
+		t.Errorf("Status = %q, want %q", outcome.Status, domain.DeliveryFailed)
+	}
+}
+
+func TestWebhookDispatcherBackoff(t *testing.T) {
+	dispatcher := NewWebhookDispatcher(nil, nil, testWebhookConfig)
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 40 * time.Second},
+		{4, time.Minute},
+		{10, time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := dispatcher.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://hooks.example.com/tada", true},
+		{"http://93.184.216.34:8080/hook", true},
+		{"https://[2606:2800:220:1::1]/hook", true},
+		{"ftp://hooks.example.com/tada", false},
+		{"hooks.example.com/tada", false},
+		{"https:///tada", false},
+		{"http://localhost:8080/hook", false},
+		{"http://api.localhost/hook", false},
+		{"http://127.0.0.1/hook", false},
+		{"http://[::1]/hook", false},
+		{"http://[::ffff:127.0.0.1]/hook", false},
+		{"http://10.0.0.5/hook", false},
+		{"http://172.16.3.4/hook", false},
+		{"http://192.168.1.1/hook", false},
+		{"http://100.64.0.1/hook", false},
+		{"http://169.254.169.254/latest/meta-data", false},
+		{"http://0.0.0.0/hook", false},
+		{"http://[fd00::1]/hook", false},
+	}
+
+	for _, tt := range tests {
+		err := validateWebhookURL(tt.url)
+		if got := err == nil; got != tt.want {
+			t.Errorf("validateWebhookURL(%q) = %v, want valid %v", tt.url, err, tt.want)
+		}
+	}
+}
+
+func TestWebhookClientRefusesPrivateAddresses(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	resp, err := NewWebhookClient().Get(server.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatalf("Get(%s) succeeded, want the loopback address refused", server.URL)
+	}
+	if !errors.Is(err, ErrInvalidWebhookURL) {
+		t.Errorf("Get() error = %v, want ErrInvalidWebhookURL", err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("receiver got %d requests, want 0", n)
+	}
+}
@@ -0,0 +1,337 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/dto"
+	"github.com/kanutocd/tada/internal/repository"
+)
+
+// WebhookService manages webhook subscriptions and, as a TadaPublisher,
+// queues a delivery for every subscribed webhook when a tada changes.
+type WebhookService interface {
+	TadaPublisher
+
+	CreateWebhook(ctx context.Context, ownerID uuid.UUID, req dto.CreateWebhookRequest) (*dto.WebhookResponse, error)
+	GetWebhookByID(ctx context.Context, ownerID, id uuid.UUID) (*dto.WebhookResponse, error)
+	GetWebhooks(ctx context.Context, ownerID uuid.UUID, pagination dto.PaginationQuery) (*dto.PaginationResponse, error)
+	UpdateWebhook(ctx context.Context, ownerID, id uuid.UUID, req dto.UpdateWebhookRequest) (*dto.WebhookResponse, error)
+	DeleteWebhook(ctx context.Context, ownerID, id uuid.UUID) error
+	GetDeliveries(ctx context.Context, ownerID, id uuid.UUID, pagination dto.PaginationQuery) (*dto.PaginationResponse, error)
+
+	// InWorkspace returns the service confined to a workspace's webhooks.
+	InWorkspace(workspaceID uuid.UUID) WebhookService
+}
+
+type webhookService struct {
+	webhookRepo repository.WebhookRepository
+	txManager   repository.TxManager
+}
+
+func NewWebhookService(webhookRepo repository.WebhookRepository, txManager repository.TxManager) WebhookService {
+	return &webhookService{
+		webhookRepo: webhookRepo,
+		txManager:   txManager,
+	}
+}
+
+func (s *webhookService) InWorkspace(workspaceID uuid.UUID) WebhookService {
+	scoped := *s
+	scoped.webhookRepo = s.webhookRepo.InWorkspace(workspaceID)
+	return &scoped
+}
+
+// CreateWebhook subscribes a webhook to changes of the tadas in the
+// service's workspace.
+func (s *webhookService) CreateWebhook(
+	ctx context.Context, ownerID uuid.UUID, req dto.CreateWebhookRequest,
+) (*dto.WebhookResponse, error) {
+	var response *dto.WebhookResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		response, err = s.createWebhook(ctx, ownerID, req)
+		return err
+	})
+	return response, err
+}
+
+func (s *webhookService) createWebhook(
+	ctx context.Context, ownerID uuid.UUID, req dto.CreateWebhookRequest,
+) (*dto.WebhookResponse, error) {
+	if err := validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+	if err := validateChangeTypes(req.Events); err != nil {
+		return nil, err
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook := &domain.Webhook{
+		OwnerID: ownerID,
+		URL:     req.URL,
+		Secret:  secret,
+		Events:  req.Events,
+		Active:  true,
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	if err := s.webhookRepo.Create(ctx, webhook); err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	response := dto.ToWebhookResponse(webhook)
+	response.Secret = webhook.Secret
+
+	return response, nil
+}
+
+func (s *webhookService) GetWebhookByID(ctx context.Context, ownerID, id uuid.UUID) (*dto.WebhookResponse, error) {
+	webhook, err := s.getOwned(ctx, ownerID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.ToWebhookResponse(webhook), nil
+}
+
+func (s *webhookService) GetWebhooks(
+	ctx context.Context, ownerID uuid.UUID, pagination dto.PaginationQuery,
+) (*dto.PaginationResponse, error) {
+	webhooks, nextCursor, err := s.webhookRepo.GetByOwnerID(ctx, ownerID, pagination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks: %w", err)
+	}
+
+	webhookResponses := make([]dto.WebhookResponse, len(webhooks))
+	for i, webhook := range webhooks {
+		webhookResponses[i] = *dto.ToWebhookResponse(&webhook)
+	}
+
+	return &dto.PaginationResponse{
+		Data: webhookResponses,
+		Pagination: dto.PaginationMeta{
+			Limit:      pagination.Limit,
+			Count:      len(webhookResponses),
+			NextCursor: nextCursor,
+		},
+	}, nil
+}
+
+func (s *webhookService) UpdateWebhook(
+	ctx context.Context, ownerID, id uuid.UUID, req dto.UpdateWebhookRequest,
+) (*dto.WebhookResponse, error) {
+	var response *dto.WebhookResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		response, err = s.updateWebhook(ctx, ownerID, id, req)
+		return err
+	})
+	return response, err
+}
+
+func (s *webhookService) updateWebhook(
+	ctx context.Context, ownerID, id uuid.UUID, req dto.UpdateWebhookRequest,
+) (*dto.WebhookResponse, error) {
+	webhook, err := s.getOwned(ctx, ownerID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update fields
+	if req.URL != nil {
+		if err := validateWebhookURL(*req.URL); err != nil {
+			return nil, err
+		}
+		webhook.URL = *req.URL
+	}
+	if req.Events != nil {
+		if err := validateChangeTypes(req.Events); err != nil {
+			return nil, err
+		}
+		webhook.Events = req.Events
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	if err := s.webhookRepo.Update(ctx, webhook); err != nil {
+		return nil, fmt.Errorf("failed to update webhook: %w", err)
+	}
+
+	return dto.ToWebhookResponse(webhook), nil
+}
+
+func (s *webhookService) DeleteWebhook(ctx context.Context, ownerID, id uuid.UUID) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		return s.deleteWebhook(ctx, ownerID, id)
+	})
+}
+
+func (s *webhookService) deleteWebhook(ctx context.Context, ownerID, id uuid.UUID) error {
+	if _, err := s.getOwned(ctx, ownerID, id); err != nil {
+		return err
+	}
+
+	if err := s.webhookRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	return nil
+}
+
+func (s *webhookService) GetDeliveries(
+	ctx context.Context, ownerID, id uuid.UUID, pagination dto.PaginationQuery,
+) (*dto.PaginationResponse, error) {
+	if _, err := s.getOwned(ctx, ownerID, id); err != nil {
+		return nil, err
+	}
+
+	deliveries, nextCursor, err := s.webhookRepo.GetDeliveries(ctx, id, pagination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+
+	deliveryResponses := make([]dto.WebhookDeliveryResponse, len(deliveries))
+	for i, delivery := range deliveries {
+		deliveryResponses[i] = *dto.ToWebhookDeliveryResponse(&delivery)
+	}
+
+	return &dto.PaginationResponse{
+		Data: deliveryResponses,
+		Pagination: dto.PaginationMeta{
+			Limit:      pagination.Limit,
+			Count:      len(deliveryResponses),
+			NextCursor: nextCursor,
+		},
+	}, nil
+}
+
+// Publish queues a delivery of each change to every webhook subscribed to
+// its type in the changed tada's workspace. Failures are logged rather than
+// returned, since the change has already been committed.
+func (s *webhookService) Publish(changes ...TadaChange) {
+	// The request that made the changes may be over by now, so queuing
+	// does not run under its context.
+	ctx := context.Background()
+	for _, change := range changes {
+		if err := s.enqueue(ctx, change); err != nil {
+			log.Printf("Failed to queue webhook deliveries for %s %s: %v", change.Type, change.ID, err)
+		}
+	}
+}
+
+func (s *webhookService) enqueue(ctx context.Context, change TadaChange) error {
+	webhooks, err := s.webhookRepo.GetSubscribed(ctx, change.Type, change.Tada.WorkspaceID)
+	if err != nil {
+		return err
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(change.Event())
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	now := time.Now()
+	deliveries := make([]domain.WebhookDelivery, len(webhooks))
+	for i, webhook := range webhooks {
+		deliveries[i] = domain.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventType:     change.Type,
+			Payload:       string(payload),
+			Status:        domain.DeliveryPending,
+			NextAttemptAt: now,
+		}
+	}
+
+	return s.webhookRepo.CreateDeliveries(ctx, deliveries)
+}
+
+// getOwned loads a webhook of the service's workspace, treating other
+// users' webhooks as missing.
+func (s *webhookService) getOwned(ctx context.Context, ownerID, id uuid.UUID) (*domain.Webhook, error) {
+	webhook, err := s.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	if webhook.OwnerID != ownerID {
+		return nil, ErrWebhookNotFound
+	}
+
+	return webhook, nil
+}
+
+func validateChangeTypes(changeTypes []domain.TadaChangeType) error {
+	for _, changeType := range changeTypes {
+		if !domain.TadaChangeTypeList(domain.TadaChangeTypes).Contains(changeType) {
+			return fmt.Errorf("%w: %q", ErrInvalidEventType, changeType)
+		}
+	}
+	return nil
+}
+
+// validateWebhookURL rejects URLs that would have deliveries sent to the
+// server itself or its private network: anything but http and https, and
+// hosts that are localhost or a loopback, private, link-local or
+// unspecified address. Hostnames are checked again when a delivery
+// connects, since they can resolve to anything (see NewWebhookClient).
+func validateWebhookURL(raw string) error {
+	target, err := url.Parse(raw)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Hostname() == "" {
+		return ErrInvalidWebhookURL
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(target.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrInvalidWebhookURL
+	}
+	if ip := net.ParseIP(host); ip != nil && !isPublicIP(ip) {
+		return ErrInvalidWebhookURL
+	}
+	return nil
+}
+
+// isPublicIP reports whether ip may receive webhook deliveries.
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsInterfaceLocalMulticast() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598, which is
+// as internal as the private ranges net.IP.IsPrivate knows about.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func newWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,539 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kanutocd/tada/internal/config"
+	"github.com/kanutocd/tada/internal/domain"
+	"github.com/kanutocd/tada/internal/dto"
+	"github.com/kanutocd/tada/internal/repository"
+)
+
+// Machine-readable reasons reported when a workspace action is denied.
+const (
+	ReasonInsufficientRole = "insufficient_role"
+	ReasonOwnerRole        = "owner_role"
+	ReasonEmailMismatch    = "email_mismatch"
+)
+
+// WorkspaceService manages workspaces, their members and invitations.
+// Workspaces the actor is not a member of are reported as not found, so
+// their IDs cannot be probed.
+type WorkspaceService interface {
+	CreateWorkspace(ctx context.Context, actor *domain.User, req dto.CreateWorkspaceRequest) (*dto.WorkspaceResponse, error)
+	GetWorkspaces(ctx context.Context, actor *domain.User, pagination dto.PaginationQuery) (*dto.PaginationResponse, error)
+	GetWorkspace(ctx context.Context, actor *domain.User, id uuid.UUID) (*dto.WorkspaceResponse, error)
+	UpdateWorkspace(ctx context.Context, actor *domain.User, id uuid.UUID, req dto.UpdateWorkspaceRequest) (*dto.WorkspaceResponse, error)
+	GetMembers(ctx context.Context, actor *domain.User, id uuid.UUID, pagination dto.PaginationQuery) (*dto.PaginationResponse, error)
+	UpdateMember(
+		ctx context.Context, actor *domain.User, id, userID uuid.UUID, req dto.UpdateWorkspaceMemberRequest,
+	) (*dto.WorkspaceMemberResponse, error)
+	RemoveMember(ctx context.Context, actor *domain.User, id, userID uuid.UUID) error
+	InviteMember(
+		ctx context.Context, actor *domain.User, id uuid.UUID, req dto.CreateWorkspaceInvitationRequest,
+	) (*dto.WorkspaceInvitationResponse, error)
+	GetInvitations(ctx context.Context, actor *domain.User, id uuid.UUID, pagination dto.PaginationQuery) (*dto.PaginationResponse, error)
+	RevokeInvitation(ctx context.Context, actor *domain.User, id, invitationID uuid.UUID) error
+	AcceptInvitation(ctx context.Context, actor *domain.User, req dto.AcceptWorkspaceInvitationRequest) (*dto.WorkspaceResponse, error)
+	GetMembership(ctx context.Context, userID, workspaceID uuid.UUID) (*domain.WorkspaceMember, error)
+}
+
+type workspaceService struct {
+	workspaceRepo repository.WorkspaceRepository
+	userRepo      repository.UserRepository
+	txManager     repository.TxManager
+	notifier      Notifier
+	config        config.WorkspaceConfig
+}
+
+func NewWorkspaceService(
+	workspaceRepo repository.WorkspaceRepository,
+	userRepo repository.UserRepository,
+	txManager repository.TxManager,
+	notifier Notifier,
+	cfg config.WorkspaceConfig,
+) WorkspaceService {
+	return &workspaceService{
+		workspaceRepo: workspaceRepo,
+		userRepo:      userRepo,
+		txManager:     txManager,
+		notifier:      notifier,
+		config:        cfg,
+	}
+}
+
+// CreateWorkspace creates a workspace owned by actor.
+func (s *workspaceService) CreateWorkspace(
+	ctx context.Context, actor *domain.User, req dto.CreateWorkspaceRequest,
+) (*dto.WorkspaceResponse, error) {
+	var response *dto.WorkspaceResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		response, err = s.createWorkspace(ctx, actor, req)
+		return err
+	})
+	return response, err
+}
+
+func (s *workspaceService) createWorkspace(
+	ctx context.Context, actor *domain.User, req dto.CreateWorkspaceRequest,
+) (*dto.WorkspaceResponse, error) {
+	workspace := &domain.Workspace{Name: req.Name}
+	if err := s.workspaceRepo.Create(ctx, workspace, actor.ID); err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+
+	return dto.ToWorkspaceResponse(&domain.WorkspaceMember{
+		WorkspaceID: workspace.ID,
+		UserID:      actor.ID,
+		Role:        domain.WorkspaceRoleOwner,
+		Workspace:   *workspace,
+	}), nil
+}
+
+// GetWorkspaces lists the workspaces actor is a member of.
+func (s *workspaceService) GetWorkspaces(
+	ctx context.Context, actor *domain.User, pagination dto.PaginationQuery,
+) (*dto.PaginationResponse, error) {
+	members, nextCursor, err := s.workspaceRepo.GetByUserID(ctx, actor.ID, pagination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspaces: %w", err)
+	}
+
+	workspaceResponses := make([]dto.WorkspaceResponse, len(members))
+	for i, member := range members {
+		workspaceResponses[i] = *dto.ToWorkspaceResponse(&member)
+	}
+
+	return &dto.PaginationResponse{
+		Data: workspaceResponses,
+		Pagination: dto.PaginationMeta{
+			Limit:      pagination.Limit,
+			Count:      len(workspaceResponses),
+			NextCursor: nextCursor,
+		},
+	}, nil
+}
+
+func (s *workspaceService) GetWorkspace(ctx context.Context, actor *domain.User, id uuid.UUID) (*dto.WorkspaceResponse, error) {
+	member, err := s.GetMembership(ctx, actor.ID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.ToWorkspaceResponse(member), nil
+}
+
+// UpdateWorkspace renames a workspace. It takes an admin or the owner.
+func (s *workspaceService) UpdateWorkspace(
+	ctx context.Context, actor *domain.User, id uuid.UUID, req dto.UpdateWorkspaceRequest,
+) (*dto.WorkspaceResponse, error) {
+	var response *dto.WorkspaceResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		response, err = s.updateWorkspace(ctx, actor, id, req)
+		return err
+	})
+	return response, err
+}
+
+func (s *workspaceService) updateWorkspace(
+	ctx context.Context, actor *domain.User, id uuid.UUID, req dto.UpdateWorkspaceRequest,
+) (*dto.WorkspaceResponse, error) {
+	member, err := s.requireRole(ctx, actor, id, domain.WorkspaceRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		member.Workspace.Name = *req.Name
+	}
+
+	if err := s.workspaceRepo.Update(ctx, &member.Workspace); err != nil {
+		return nil, fmt.Errorf("failed to update workspace: %w", err)
+	}
+
+	return dto.ToWorkspaceResponse(member), nil
+}
+
+func (s *workspaceService) GetMembers(
+	ctx context.Context, actor *domain.User, id uuid.UUID, pagination dto.PaginationQuery,
+) (*dto.PaginationResponse, error) {
+	if _, err := s.GetMembership(ctx, actor.ID, id); err != nil {
+		return nil, err
+	}
+
+	members, nextCursor, err := s.workspaceRepo.GetMembers(ctx, id, pagination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace members: %w", err)
+	}
+
+	memberResponses := make([]dto.WorkspaceMemberResponse, len(members))
+	for i, member := range members {
+		memberResponses[i] = *dto.ToWorkspaceMemberResponse(&member)
+	}
+
+	return &dto.PaginationResponse{
+		Data: memberResponses,
+		Pagination: dto.PaginationMeta{
+			Limit:      pagination.Limit,
+			Count:      len(memberResponses),
+			NextCursor: nextCursor,
+		},
+	}, nil
+}
+
+// UpdateMember changes a member's role. It takes an admin or the owner, and
+// the owner's own role cannot be changed.
+func (s *workspaceService) UpdateMember(
+	ctx context.Context, actor *domain.User, id, userID uuid.UUID, req dto.UpdateWorkspaceMemberRequest,
+) (*dto.WorkspaceMemberResponse, error) {
+	var response *dto.WorkspaceMemberResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		response, err = s.updateMember(ctx, actor, id, userID, req)
+		return err
+	})
+	return response, err
+}
+
+func (s *workspaceService) updateMember(
+	ctx context.Context, actor *domain.User, id, userID uuid.UUID, req dto.UpdateWorkspaceMemberRequest,
+) (*dto.WorkspaceMemberResponse, error) {
+	if _, err := s.requireRole(ctx, actor, id, domain.WorkspaceRoleAdmin); err != nil {
+		return nil, err
+	}
+
+	member, err := s.getMember(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role == domain.WorkspaceRoleOwner {
+		return nil, ownerRoleError()
+	}
+
+	member.Role = req.Role
+	if err := s.workspaceRepo.UpdateMember(ctx, member); err != nil {
+		return nil, fmt.Errorf("failed to update workspace member: %w", err)
+	}
+
+	return dto.ToWorkspaceMemberResponse(member), nil
+}
+
+// RemoveMember takes a user out of a workspace. Admins and the owner can
+// remove anyone but the owner, and any other member can leave.
+func (s *workspaceService) RemoveMember(ctx context.Context, actor *domain.User, id, userID uuid.UUID) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		return s.removeMember(ctx, actor, id, userID)
+	})
+}
+
+func (s *workspaceService) removeMember(ctx context.Context, actor *domain.User, id, userID uuid.UUID) error {
+	if userID == actor.ID {
+		if _, err := s.GetMembership(ctx, actor.ID, id); err != nil {
+			return err
+		}
+	} else if _, err := s.requireRole(ctx, actor, id, domain.WorkspaceRoleAdmin); err != nil {
+		return err
+	}
+
+	member, err := s.getMember(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if member.Role == domain.WorkspaceRoleOwner {
+		return ownerRoleError()
+	}
+
+	if err := s.workspaceRepo.RemoveMember(ctx, id, userID); err != nil {
+		return fmt.Errorf("failed to remove workspace member: %w", err)
+	}
+
+	return nil
+}
+
+// InviteMember emails an invitation to join a workspace. It takes an admin
+// or the owner. The invitation is rolled back if the email cannot be sent.
+func (s *workspaceService) InviteMember(
+	ctx context.Context, actor *domain.User, id uuid.UUID, req dto.CreateWorkspaceInvitationRequest,
+) (*dto.WorkspaceInvitationResponse, error) {
+	var response *dto.WorkspaceInvitationResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		response, err = s.inviteMember(ctx, actor, id, req)
+		return err
+	})
+	return response, err
+}
+
+func (s *workspaceService) inviteMember(
+	ctx context.Context, actor *domain.User, id uuid.UUID, req dto.CreateWorkspaceInvitationRequest,
+) (*dto.WorkspaceInvitationResponse, error) {
+	inviter, err := s.requireRole(ctx, actor, id, domain.WorkspaceRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	email := strings.TrimSpace(req.Email)
+	if user, err := s.userRepo.GetByEmail(ctx, email); err == nil {
+		if _, err := s.workspaceRepo.GetMember(ctx, id, user.ID); err == nil {
+			return nil, ErrAlreadyMember
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to check membership: %w", err)
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check email: %w", err)
+	}
+
+	token, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := &domain.WorkspaceInvitation{
+		WorkspaceID: id,
+		Email:       email,
+		Role:        req.Role,
+		TokenHash:   hashToken(token),
+		InvitedBy:   actor.ID,
+		ExpiresAt:   time.Now().Add(s.config.InvitationTTL),
+	}
+	if invitation.Role == "" {
+		invitation.Role = domain.WorkspaceRoleMember
+	}
+
+	if err := s.workspaceRepo.CreateInvitation(ctx, invitation); err != nil {
+		return nil, fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	notification := s.invitationNotification(inviter, invitation, token)
+	if err := s.notifier.Notify(context.Background(), notification); err != nil {
+		return nil, fmt.Errorf("failed to send invitation: %w", err)
+	}
+
+	return dto.ToWorkspaceInvitationResponse(invitation), nil
+}
+
+// GetInvitations lists the invitations to a workspace that can still be
+// accepted. It takes an admin or the owner.
+func (s *workspaceService) GetInvitations(
+	ctx context.Context, actor *domain.User, id uuid.UUID, pagination dto.PaginationQuery,
+) (*dto.PaginationResponse, error) {
+	if _, err := s.requireRole(ctx, actor, id, domain.WorkspaceRoleAdmin); err != nil {
+		return nil, err
+	}
+
+	invitations, nextCursor, err := s.workspaceRepo.GetPendingInvitations(ctx, id, time.Now(), pagination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitations: %w", err)
+	}
+
+	invitationResponses := make([]dto.WorkspaceInvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		invitationResponses[i] = *dto.ToWorkspaceInvitationResponse(&invitation)
+	}
+
+	return &dto.PaginationResponse{
+		Data: invitationResponses,
+		Pagination: dto.PaginationMeta{
+			Limit:      pagination.Limit,
+			Count:      len(invitationResponses),
+			NextCursor: nextCursor,
+		},
+	}, nil
+}
+
+// RevokeInvitation withdraws an invitation so that its token no longer
+// works. It takes an admin or the owner.
+func (s *workspaceService) RevokeInvitation(ctx context.Context, actor *domain.User, id, invitationID uuid.UUID) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		return s.revokeInvitation(ctx, actor, id, invitationID)
+	})
+}
+
+func (s *workspaceService) revokeInvitation(ctx context.Context, actor *domain.User, id, invitationID uuid.UUID) error {
+	if _, err := s.requireRole(ctx, actor, id, domain.WorkspaceRoleAdmin); err != nil {
+		return err
+	}
+
+	invitation, err := s.workspaceRepo.GetInvitationByID(ctx, invitationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvitationNotFound
+		}
+		return fmt.Errorf("failed to get invitation: %w", err)
+	}
+	if invitation.WorkspaceID != id {
+		return ErrInvitationNotFound
+	}
+
+	if err := s.workspaceRepo.DeleteInvitation(ctx, invitationID); err != nil {
+		return fmt.Errorf("failed to revoke invitation: %w", err)
+	}
+
+	return nil
+}
+
+// AcceptInvitation adds actor to the workspace an invitation is for. The
+// invitation must have been sent to actor's email address, and can only be
+// accepted once and before it expires.
+func (s *workspaceService) AcceptInvitation(
+	ctx context.Context, actor *domain.User, req dto.AcceptWorkspaceInvitationRequest,
+) (*dto.WorkspaceResponse, error) {
+	var response *dto.WorkspaceResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		response, err = s.acceptInvitation(ctx, actor, req)
+		return err
+	})
+	return response, err
+}
+
+func (s *workspaceService) acceptInvitation(
+	ctx context.Context, actor *domain.User, req dto.AcceptWorkspaceInvitationRequest,
+) (*dto.WorkspaceResponse, error) {
+	invitation, err := s.workspaceRepo.GetInvitationByTokenHash(ctx, hashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+
+	now := time.Now()
+	if !invitation.Pending(now) {
+		return nil, ErrInvitationExpired
+	}
+	if !strings.EqualFold(invitation.Email, actor.Email) {
+		return nil, &ForbiddenError{
+			Action:   "accept",
+			Resource: "invitation",
+			Reason:   ReasonEmailMismatch,
+			Message:  "this invitation was sent to a different email address",
+		}
+	}
+
+	if _, err := s.workspaceRepo.GetMember(ctx, invitation.WorkspaceID, actor.ID); err == nil {
+		return nil, ErrAlreadyMember
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check membership: %w", err)
+	}
+
+	invitation.AcceptedAt = &now
+	member := &domain.WorkspaceMember{
+		WorkspaceID: invitation.WorkspaceID,
+		UserID:      actor.ID,
+		Role:        invitation.Role,
+	}
+	if err := s.workspaceRepo.AcceptInvitation(ctx, invitation, member); err != nil {
+		if errors.Is(err, repository.ErrInvitationUsed) {
+			return nil, ErrInvitationExpired
+		}
+		return nil, fmt.Errorf("failed to accept invitation: %w", err)
+	}
+
+	member, err = s.getMember(ctx, invitation.WorkspaceID, actor.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.ToWorkspaceResponse(member), nil
+}
+
+// GetMembership returns a user's membership of a workspace, or of their
+// default workspace when workspaceID is uuid.Nil. It returns
+// ErrWorkspaceNotFound when the user is not a member, and ErrNoWorkspace
+// when they have no workspace to default to.
+func (s *workspaceService) GetMembership(ctx context.Context, userID, workspaceID uuid.UUID) (*domain.WorkspaceMember, error) {
+	if workspaceID == uuid.Nil {
+		member, err := s.workspaceRepo.GetDefaultMember(ctx, userID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrNoWorkspace
+			}
+			return nil, fmt.Errorf("failed to get workspace membership: %w", err)
+		}
+		return member, nil
+	}
+
+	member, err := s.workspaceRepo.GetMember(ctx, workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWorkspaceNotFound
+		}
+		return nil, fmt.Errorf("failed to get workspace membership: %w", err)
+	}
+	return member, nil
+}
+
+// requireRole returns actor's membership of a workspace if it grants at
+// least role.
+func (s *workspaceService) requireRole(
+	ctx context.Context, actor *domain.User, id uuid.UUID, role domain.WorkspaceRole,
+) (*domain.WorkspaceMember, error) {
+	member, err := s.GetMembership(ctx, actor.ID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !member.Role.AtLeast(role) {
+		return nil, &ForbiddenError{
+			Action:   TadaActionUpdate,
+			Resource: "workspace",
+			Reason:   ReasonInsufficientRole,
+			Message:  "only owners and admins may manage this workspace",
+		}
+	}
+
+	return member, nil
+}
+
+func (s *workspaceService) getMember(ctx context.Context, id, userID uuid.UUID) (*domain.WorkspaceMember, error) {
+	member, err := s.workspaceRepo.GetMember(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMemberNotFound
+		}
+		return nil, fmt.Errorf("failed to get workspace member: %w", err)
+	}
+	return member, nil
+}
+
+func ownerRoleError() error {
+	return &ForbiddenError{
+		Action:   TadaActionUpdate,
+		Resource: "workspace",
+		Reason:   ReasonOwnerRole,
+		Message:  "the owner cannot be removed or given another role",
+	}
+}
+
+// invitationNotification builds the email sent to an invitee, who may not
+// have an account yet.
+func (s *workspaceService) invitationNotification(
+	inviter *domain.WorkspaceMember, invitation *domain.WorkspaceInvitation, token string,
+) Notification {
+	var body strings.Builder
+	fmt.Fprintf(&body, "%s has invited you to join the %q workspace as %s.\n\n",
+		inviter.User.Name, inviter.Workspace.Name, invitation.Role)
+	if s.config.InvitationURL != "" {
+		fmt.Fprintf(&body, "Accept the invitation at %s?token=%s\n", s.config.InvitationURL, url.QueryEscape(token))
+	} else {
+		fmt.Fprintf(&body, "Accept the invitation by signing in and sending this token to POST /api/v1/invitations/accept:\n\n%s\n", token)
+	}
+	fmt.Fprintf(&body, "\nThe invitation expires on %s.\n", invitation.ExpiresAt.UTC().Format(time.RFC1123))
+
+	return Notification{
+		Recipient: domain.User{Name: invitation.Email, Email: invitation.Email},
+		Subject:   fmt.Sprintf("You're invited to %s", inviter.Workspace.Name),
+		Body:      body.String(),
+	}
+}
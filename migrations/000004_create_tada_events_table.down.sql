@@ -0,0 +1,2 @@
+-- Drop tada_events table
+DROP TABLE IF EXISTS tada_events;
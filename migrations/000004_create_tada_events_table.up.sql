@@ -0,0 +1,18 @@
+-- Create tada_events table
+CREATE TABLE IF NOT EXISTS tada_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tada_id UUID NOT NULL,
+    actor_id UUID,
+    type VARCHAR(20) NOT NULL,
+    changes JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT fk_tada_events_tada_id FOREIGN KEY (tada_id) REFERENCES tadas (id),
+    CONSTRAINT fk_tada_events_actor_id FOREIGN KEY (actor_id) REFERENCES users (id),
+    CONSTRAINT chk_tada_events_type CHECK (
+        type IN ('created', 'updated', 'completed', 'deleted')
+    )
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_tada_events_tada_id ON tada_events(tada_id);
+CREATE INDEX IF NOT EXISTS idx_tada_events_created_at ON tada_events(created_at);
@@ -0,0 +1,4 @@
+-- Drop webhook tables
+DROP TABLE IF EXISTS webhook_deliveries;
+
+DROP TABLE IF EXISTS webhooks;
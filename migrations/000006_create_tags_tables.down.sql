@@ -0,0 +1,4 @@
+-- Drop tag tables
+DROP TABLE IF EXISTS tada_tags;
+
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,20 @@
+-- Create tags table
+CREATE TABLE IF NOT EXISTS tags (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create tada_tags join table
+CREATE TABLE IF NOT EXISTS tada_tags (
+    tada_id UUID NOT NULL,
+    tag_id UUID NOT NULL,
+    PRIMARY KEY (tada_id, tag_id),
+    CONSTRAINT fk_tada_tags_tada FOREIGN KEY (tada_id) REFERENCES tadas (id) ON DELETE CASCADE,
+    CONSTRAINT fk_tada_tags_tag FOREIGN KEY (tag_id) REFERENCES tags (id) ON DELETE CASCADE
+);
+
+-- Create indexes
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_name ON tags(name);
+CREATE INDEX IF NOT EXISTS idx_tada_tags_tag_id ON tada_tags(tag_id);
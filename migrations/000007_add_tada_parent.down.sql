@@ -0,0 +1,6 @@
+-- Remove parent reference
+DROP INDEX IF EXISTS idx_tadas_parent_id;
+
+ALTER TABLE tadas DROP CONSTRAINT IF EXISTS fk_tadas_parent_id;
+
+ALTER TABLE tadas DROP COLUMN IF EXISTS parent_id;
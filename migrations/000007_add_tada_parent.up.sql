@@ -0,0 +1,8 @@
+-- Add parent reference for subtasks
+ALTER TABLE tadas ADD COLUMN IF NOT EXISTS parent_id UUID;
+
+ALTER TABLE tadas
+    ADD CONSTRAINT fk_tadas_parent_id FOREIGN KEY (parent_id) REFERENCES tadas (id);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_tadas_parent_id ON tadas(parent_id);
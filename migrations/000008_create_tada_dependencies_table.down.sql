@@ -0,0 +1,2 @@
+-- Drop tada_dependencies table
+DROP TABLE IF EXISTS tada_dependencies;
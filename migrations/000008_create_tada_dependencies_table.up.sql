@@ -0,0 +1,15 @@
+-- Create tada_dependencies table
+CREATE TABLE IF NOT EXISTS tada_dependencies (
+    blocker_id UUID NOT NULL,
+    blocked_id UUID NOT NULL,
+    created_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (blocker_id, blocked_id),
+    CONSTRAINT fk_tada_dependencies_blocker_id FOREIGN KEY (blocker_id) REFERENCES tadas (id) ON DELETE CASCADE,
+    CONSTRAINT fk_tada_dependencies_blocked_id FOREIGN KEY (blocked_id) REFERENCES tadas (id) ON DELETE CASCADE,
+    CONSTRAINT fk_tada_dependencies_created_by FOREIGN KEY (created_by) REFERENCES users (id),
+    CONSTRAINT chk_tada_dependencies_distinct CHECK (blocker_id <> blocked_id)
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_tada_dependencies_blocked_id ON tada_dependencies(blocked_id);
@@ -0,0 +1,8 @@
+-- Remove recurrence rule and series link
+DROP INDEX IF EXISTS idx_tadas_series_id;
+
+ALTER TABLE tadas DROP CONSTRAINT IF EXISTS fk_tadas_series_id;
+
+ALTER TABLE tadas DROP COLUMN IF EXISTS occurrence;
+ALTER TABLE tadas DROP COLUMN IF EXISTS series_id;
+ALTER TABLE tadas DROP COLUMN IF EXISTS recurrence;
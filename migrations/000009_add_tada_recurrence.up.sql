@@ -0,0 +1,10 @@
+-- Add recurrence rule and series link
+ALTER TABLE tadas ADD COLUMN IF NOT EXISTS recurrence VARCHAR(255);
+ALTER TABLE tadas ADD COLUMN IF NOT EXISTS series_id UUID;
+ALTER TABLE tadas ADD COLUMN IF NOT EXISTS occurrence INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE tadas
+    ADD CONSTRAINT fk_tadas_series_id FOREIGN KEY (series_id) REFERENCES tadas (id);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_tadas_series_id ON tadas(series_id);
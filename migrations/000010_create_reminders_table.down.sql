@@ -0,0 +1,4 @@
+-- Drop reminders table
+DROP INDEX IF EXISTS idx_tadas_due_at;
+
+DROP TABLE IF EXISTS reminders;
@@ -0,0 +1,20 @@
+-- Create reminders table
+CREATE TABLE IF NOT EXISTS reminders (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tada_id UUID NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    recipient_id UUID NOT NULL,
+    sent_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT fk_reminders_tada_id FOREIGN KEY (tada_id) REFERENCES tadas (id) ON DELETE CASCADE,
+    CONSTRAINT fk_reminders_recipient_id FOREIGN KEY (recipient_id) REFERENCES users (id),
+    CONSTRAINT chk_reminders_kind CHECK (
+        kind IN ('due_soon', 'overdue')
+    )
+);
+
+-- Create indexes
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reminders_tada_kind_due ON reminders(tada_id, kind, due_at);
+
+-- Speed up the scheduler's scan for tadas falling due
+CREATE INDEX IF NOT EXISTS idx_tadas_due_at ON tadas(due_at) WHERE status = 'in_progress';
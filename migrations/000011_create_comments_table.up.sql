@@ -0,0 +1,19 @@
+-- Create comments table
+CREATE TABLE IF NOT EXISTS comments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tada_id UUID NOT NULL,
+    author_id UUID NOT NULL,
+    body TEXT NOT NULL,
+    mentions JSONB NOT NULL DEFAULT '[]',
+    edited_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT fk_comments_tada_id FOREIGN KEY (tada_id) REFERENCES tadas (id) ON DELETE CASCADE,
+    CONSTRAINT fk_comments_author_id FOREIGN KEY (author_id) REFERENCES users (id)
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_comments_tada_id ON comments(tada_id);
+CREATE INDEX IF NOT EXISTS idx_comments_created_at ON comments(created_at);
+CREATE INDEX IF NOT EXISTS idx_comments_deleted_at ON comments(deleted_at);
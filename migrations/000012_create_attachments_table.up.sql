@@ -0,0 +1,19 @@
+-- Create attachments table
+CREATE TABLE IF NOT EXISTS attachments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tada_id UUID NOT NULL,
+    uploaded_by UUID NOT NULL,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    storage_key VARCHAR(512) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT fk_attachments_tada_id FOREIGN KEY (tada_id) REFERENCES tadas (id) ON DELETE CASCADE,
+    CONSTRAINT fk_attachments_uploaded_by FOREIGN KEY (uploaded_by) REFERENCES users (id),
+    CONSTRAINT chk_attachments_size CHECK (size >= 0)
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_attachments_tada_id ON attachments(tada_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_created_at ON attachments(created_at);
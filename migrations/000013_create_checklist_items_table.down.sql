@@ -0,0 +1,2 @@
+-- Drop checklist items table
+DROP TABLE IF EXISTS checklist_items;
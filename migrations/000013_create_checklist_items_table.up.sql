@@ -0,0 +1,17 @@
+-- Create checklist items table
+CREATE TABLE IF NOT EXISTS checklist_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tada_id UUID NOT NULL,
+    text VARCHAR(500) NOT NULL,
+    checked BOOLEAN NOT NULL DEFAULT FALSE,
+    position INTEGER NOT NULL,
+    checked_by UUID,
+    checked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT fk_checklist_items_tada_id FOREIGN KEY (tada_id) REFERENCES tadas (id) ON DELETE CASCADE,
+    CONSTRAINT fk_checklist_items_checked_by FOREIGN KEY (checked_by) REFERENCES users (id) ON DELETE SET NULL
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_checklist_items_tada_position ON checklist_items(tada_id, position);
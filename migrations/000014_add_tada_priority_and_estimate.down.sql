@@ -0,0 +1,8 @@
+-- Remove priority and effort estimate
+DROP INDEX IF EXISTS idx_tadas_priority_rank;
+
+ALTER TABLE tadas DROP CONSTRAINT IF EXISTS chk_tadas_estimate_minutes;
+ALTER TABLE tadas DROP CONSTRAINT IF EXISTS chk_tadas_priority;
+
+ALTER TABLE tadas DROP COLUMN IF EXISTS estimate_minutes;
+ALTER TABLE tadas DROP COLUMN IF EXISTS priority;
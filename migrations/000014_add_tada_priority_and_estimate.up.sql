@@ -0,0 +1,23 @@
+-- Add priority and effort estimate
+ALTER TABLE tadas ADD COLUMN IF NOT EXISTS priority VARCHAR(10);
+ALTER TABLE tadas ADD COLUMN IF NOT EXISTS estimate_minutes INTEGER;
+
+-- Backfill existing tadas before requiring a priority
+UPDATE tadas SET priority = 'medium' WHERE priority IS NULL;
+
+ALTER TABLE tadas ALTER COLUMN priority SET DEFAULT 'medium';
+ALTER TABLE tadas ALTER COLUMN priority SET NOT NULL;
+
+ALTER TABLE tadas
+    ADD CONSTRAINT chk_tadas_priority CHECK (
+        priority IN ('low', 'medium', 'high', 'urgent')
+    );
+
+ALTER TABLE tadas
+    ADD CONSTRAINT chk_tadas_estimate_minutes CHECK (estimate_minutes > 0);
+
+-- Support sort=priority, which orders by urgency rather than by name
+CREATE INDEX IF NOT EXISTS idx_tadas_priority_rank ON tadas (
+    (CASE priority WHEN 'low' THEN 0 WHEN 'medium' THEN 1 WHEN 'high' THEN 2 WHEN 'urgent' THEN 3 END),
+    id
+);
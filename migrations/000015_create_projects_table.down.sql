@@ -0,0 +1,6 @@
+-- Remove projects
+DROP INDEX IF EXISTS idx_tadas_project_id;
+ALTER TABLE tadas DROP CONSTRAINT IF EXISTS fk_tadas_project_id;
+ALTER TABLE tadas DROP COLUMN IF EXISTS project_id;
+
+DROP TABLE IF EXISTS projects;
@@ -0,0 +1,24 @@
+-- Create projects table
+CREATE TABLE IF NOT EXISTS projects (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    owner_id UUID NOT NULL,
+    archived BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT fk_projects_owner_id FOREIGN KEY (owner_id) REFERENCES users (id)
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_projects_owner_id ON projects(owner_id);
+CREATE INDEX IF NOT EXISTS idx_projects_deleted_at ON projects(deleted_at);
+
+-- Let tadas belong to a project
+ALTER TABLE tadas ADD COLUMN IF NOT EXISTS project_id UUID;
+
+ALTER TABLE tadas
+    ADD CONSTRAINT fk_tadas_project_id FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tadas_project_id ON tadas(project_id);
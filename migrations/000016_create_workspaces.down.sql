@@ -0,0 +1,16 @@
+-- Remove workspaces
+DROP INDEX IF EXISTS idx_webhooks_workspace_id;
+DROP INDEX IF EXISTS idx_projects_workspace_id;
+DROP INDEX IF EXISTS idx_tadas_workspace_id;
+
+ALTER TABLE webhooks DROP CONSTRAINT IF EXISTS fk_webhooks_workspace_id;
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS fk_projects_workspace_id;
+ALTER TABLE tadas DROP CONSTRAINT IF EXISTS fk_tadas_workspace_id;
+
+ALTER TABLE webhooks DROP COLUMN IF EXISTS workspace_id;
+ALTER TABLE projects DROP COLUMN IF EXISTS workspace_id;
+ALTER TABLE tadas DROP COLUMN IF EXISTS workspace_id;
+
+DROP TABLE IF EXISTS workspace_invitations;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
@@ -0,0 +1,85 @@
+-- Create workspaces table
+CREATE TABLE IF NOT EXISTS workspaces (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspaces_deleted_at ON workspaces(deleted_at);
+
+-- Create workspace members table
+CREATE TABLE IF NOT EXISTS workspace_members (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    workspace_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    role VARCHAR(10) NOT NULL CHECK (role IN ('guest', 'member', 'admin', 'owner')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT fk_workspace_members_workspace_id FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE,
+    CONSTRAINT fk_workspace_members_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_workspace_members_workspace_user ON workspace_members(workspace_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_workspace_members_user_id ON workspace_members(user_id);
+
+-- Create workspace invitations table
+CREATE TABLE IF NOT EXISTS workspace_invitations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    workspace_id UUID NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(10) NOT NULL CHECK (role IN ('guest', 'member', 'admin')),
+    token_hash VARCHAR(64) NOT NULL,
+    invited_by UUID NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    accepted_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT fk_workspace_invitations_workspace_id FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE,
+    CONSTRAINT fk_workspace_invitations_invited_by FOREIGN KEY (invited_by) REFERENCES users (id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_workspace_invitations_token_hash ON workspace_invitations(token_hash);
+CREATE INDEX IF NOT EXISTS idx_workspace_invitations_workspace_id ON workspace_invitations(workspace_id);
+
+-- Let tadas, projects and webhooks belong to a workspace
+ALTER TABLE tadas ADD COLUMN IF NOT EXISTS workspace_id UUID;
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS workspace_id UUID;
+ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS workspace_id UUID;
+
+-- Move existing records into a default workspace that every user joins,
+-- owned by the earliest user
+DO $$
+DECLARE
+    default_workspace UUID;
+BEGIN
+    IF EXISTS (SELECT 1 FROM tadas WHERE workspace_id IS NULL)
+        OR EXISTS (SELECT 1 FROM projects WHERE workspace_id IS NULL)
+        OR EXISTS (SELECT 1 FROM webhooks WHERE workspace_id IS NULL) THEN
+        INSERT INTO workspaces (name) VALUES ('Default') RETURNING id INTO default_workspace;
+
+        INSERT INTO workspace_members (workspace_id, user_id, role)
+        SELECT default_workspace, id,
+            CASE WHEN ROW_NUMBER() OVER (ORDER BY created_at, id) = 1 THEN 'owner' ELSE 'member' END
+        FROM users WHERE deleted_at IS NULL;
+
+        UPDATE tadas SET workspace_id = default_workspace WHERE workspace_id IS NULL;
+        UPDATE projects SET workspace_id = default_workspace WHERE workspace_id IS NULL;
+        UPDATE webhooks SET workspace_id = default_workspace WHERE workspace_id IS NULL;
+    END IF;
+END $$;
+
+ALTER TABLE tadas ALTER COLUMN workspace_id SET NOT NULL;
+ALTER TABLE projects ALTER COLUMN workspace_id SET NOT NULL;
+ALTER TABLE webhooks ALTER COLUMN workspace_id SET NOT NULL;
+
+ALTER TABLE tadas
+    ADD CONSTRAINT fk_tadas_workspace_id FOREIGN KEY (workspace_id) REFERENCES workspaces (id);
+ALTER TABLE projects
+    ADD CONSTRAINT fk_projects_workspace_id FOREIGN KEY (workspace_id) REFERENCES workspaces (id);
+ALTER TABLE webhooks
+    ADD CONSTRAINT fk_webhooks_workspace_id FOREIGN KEY (workspace_id) REFERENCES workspaces (id);
+
+CREATE INDEX IF NOT EXISTS idx_tadas_workspace_id ON tadas(workspace_id);
+CREATE INDEX IF NOT EXISTS idx_projects_workspace_id ON projects(workspace_id);
+CREATE INDEX IF NOT EXISTS idx_webhooks_workspace_id ON webhooks(workspace_id);
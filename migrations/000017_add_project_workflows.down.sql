@@ -0,0 +1,25 @@
+-- Remove project workflows
+DROP INDEX IF EXISTS idx_tadas_due_at;
+DROP INDEX IF EXISTS idx_tadas_status_category;
+
+ALTER TABLE tadas DROP CONSTRAINT IF EXISTS chk_tadas_status_category;
+
+-- Fall back to the default statuses by category
+UPDATE tadas
+SET status = CASE status_category
+    WHEN 'completed' THEN 'completed'
+    WHEN 'cancelled' THEN 'cancelled'
+    ELSE 'in_progress'
+END
+WHERE status NOT IN ('in_progress', 'cancelled', 'completed');
+
+ALTER TABLE tadas DROP COLUMN IF EXISTS status_category;
+
+ALTER TABLE tadas
+    ADD CONSTRAINT chk_tadas_status CHECK (
+        status IN ('in_progress', 'cancelled', 'completed')
+    );
+
+CREATE INDEX IF NOT EXISTS idx_tadas_due_at ON tadas(due_at) WHERE status = 'in_progress';
+
+ALTER TABLE projects DROP COLUMN IF EXISTS workflow;
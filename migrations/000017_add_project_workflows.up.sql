@@ -0,0 +1,30 @@
+-- Let projects define their own status workflow
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS workflow JSONB;
+
+-- Statuses are now named by the workflow, so only their category is fixed
+ALTER TABLE tadas DROP CONSTRAINT IF EXISTS chk_tadas_status;
+
+ALTER TABLE tadas ADD COLUMN IF NOT EXISTS status_category VARCHAR(10);
+
+-- Backfill existing tadas, which all follow the default workflow
+UPDATE tadas
+SET status_category = CASE status
+    WHEN 'completed' THEN 'completed'
+    WHEN 'cancelled' THEN 'cancelled'
+    ELSE 'open'
+END
+WHERE status_category IS NULL;
+
+ALTER TABLE tadas ALTER COLUMN status_category SET DEFAULT 'open';
+ALTER TABLE tadas ALTER COLUMN status_category SET NOT NULL;
+
+ALTER TABLE tadas
+    ADD CONSTRAINT chk_tadas_status_category CHECK (
+        status_category IN ('open', 'completed', 'cancelled')
+    );
+
+CREATE INDEX IF NOT EXISTS idx_tadas_status_category ON tadas (status_category);
+
+-- Reminders now look for open tadas by category rather than by status
+DROP INDEX IF EXISTS idx_tadas_due_at;
+CREATE INDEX IF NOT EXISTS idx_tadas_due_at ON tadas (due_at) WHERE status_category = 'open';
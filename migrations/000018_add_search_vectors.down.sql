@@ -0,0 +1,6 @@
+-- Remove full-text search
+DROP INDEX IF EXISTS idx_comments_search_vector;
+DROP INDEX IF EXISTS idx_tadas_search_vector;
+
+ALTER TABLE comments DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE tadas DROP COLUMN IF EXISTS search_vector;
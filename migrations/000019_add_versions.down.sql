@@ -0,0 +1,3 @@
+-- Remove record versions
+ALTER TABLE users DROP COLUMN IF EXISTS version;
+ALTER TABLE tadas DROP COLUMN IF EXISTS version;
@@ -0,0 +1,4 @@
+-- Count saves to tadas and users, so that a save made against an outdated
+-- copy can be rejected
+ALTER TABLE tadas ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
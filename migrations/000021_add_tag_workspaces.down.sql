@@ -0,0 +1,28 @@
+-- Drop tag workspaces. Copies of a tag in different workspaces are merged
+-- back into one.
+DROP INDEX IF EXISTS idx_tags_workspace_name;
+
+ALTER TABLE tags DROP CONSTRAINT IF EXISTS fk_tags_workspace_id;
+
+DELETE FROM tada_tags
+USING tags, tags AS survivors, tada_tags AS kept
+WHERE tags.id = tada_tags.tag_id
+    AND survivors.name = tags.name
+    AND survivors.id = (SELECT MIN(id::text)::uuid FROM tags AS same WHERE same.name = tags.name)
+    AND survivors.id <> tags.id
+    AND kept.tada_id = tada_tags.tada_id
+    AND kept.tag_id = survivors.id;
+
+UPDATE tada_tags SET tag_id = survivors.id
+FROM tags, tags AS survivors
+WHERE tags.id = tada_tags.tag_id
+    AND survivors.name = tags.name
+    AND survivors.id = (SELECT MIN(id::text)::uuid FROM tags AS same WHERE same.name = tags.name)
+    AND survivors.id <> tags.id;
+
+DELETE FROM tags
+WHERE id <> (SELECT MIN(id::text)::uuid FROM tags AS same WHERE same.name = tags.name);
+
+ALTER TABLE tags DROP COLUMN IF EXISTS workspace_id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_name ON tags(name);
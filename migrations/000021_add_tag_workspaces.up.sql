@@ -0,0 +1,40 @@
+-- Let tags belong to a workspace, with names unique within one rather
+-- than overall
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS workspace_id UUID;
+DROP INDEX IF EXISTS idx_tags_name;
+
+-- Give every workspace its own copy of each tag its tadas carry, and move
+-- the tadas over to the copies
+INSERT INTO tags (name, workspace_id, created_at, updated_at)
+SELECT DISTINCT tags.name, tadas.workspace_id, tags.created_at, tags.updated_at
+FROM tags
+JOIN tada_tags ON tada_tags.tag_id = tags.id
+JOIN tadas ON tadas.id = tada_tags.tada_id
+WHERE tags.workspace_id IS NULL;
+
+UPDATE tada_tags SET tag_id = copies.id
+FROM tadas, tags AS originals, tags AS copies
+WHERE tadas.id = tada_tags.tada_id
+    AND originals.id = tada_tags.tag_id
+    AND originals.workspace_id IS NULL
+    AND copies.workspace_id = tadas.workspace_id
+    AND copies.name = originals.name;
+
+-- Drop the originals that were copied. The rest, which no tada carries,
+-- go to the earliest workspace, or away if there is none
+DELETE FROM tags
+WHERE workspace_id IS NULL
+    AND (
+        NOT EXISTS (SELECT 1 FROM workspaces)
+        OR EXISTS (SELECT 1 FROM tags AS copies WHERE copies.name = tags.name AND copies.workspace_id IS NOT NULL)
+    );
+
+UPDATE tags SET workspace_id = (SELECT id FROM workspaces ORDER BY created_at, id LIMIT 1)
+WHERE workspace_id IS NULL;
+
+ALTER TABLE tags ALTER COLUMN workspace_id SET NOT NULL;
+
+ALTER TABLE tags
+    ADD CONSTRAINT fk_tags_workspace_id FOREIGN KEY (workspace_id) REFERENCES workspaces (id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_workspace_name ON tags(workspace_id, name);
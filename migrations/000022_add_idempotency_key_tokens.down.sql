@@ -0,0 +1,2 @@
+-- Drop idempotency key claim tokens
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS token;
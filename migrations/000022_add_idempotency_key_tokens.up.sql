@@ -0,0 +1,4 @@
+-- Give each claim on an idempotency key a token, so that a request whose
+-- key was taken over can no longer release it or store its response
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS token UUID NOT NULL DEFAULT uuid_generate_v4();
+ALTER TABLE idempotency_keys ALTER COLUMN token DROP DEFAULT;
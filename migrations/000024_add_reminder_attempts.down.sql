@@ -0,0 +1,7 @@
+-- Drop reminder retries; unsent reminders are sent afresh
+DELETE FROM reminders WHERE sent_at IS NULL;
+
+ALTER TABLE reminders DROP COLUMN IF EXISTS next_attempt_at;
+ALTER TABLE reminders DROP COLUMN IF EXISTS last_error;
+ALTER TABLE reminders DROP COLUMN IF EXISTS attempts;
+ALTER TABLE reminders ALTER COLUMN sent_at SET NOT NULL;
@@ -0,0 +1,6 @@
+-- Keep reminders that failed to send, to be retried later instead of
+-- holding up the rest of the scheduler's batch
+ALTER TABLE reminders ALTER COLUMN sent_at DROP NOT NULL;
+ALTER TABLE reminders ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE reminders ADD COLUMN IF NOT EXISTS last_error TEXT;
+ALTER TABLE reminders ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP WITH TIME ZONE;
@@ -25,15 +25,56 @@ VALUES
     NOW()
   );
 
+-- Workspaces
+INSERT INTO
+  workspaces (id, name, created_at, updated_at)
+VALUES
+  (
+    '750e8400-e29b-41d4-a716-446655440001',
+    'Test Workspace',
+    NOW(),
+    NOW()
+  );
+
+INSERT INTO
+  workspace_members (id, workspace_id, user_id, role, created_at, updated_at)
+VALUES
+  (
+    '850e8400-e29b-41d4-a716-446655440001',
+    '750e8400-e29b-41d4-a716-446655440001',
+    '550e8400-e29b-41d4-a716-446655440001',
+    'owner',
+    NOW(),
+    NOW()
+  ),
+  (
+    '850e8400-e29b-41d4-a716-446655440002',
+    '750e8400-e29b-41d4-a716-446655440001',
+    '550e8400-e29b-41d4-a716-446655440002',
+    'member',
+    NOW(),
+    NOW()
+  ),
+  (
+    '850e8400-e29b-41d4-a716-446655440003',
+    '750e8400-e29b-41d4-a716-446655440001',
+    '550e8400-e29b-41d4-a716-446655440003',
+    'guest',
+    NOW(),
+    NOW()
+  );
+
 -- Tadas
 INSERT INTO
   tadas (
     id,
+    workspace_id,
     name,
     description,
     created_by,
     assigned_to,
     status,
+    status_category,
     due_at,
     created_at,
     updated_at
@@ -41,33 +82,39 @@ INSERT INTO
 VALUES
   (
     '650e8400-e29b-41d4-a716-446655440001',
+    '750e8400-e29b-41d4-a716-446655440001',
     'Test Task 1',
     'Description 1',
     '550e8400-e29b-41d4-a716-446655440001',
     '550e8400-e29b-41d4-a716-446655440002',
     'in_progress',
+    'open',
     NOW()+INTERVAL '7 days',
     NOW(),
     NOW()
   ),
   (
     '650e8400-e29b-41d4-a716-446655440002',
+    '750e8400-e29b-41d4-a716-446655440001',
     'Test Task 2',
     'Description 2',
     '550e8400-e29b-41d4-a716-446655440002',
     NULL,
     'in_progress',
+    'open',
     NULL,
     NOW(),
     NOW()
   ),
   (
     '650e8400-e29b-41d4-a716-446655440003',
+    '750e8400-e29b-41d4-a716-446655440001',
     'Test Task 3',
     'Description 3',
     '550e8400-e29b-41d4-a716-446655440001',
     '550e8400-e29b-41d4-a716-446655440003',
     'completed',
+    'completed',
     NOW()-INTERVAL '1 day',
     NOW()-INTERVAL '1 day',
     NOW()